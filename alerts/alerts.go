@@ -0,0 +1,188 @@
+// Package alerts evaluates price alert rules against a stream of quotes from
+// the WebSocket client, the poller or a backtest, with per-rule debouncing.
+package alerts
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tradermade/Go-SDK/candle"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Condition decides whether a quote triggers an alert. Conditions keep their
+// own per-symbol state, so one condition may watch several symbols.
+type Condition interface {
+	// Check evaluates a quote at time t and returns the value that triggered
+	// it, e.g. the price, percent move or spread
+	Check(quote tradermadews.QuoteMessage, t time.Time) (value float64, triggered bool)
+}
+
+// Rule is a named condition on one symbol, or on every symbol. Set either
+// Condition, evaluated on quotes, or CandleCondition, evaluated on closed candles.
+type Rule struct {
+	Name            string
+	Symbol          string          // Empty matches every symbol
+	Condition       Condition       // Evaluated on every matching quote
+	CandleCondition CandleCondition // Evaluated on every matching closed candle
+	Cooldown        time.Duration   // Minimum time between triggers per symbol, zero fires every time
+}
+
+// Event describes a triggered rule
+type Event struct {
+	Rule   string
+	Symbol string
+	Time   time.Time // Quote time, or candle close, that triggered the rule
+	Value  float64   // Value returned by the condition
+	Quote  tradermadews.QuoteMessage
+	Candle *candle.Candle // Closed candle for candle conditions
+}
+
+// Engine evaluates rules against quotes
+type Engine struct {
+	Handler    func(Event)          // Handles every triggered rule
+	MarketOpen func(time.Time) bool // Ignores quotes while false, e.g. markethours.IsOpen; nil means always open
+
+	mu        sync.Mutex
+	rules     []Rule
+	lastFired map[firedKey]time.Time
+	events    chan Event
+}
+
+type firedKey struct {
+	rule, symbol string
+}
+
+// New creates an engine without rules
+func New() *Engine {
+	return &Engine{
+		lastFired: make(map[firedKey]time.Time),
+		events:    make(chan Event, 64),
+	}
+}
+
+// SetHandler sets the callback function to handle triggered rules
+func (e *Engine) SetHandler(handler func(Event)) {
+	e.Handler = handler
+}
+
+// Events returns a channel receiving triggered rules. Events are dropped when
+// the channel's buffer is full.
+func (e *Engine) Events() <-chan Event {
+	return e.events
+}
+
+// Add registers a rule, replacing any rule with the same name
+func (e *Engine) Add(rule Rule) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i := range e.rules {
+		if e.rules[i].Name == rule.Name {
+			e.rules[i] = rule
+			return
+		}
+	}
+	e.rules = append(e.rules, rule)
+}
+
+// Remove unregisters the named rule
+func (e *Engine) Remove(name string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i := range e.rules {
+		if e.rules[i].Name == name {
+			e.rules = append(e.rules[:i], e.rules[i+1:]...)
+			return
+		}
+	}
+}
+
+// HandleQuote evaluates the rules against a quote. It has the message handler
+// signature of the WebSocket client and the poller.
+func (e *Engine) HandleQuote(quote tradermadews.QuoteMessage, _ string) {
+	e.Check(quote)
+}
+
+// Check evaluates the rules against a quote and returns the events it
+// triggered. Quotes with an invalid timestamp are evaluated at the current time.
+func (e *Engine) Check(quote tradermadews.QuoteMessage) []Event {
+	t := quote.Time
+	if t.IsZero() {
+		var err error
+		if t, err = quote.Timestamp(); err != nil {
+			t = time.Now()
+		}
+	}
+	if e.MarketOpen != nil && !e.MarketOpen(t) {
+		return nil
+	}
+
+	e.mu.Lock()
+	var fired []Event
+	for _, rule := range e.rules {
+		if rule.Condition == nil || (rule.Symbol != "" && rule.Symbol != quote.Symbol) {
+			continue
+		}
+		value, triggered := rule.Condition.Check(quote, t)
+		if triggered && e.debounce(rule, quote.Symbol, t) {
+			fired = append(fired, Event{Rule: rule.Name, Symbol: quote.Symbol, Time: t, Value: value, Quote: quote})
+		}
+	}
+	e.mu.Unlock()
+
+	e.deliver(fired)
+	return fired
+}
+
+// HandleCandle evaluates the candle rules against a closed candle. It can be
+// passed to candle.Builder.SetCloseHandler.
+func (e *Engine) HandleCandle(c candle.Candle) {
+	e.CheckCandle(c)
+}
+
+// CheckCandle evaluates the candle rules against a closed candle and returns
+// the events it triggered
+func (e *Engine) CheckCandle(c candle.Candle) []Event {
+	t := c.End()
+
+	e.mu.Lock()
+	var fired []Event
+	for _, rule := range e.rules {
+		if rule.CandleCondition == nil || (rule.Symbol != "" && rule.Symbol != c.Symbol) {
+			continue
+		}
+		value, triggered := rule.CandleCondition.CheckCandle(c)
+		if triggered && e.debounce(rule, c.Symbol, t) {
+			bar := c
+			fired = append(fired, Event{Rule: rule.Name, Symbol: c.Symbol, Time: t, Value: value, Candle: &bar})
+		}
+	}
+	e.mu.Unlock()
+
+	e.deliver(fired)
+	return fired
+}
+
+// debounce reports whether rule may fire for symbol at t, recording it if so.
+// Must be called with mu held.
+func (e *Engine) debounce(rule Rule, symbol string, t time.Time) bool {
+	key := firedKey{rule.Name, symbol}
+	if last, ok := e.lastFired[key]; ok && rule.Cooldown > 0 && t.Sub(last) < rule.Cooldown {
+		return false
+	}
+	e.lastFired[key] = t
+	return true
+}
+
+// deliver passes events to the handler and the Events channel
+func (e *Engine) deliver(fired []Event) {
+	for _, event := range fired {
+		if e.Handler != nil {
+			e.Handler(event)
+		}
+		select {
+		case e.events <- event:
+		default:
+		}
+	}
+}
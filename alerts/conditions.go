@@ -0,0 +1,137 @@
+package alerts
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// CrossesAbove triggers when the mid price moves from below level to at or above it
+func CrossesAbove(level float64) Condition {
+	return &crossing{level: level, above: true, prev: make(map[string]float64)}
+}
+
+// CrossesBelow triggers when the mid price moves from above level to at or below it
+func CrossesBelow(level float64) Condition {
+	return &crossing{level: level, prev: make(map[string]float64)}
+}
+
+type crossing struct {
+	mu    sync.Mutex
+	level float64
+	above bool
+	prev  map[string]float64 // Previous mid per symbol
+}
+
+func (c *crossing) Check(quote tradermadews.QuoteMessage, _ time.Time) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prev, seen := c.prev[quote.Symbol]
+	c.prev[quote.Symbol] = quote.Mid
+	if !seen {
+		return 0, false
+	}
+	if c.above {
+		return quote.Mid, prev < c.level && quote.Mid >= c.level
+	}
+	return quote.Mid, prev > c.level && quote.Mid <= c.level
+}
+
+// PercentMove triggers when the mid price has moved by at least percent, up
+// or down, from any price within the preceding window
+func PercentMove(percent float64, window time.Duration) Condition {
+	return &percentMove{percent: percent, window: window, extremes: make(map[string]*extremes)}
+}
+
+type pricePoint struct {
+	t   time.Time
+	mid float64
+}
+
+type percentMove struct {
+	mu       sync.Mutex
+	percent  float64
+	window   time.Duration
+	extremes map[string]*extremes // Window minimum and maximum per symbol
+}
+
+// extremes tracks the minimum and maximum price within a sliding window in
+// amortized constant time per tick. Each deque holds the points that can
+// still become the extreme, oldest first: min has rising prices and max
+// falling ones, so the front of each is the window's extreme.
+type extremes struct {
+	min []pricePoint
+	max []pricePoint
+}
+
+// expire drops points older than cutoff
+func (e *extremes) expire(cutoff time.Time) {
+	for len(e.min) > 0 && e.min[0].t.Before(cutoff) {
+		e.min = e.min[1:]
+	}
+	for len(e.max) > 0 && e.max[0].t.Before(cutoff) {
+		e.max = e.max[1:]
+	}
+}
+
+// push adds the latest point, dropping those it makes irrelevant
+func (e *extremes) push(p pricePoint) {
+	for len(e.min) > 0 && e.min[len(e.min)-1].mid >= p.mid {
+		e.min = e.min[:len(e.min)-1]
+	}
+	e.min = append(e.min, p)
+	for len(e.max) > 0 && e.max[len(e.max)-1].mid <= p.mid {
+		e.max = e.max[:len(e.max)-1]
+	}
+	e.max = append(e.max, p)
+}
+
+func (c *percentMove) Check(quote tradermadews.QuoteMessage, t time.Time) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := c.extremes[quote.Symbol]
+	if e == nil {
+		e = &extremes{}
+		c.extremes[quote.Symbol] = e
+	}
+	e.expire(t.Add(-c.window))
+
+	// The largest move is against the window's lowest or highest price
+	var move float64
+	if len(e.min) > 0 {
+		for _, ref := range []float64{e.min[0].mid, e.max[0].mid} {
+			if m := (quote.Mid - ref) / ref * 100; math.Abs(m) > math.Abs(move) {
+				move = m
+			}
+		}
+	}
+	if quote.Mid > 0 {
+		e.push(pricePoint{t, quote.Mid})
+	}
+	return move, math.Abs(move) >= c.percent && move != 0
+}
+
+// SpreadAbove triggers when the spread (ask minus bid) widens above threshold.
+// It fires again only after the spread has narrowed back to threshold or below.
+func SpreadAbove(threshold float64) Condition {
+	return &spreadAbove{threshold: threshold, wide: make(map[string]bool)}
+}
+
+type spreadAbove struct {
+	mu        sync.Mutex
+	threshold float64
+	wide      map[string]bool // Whether the spread is currently above threshold per symbol
+}
+
+func (c *spreadAbove) Check(quote tradermadews.QuoteMessage, _ time.Time) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	spread := quote.Ask - quote.Bid
+	wide := spread > c.threshold
+	triggered := wide && !c.wide[quote.Symbol]
+	c.wide[quote.Symbol] = wide
+	return spread, triggered
+}
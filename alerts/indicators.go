@@ -0,0 +1,118 @@
+package alerts
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tradermade/Go-SDK/candle"
+	"github.com/tradermade/Go-SDK/indicator"
+)
+
+// CandleCondition decides whether a closed candle triggers an alert. Like
+// Condition, it keeps its own state per symbol and interval.
+type CandleCondition interface {
+	// CheckCandle evaluates a closed candle and returns the value that
+	// triggered it, e.g. the indicator reading
+	CheckCandle(c candle.Candle) (value float64, triggered bool)
+}
+
+// seriesKey identifies one candle series
+type seriesKey struct {
+	symbol   string
+	interval time.Duration
+}
+
+// EMACrossAbove triggers when the fast EMA of closes crosses above the slow
+// EMA, e.g. EMACrossAbove(50, 200) for a golden cross. The value is the fast EMA.
+func EMACrossAbove(fast, slow int) CandleCondition {
+	return &emaCross{fast: fast, slow: slow, above: true, series: make(map[seriesKey]*emaPair)}
+}
+
+// EMACrossBelow triggers when the fast EMA of closes crosses below the slow
+// EMA, e.g. EMACrossBelow(50, 200) for a death cross. The value is the fast EMA.
+func EMACrossBelow(fast, slow int) CandleCondition {
+	return &emaCross{fast: fast, slow: slow, series: make(map[seriesKey]*emaPair)}
+}
+
+type emaPair struct {
+	fast, slow *indicator.EMA
+	wasAbove   bool
+	primed     bool // Whether wasAbove has been set from ready averages
+}
+
+type emaCross struct {
+	mu         sync.Mutex
+	fast, slow int
+	above      bool
+	series     map[seriesKey]*emaPair
+}
+
+func (c *emaCross) CheckCandle(bar candle.Candle) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := seriesKey{bar.Symbol, bar.Interval}
+	pair := c.series[key]
+	if pair == nil {
+		pair = &emaPair{fast: indicator.NewEMA(c.fast), slow: indicator.NewEMA(c.slow)}
+		c.series[key] = pair
+	}
+	fast, slow := pair.fast.Update(bar.Close), pair.slow.Update(bar.Close)
+	if !pair.fast.Ready() || !pair.slow.Ready() {
+		return fast, false
+	}
+
+	isAbove := fast > slow
+	crossed := pair.primed && isAbove != pair.wasAbove && isAbove == c.above
+	pair.wasAbove, pair.primed = isAbove, true
+	return fast, crossed
+}
+
+// RSIAbove triggers when the RSI of closes rises above level, e.g.
+// RSIAbove(14, 70) for overbought. It fires again only after falling back.
+func RSIAbove(period int, level float64) CandleCondition {
+	return &rsiLevel{period: period, level: level, above: true, series: make(map[seriesKey]*rsiState)}
+}
+
+// RSIBelow triggers when the RSI of closes falls below level, e.g.
+// RSIBelow(14, 30) for oversold. It fires again only after rising back.
+func RSIBelow(period int, level float64) CandleCondition {
+	return &rsiLevel{period: period, level: level, series: make(map[seriesKey]*rsiState)}
+}
+
+type rsiState struct {
+	rsi    *indicator.RSI
+	beyond bool // Whether the RSI is currently past the level
+}
+
+type rsiLevel struct {
+	mu     sync.Mutex
+	period int
+	level  float64
+	above  bool
+	series map[seriesKey]*rsiState
+}
+
+func (c *rsiLevel) CheckCandle(bar candle.Candle) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := seriesKey{bar.Symbol, bar.Interval}
+	state := c.series[key]
+	if state == nil {
+		state = &rsiState{rsi: indicator.NewRSI(c.period)}
+		c.series[key] = state
+	}
+	value := state.rsi.Update(bar.Close)
+	if !state.rsi.Ready() {
+		return value, false
+	}
+
+	beyond := value < c.level
+	if c.above {
+		beyond = value > c.level
+	}
+	triggered := beyond && !state.beyond
+	state.beyond = beyond
+	return value, triggered
+}
@@ -0,0 +1,243 @@
+package backtest
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tradermade/Go-SDK/candle"
+	"github.com/tradermade/Go-SDK/clock"
+	"github.com/tradermade/Go-SDK/feed"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Clock is the simulated clock advanced by the replay. Now returns the time of
+// the event currently being delivered. It implements clock.Clock, so it can
+// drive components with a Clock field, such as candle.Builder; their timers
+// and tickers fire as the replay reaches them.
+type Clock struct {
+	fake clock.Fake
+}
+
+var _ clock.Clock = (*Clock)(nil)
+
+// Now returns the current simulated time
+func (c *Clock) Now() time.Time {
+	return c.fake.Now()
+}
+
+// After returns a channel receiving the simulated time once the replay
+// reaches now plus d
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.fake.After(d)
+}
+
+// NewTicker returns a ticker firing every d of simulated time
+func (c *Clock) NewTicker(d time.Duration) clock.Ticker {
+	return c.fake.NewTicker(d)
+}
+
+func (c *Clock) set(t time.Time) {
+	c.fake.Set(t)
+}
+
+// Feed replays events in chronological order. Candle events are also delivered
+// as quotes priced at the candle's close, so quote-driven strategies can run
+// on bar data.
+type Feed struct {
+	MessageHandler func(tradermadews.QuoteMessage, string) // Handles every replayed quote
+	CandleHandler  func(candle.Candle)                     // Handles every replayed candle
+
+	clock   Clock
+	events  []Event
+	mu      sync.Mutex
+	symbols map[string]bool // Subscribed symbols, empty replays everything
+	quotes  chan tradermadews.QuoteMessage
+	stop    chan struct{}
+	done    chan struct{}
+	started bool
+}
+
+var _ feed.MarketDataFeed = (*Feed)(nil)
+
+// NewFeed merges the event sets into one chronological stream. Events with equal
+// times keep their input order.
+func NewFeed(eventSets ...[]Event) *Feed {
+	var events []Event
+	for _, set := range eventSets {
+		events = append(events, set...)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+
+	f := &Feed{
+		events:  events,
+		symbols: make(map[string]bool),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if len(events) > 0 {
+		f.clock.set(events[0].Time)
+	}
+	return f
+}
+
+// SetMessageHandler sets the callback function to handle replayed quotes
+func (f *Feed) SetMessageHandler(handler func(tradermadews.QuoteMessage, string)) {
+	f.MessageHandler = handler
+}
+
+// SetCandleHandler sets the callback function to handle replayed candles
+func (f *Feed) SetCandleHandler(handler func(candle.Candle)) {
+	f.CandleHandler = handler
+}
+
+// Clock returns the simulated clock driven by the replay
+func (f *Feed) Clock() *Clock {
+	return &f.clock
+}
+
+// Len returns the number of events in the stream
+func (f *Feed) Len() int {
+	return len(f.events)
+}
+
+// Subscribe restricts the replay to the given symbols. Without a
+// subscription every symbol is replayed.
+func (f *Feed) Subscribe(symbols ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, s := range symbols {
+		f.symbols[s] = true
+	}
+	return nil
+}
+
+// Quotes returns a channel receiving every replayed quote. Unlike the live
+// client, a backtest never drops quotes: once Quotes has been called the
+// replay waits for each quote to be received. The channel is closed when the
+// replay ends.
+func (f *Feed) Quotes() <-chan tradermadews.QuoteMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.quotes == nil {
+		f.quotes = make(chan tradermadews.QuoteMessage)
+	}
+	return f.quotes
+}
+
+// Connect starts the replay in the background
+func (f *Feed) Connect() error {
+	f.mu.Lock()
+	if f.started {
+		f.mu.Unlock()
+		return fmt.Errorf("backtest feed already started")
+	}
+	f.started = true
+	f.mu.Unlock()
+
+	go f.replay()
+	return nil
+}
+
+// Run replays the whole stream synchronously and returns when it ends
+func (f *Feed) Run() error {
+	if err := f.Connect(); err != nil {
+		return err
+	}
+	<-f.done
+	return nil
+}
+
+// Done is closed when the replay has delivered every event or was closed
+func (f *Feed) Done() <-chan struct{} {
+	return f.done
+}
+
+// Close stops the replay
+func (f *Feed) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	select {
+	case <-f.stop:
+	default:
+		close(f.stop)
+	}
+	if !f.started {
+		f.started = true
+		close(f.done)
+	}
+	return nil
+}
+
+func (f *Feed) replay() {
+	defer close(f.done)
+
+	f.mu.Lock()
+	quotes := f.quotes
+	f.mu.Unlock()
+	if quotes != nil {
+		defer close(quotes)
+	}
+
+	for i := range f.events {
+		event := &f.events[i]
+		if !f.wants(event.Symbol) {
+			continue
+		}
+		f.clock.set(event.Time)
+
+		if event.Candle != nil && f.CandleHandler != nil {
+			f.CandleHandler(*event.Candle)
+		}
+
+		quote, ok := eventQuote(event)
+		if !ok {
+			continue
+		}
+		if f.MessageHandler != nil {
+			f.MessageHandler(quote, event.Time.Format("2006-01-02 15:04:05.000"))
+		}
+		if quotes != nil {
+			select {
+			case quotes <- quote:
+			case <-f.stop:
+				return
+			}
+		}
+
+		select {
+		case <-f.stop:
+			return
+		default:
+		}
+	}
+}
+
+func (f *Feed) wants(symbol string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.symbols) == 0 || f.symbols[symbol]
+}
+
+// eventQuote returns the quote delivered for an event
+func eventQuote(event *Event) (tradermadews.QuoteMessage, bool) {
+	if event.Quote != nil {
+		return *event.Quote, true
+	}
+	if event.Candle != nil {
+		c := event.Candle
+		return tradermadews.QuoteMessage{
+			Symbol: c.Symbol,
+			Bid:    c.Close,
+			Ask:    c.Close,
+			Mid:    c.Close,
+			Ts:     strconv.FormatInt(event.Time.UnixMilli(), 10),
+			Time:   event.Time,
+		}, true
+	}
+	return tradermadews.QuoteMessage{}, false
+}
@@ -0,0 +1,165 @@
+// Package backtest loads historical candles and ticks, from the API or from
+// recorded files, and replays them in chronological order on a simulated
+// clock. Feed implements feed.MarketDataFeed, so strategies written against
+// the live feed can be backtested unchanged.
+package backtest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tradermade/Go-SDK/candle"
+	tradermade "github.com/tradermade/Go-SDK/rest"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Event is a single tick or candle in the replay stream
+type Event struct {
+	Time   time.Time // When the event becomes known: tick time, or the close of a candle
+	Symbol string
+	Quote  *tradermadews.QuoteMessage // Set for tick events
+	Candle *candle.Candle             // Set for candle events
+}
+
+// LoadTimeSeries fetches a timeseries from the API and converts each bar to a
+// candle event. Arguments are the same as RESTClient.GetTimeSeriesData.
+func LoadTimeSeries(client *tradermade.RESTClient, symbol, startDate, endDate, interval string, period ...int) ([]Event, error) {
+	series, err := client.GetTimeSeriesData(symbol, startDate, endDate, interval, period...)
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := barDuration(interval, period...)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]Event, 0, len(series.Quotes))
+	for _, q := range series.Quotes {
+		c, err := q.Candle(symbol, duration)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, CandleEvent(c, duration))
+	}
+	return events, nil
+}
+
+// LoadTickCSV reads ticks for one symbol from CSV with a ts,bid,ask,mid header,
+// as written by the archive sink. ts is a millisecond epoch.
+func LoadTickCSV(r io.Reader, symbol string) ([]Event, error) {
+	rows, err := readCSV(r, []string{"ts", "bid", "ask", "mid"})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]Event, 0, len(rows))
+	for i, row := range rows {
+		quote := tradermadews.QuoteMessage{Symbol: symbol, Ts: row[0]}
+		if quote.Bid, quote.Ask, quote.Mid, err = parseFloats3(row[1], row[2], row[3]); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+2, err)
+		}
+		event, err := QuoteEvent(quote)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+2, err)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// LoadCandleCSV reads bars of the given duration for one symbol from CSV with a
+// date,open,high,low,close header. Dates use the layouts of the API's timeseries responses.
+func LoadCandleCSV(r io.Reader, symbol string, duration time.Duration) ([]Event, error) {
+	rows, err := readCSV(r, []string{"date", "open", "high", "low", "close"})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]Event, 0, len(rows))
+	for i, row := range rows {
+		t, err := tradermade.ParseDate(row[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+2, err)
+		}
+		c := candle.Candle{Symbol: symbol, Time: t, Interval: duration}
+		if c.Open, err = strconv.ParseFloat(row[1], 64); err == nil {
+			c.High, c.Low, c.Close, err = parseFloats3(row[2], row[3], row[4])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+2, err)
+		}
+		events = append(events, CandleEvent(c, duration))
+	}
+	return events, nil
+}
+
+// QuoteEvent wraps a quote as an event at its own timestamp
+func QuoteEvent(quote tradermadews.QuoteMessage) (Event, error) {
+	ts, err := quote.Timestamp()
+	if err != nil {
+		return Event{}, fmt.Errorf("invalid timestamp %q for %s: %w", quote.Ts, quote.Symbol, err)
+	}
+	quote.Time = ts
+	return Event{Time: ts, Symbol: quote.Symbol, Quote: &quote}, nil
+}
+
+// CandleEvent wraps a candle as an event at its close (start plus duration),
+// so strategies never see a bar before it has completed
+func CandleEvent(c candle.Candle, duration time.Duration) Event {
+	return Event{Time: c.Time.Add(duration), Symbol: c.Symbol, Candle: &c}
+}
+
+// barDuration returns the bar length for a timeseries interval and period
+func barDuration(interval string, period ...int) (time.Duration, error) {
+	p := 1
+	if len(period) > 0 {
+		p = period[0]
+	}
+	switch strings.ToLower(interval) {
+	case "daily":
+		return 24 * time.Hour, nil
+	case "hourly":
+		return time.Duration(p) * time.Hour, nil
+	case "minute":
+		return time.Duration(p) * time.Minute, nil
+	default:
+		return 0, fmt.Errorf("invalid interval: %s", interval)
+	}
+}
+
+// readCSV reads all rows after checking the header matches the expected columns
+func readCSV(r io.Reader, columns []string) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(columns)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	for i, name := range columns {
+		if strings.ToLower(strings.TrimSpace(header[i])) != name {
+			return nil, fmt.Errorf("unexpected CSV header %v, want %v", header, columns)
+		}
+	}
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	return rows, nil
+}
+
+func parseFloats3(a, b, c string) (x, y, z float64, err error) {
+	if x, err = strconv.ParseFloat(a, 64); err != nil {
+		return
+	}
+	if y, err = strconv.ParseFloat(b, 64); err != nil {
+		return
+	}
+	z, err = strconv.ParseFloat(c, 64)
+	return
+}
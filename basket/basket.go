@@ -0,0 +1,223 @@
+// Package basket computes weighted currency baskets and indices, such as a
+// DXY-style dollar index or a custom portfolio index, from live quotes or
+// from historical timeseries.
+package basket
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	tradermade "github.com/tradermade/Go-SDK/rest"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Method is how component prices are combined
+type Method int
+
+const (
+	Geometric  Method = iota // Constant times the product of price^weight, as used by the dollar index
+	Arithmetic               // Constant times the sum of price*weight
+)
+
+// Component is one symbol of a basket and its weight. A negative weight in a
+// geometric basket inverts the pair, e.g. EURUSD with -0.576 in the dollar index.
+type Component struct {
+	Symbol string
+	Weight float64
+}
+
+// Value is the basket's value at one time
+type Value struct {
+	Name   string
+	Value  float64
+	Time   time.Time
+	Symbol string // Component whose quote triggered the update, empty for historical values
+}
+
+// Basket maintains the value of a weighted basket from streamed quotes
+type Basket struct {
+	Name       string
+	Components []Component
+	Method     Method
+	Constant   float64                                 // Multiplier applied to the combined prices (default 1)
+	Price      func(tradermadews.QuoteMessage) float64 // Price used for each component (default mid)
+	OnUpdate   func(Value)                             // Called with the new value after every component quote
+
+	mu     sync.Mutex
+	prices map[string]float64
+	last   Value
+	ready  bool // Whether last holds a value
+	values chan Value
+}
+
+// New creates a basket of the given components
+func New(name string, method Method, constant float64, components ...Component) *Basket {
+	if constant == 0 {
+		constant = 1
+	}
+	return &Basket{
+		Name:       name,
+		Components: components,
+		Method:     method,
+		Constant:   constant,
+		prices:     make(map[string]float64),
+		values:     make(chan Value, 256),
+	}
+}
+
+// DollarIndex creates a basket with the ICE US Dollar Index (DXY) formula
+func DollarIndex() *Basket {
+	return New("DXY", Geometric, 50.14348112,
+		Component{"EURUSD", -0.576},
+		Component{"USDJPY", 0.136},
+		Component{"GBPUSD", -0.119},
+		Component{"USDCAD", 0.091},
+		Component{"USDSEK", 0.042},
+		Component{"USDCHF", 0.036},
+	)
+}
+
+// SetUpdateHandler sets the callback function for basket value updates
+func (b *Basket) SetUpdateHandler(handler func(Value)) {
+	b.OnUpdate = handler
+}
+
+// Symbols returns the component symbols, e.g. to subscribe to them
+func (b *Basket) Symbols() []string {
+	symbols := make([]string, len(b.Components))
+	for i, c := range b.Components {
+		symbols[i] = c.Symbol
+	}
+	return symbols
+}
+
+// Compute returns the basket's value for the given prices by symbol
+func (b *Basket) Compute(prices map[string]float64) (float64, error) {
+	constant := b.Constant
+	if constant == 0 {
+		constant = 1
+	}
+	value := 1.0
+	if b.Method == Arithmetic {
+		value = 0
+	}
+	for _, c := range b.Components {
+		price, ok := prices[c.Symbol]
+		if !ok {
+			return 0, fmt.Errorf("no price for %s", c.Symbol)
+		}
+		if b.Method == Arithmetic {
+			value += price * c.Weight
+			continue
+		}
+		if price <= 0 {
+			return 0, fmt.Errorf("invalid price %v for %s in geometric basket", price, c.Symbol)
+		}
+		value *= math.Pow(price, c.Weight)
+	}
+	return constant * value, nil
+}
+
+// HandleQuote matches the WebSocket client's message handler signature
+func (b *Basket) HandleQuote(quote tradermadews.QuoteMessage, _ string) {
+	b.AddQuote(quote)
+}
+
+// AddQuote updates a component's price and, once every component has a
+// price, emits the new basket value. Quotes for other symbols are ignored.
+func (b *Basket) AddQuote(quote tradermadews.QuoteMessage) {
+	price := quote.Mid
+	if b.Price != nil {
+		price = b.Price(quote)
+	}
+	t := quote.Time
+	if t.IsZero() {
+		t, _ = quote.Timestamp()
+	}
+
+	b.mu.Lock()
+	if !b.isComponent(quote.Symbol) {
+		b.mu.Unlock()
+		return
+	}
+	b.prices[quote.Symbol] = price
+	value, err := b.Compute(b.prices)
+	if err != nil {
+		b.mu.Unlock()
+		return // Waiting for the remaining components
+	}
+	v := Value{Name: b.Name, Value: value, Time: t, Symbol: quote.Symbol}
+	b.last, b.ready = v, true
+	b.mu.Unlock()
+
+	if b.OnUpdate != nil {
+		b.OnUpdate(v)
+	}
+	select {
+	case b.values <- v:
+	default:
+	}
+}
+
+func (b *Basket) isComponent(symbol string) bool {
+	for _, c := range b.Components {
+		if c.Symbol == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns the latest basket value, or false before every component has a price
+func (b *Basket) Value() (Value, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.last, b.ready
+}
+
+// Values returns a channel receiving every basket value. Values are dropped
+// when the channel's buffer is full.
+func (b *Basket) Values() <-chan Value {
+	return b.values
+}
+
+// History computes the basket from each component's timeseries closes,
+// returning one value per bar at which every component has a close. Arguments
+// are the same as RESTClient.GetTimeSeriesDataRange; one request is made per
+// component.
+func (b *Basket) History(client *tradermade.RESTClient, start, end time.Time, interval string, period ...int) ([]Value, error) {
+	closes := make(map[time.Time]map[string]float64)
+	for _, c := range b.Components {
+		series, err := client.GetTimeSeriesDataRange(c.Symbol, start, end, interval, period...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %w", c.Symbol, err)
+		}
+		for _, q := range series.Quotes {
+			t, err := tradermade.ParseDate(q.Date)
+			if err != nil {
+				return nil, err
+			}
+			if closes[t] == nil {
+				closes[t] = make(map[string]float64, len(b.Components))
+			}
+			closes[t][c.Symbol] = q.Close
+		}
+	}
+
+	values := make([]Value, 0, len(closes))
+	for t, prices := range closes {
+		if len(prices) < len(b.Components) {
+			continue // A component has no bar at t
+		}
+		value, err := b.Compute(prices)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute %s at %s: %w", b.Name, t.Format(time.RFC3339), err)
+		}
+		values = append(values, Value{Name: b.Name, Value: value, Time: t})
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Time.Before(values[j].Time) })
+	return values, nil
+}
@@ -0,0 +1,195 @@
+package candle
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tradermade/Go-SDK/clock"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Builder aggregates streamed quotes into OHLC bars for one or more intervals
+// at once. Bars are aligned to UTC boundaries (a 5m bar starts at :00, :05, ...).
+type Builder struct {
+	Intervals []time.Duration
+	Price     func(tradermadews.QuoteMessage) float64 // Price used for the bars (default mid)
+	OnClose   func(Candle)                            // Called once per completed bar
+	OnUpdate  func(Candle)                            // Optional, called with the in-progress bar after every tick
+	Clock     clock.Clock                             // Drives Start, nil uses the system clock
+
+	mu     sync.Mutex
+	bars   map[barKey]*Candle
+	closed map[barKey]time.Time // End of the last closed bar; earlier ticks are late
+	stop   chan struct{}
+}
+
+// barKey identifies the open bar for a symbol and interval
+type barKey struct {
+	symbol   string
+	interval time.Duration
+}
+
+// NewBuilder creates a builder for the given intervals, e.g. time.Second, time.Minute, 5*time.Minute
+func NewBuilder(intervals ...time.Duration) *Builder {
+	return &Builder{
+		Intervals: intervals,
+		bars:      make(map[barKey]*Candle),
+		closed:    make(map[barKey]time.Time),
+	}
+}
+
+// SetCloseHandler sets the callback function for completed bars
+func (b *Builder) SetCloseHandler(handler func(Candle)) {
+	b.OnClose = handler
+}
+
+// SetUpdateHandler sets the callback function for in-progress bar updates
+func (b *Builder) SetUpdateHandler(handler func(Candle)) {
+	b.OnUpdate = handler
+}
+
+// AddQuote adds a tick to the open bar of every interval. A tick falling in a
+// later bar closes the current one first; ticks for a bar that has already
+// closed are ignored.
+func (b *Builder) AddQuote(quote tradermadews.QuoteMessage) error {
+	ts, err := quote.Timestamp()
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q for %s: %w", quote.Ts, quote.Symbol, err)
+	}
+	price := quote.Mid
+	if b.Price != nil {
+		price = b.Price(quote)
+	}
+
+	var closed, updated []Candle
+	b.mu.Lock()
+	for _, interval := range b.Intervals {
+		if interval <= 0 {
+			continue
+		}
+		start := ts.UTC().Truncate(interval)
+		key := barKey{quote.Symbol, interval}
+		bar := b.bars[key]
+
+		if start.Before(b.closed[key]) || (bar != nil && start.Before(bar.Time)) {
+			continue // Late tick for a bar that has already closed
+		}
+		if bar != nil && start.After(bar.Time) {
+			closed = append(closed, *bar)
+			b.closed[key] = bar.End()
+			bar = nil
+		}
+		if bar == nil {
+			bar = &Candle{
+				Symbol:   quote.Symbol,
+				Time:     start,
+				Interval: interval,
+				Open:     price,
+				High:     price,
+				Low:      price,
+				Close:    price,
+			}
+			b.bars[key] = bar
+		} else if price != bar.Close {
+			bar.Updates++
+		}
+		bar.Ticks++
+		if price > bar.High {
+			bar.High = price
+		}
+		if price < bar.Low {
+			bar.Low = price
+		}
+		bar.Close = price
+		updated = append(updated, *bar)
+	}
+	b.mu.Unlock()
+
+	b.emit(closed, updated)
+	return nil
+}
+
+// HandleQuote can be passed to WebSocketClient.SetMessageHandler directly
+func (b *Builder) HandleQuote(quote tradermadews.QuoteMessage, _ string) {
+	if err := b.AddQuote(quote); err != nil {
+		fmt.Printf("Candle builder: %v\n", err)
+	}
+}
+
+// CloseExpired closes every open bar whose end is at or before now. Without
+// it a bar only closes when the next tick for its symbol arrives.
+func (b *Builder) CloseExpired(now time.Time) {
+	var closed []Candle
+	b.mu.Lock()
+	for key, bar := range b.bars {
+		if !now.Before(bar.End()) {
+			closed = append(closed, *bar)
+			b.closed[key] = bar.End()
+			delete(b.bars, key)
+		}
+	}
+	b.mu.Unlock()
+
+	b.emit(closed, nil)
+}
+
+// Start closes expired bars against the Clock every tick, so bars close
+// on time in quiet markets. grace allows for feed latency before closing.
+func (b *Builder) Start(tick, grace time.Duration) {
+	b.mu.Lock()
+	if b.stop != nil {
+		b.mu.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	b.stop = stop
+	b.mu.Unlock()
+
+	go func() {
+		ticker := clock.OrDefault(b.Clock).NewTicker(tick)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C():
+				b.CloseExpired(now.Add(-grace))
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the background closing started by Start
+func (b *Builder) Stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stop != nil {
+		close(b.stop)
+		b.stop = nil
+	}
+}
+
+// Current returns the open bar for a symbol and interval
+func (b *Builder) Current(symbol string, interval time.Duration) (Candle, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	bar, ok := b.bars[barKey{symbol, interval}]
+	if !ok {
+		return Candle{}, false
+	}
+	return *bar, true
+}
+
+func (b *Builder) emit(closed, updated []Candle) {
+	if b.OnClose != nil {
+		for _, c := range closed {
+			b.OnClose(c)
+		}
+	}
+	if b.OnUpdate != nil {
+		for _, c := range updated {
+			b.OnUpdate(c)
+		}
+	}
+}
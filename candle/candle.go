@@ -0,0 +1,8 @@
+// Package candle defines the OHLC bar type shared by the SDK's historical,
+// streaming and backtesting components, and builds bars from streamed ticks.
+package candle
+
+import "github.com/tradermade/Go-SDK/ohlc"
+
+// Candle is an OHLC bar for one symbol starting at Time
+type Candle = ohlc.Candle
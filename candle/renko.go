@@ -0,0 +1,271 @@
+package candle
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// PipSize returns the pip of an FX pair: 0.01 for pairs quoted in JPY and
+// 0.0001 otherwise. Metals, crypto and CFDs have no standard pip and should
+// use absolute sizes.
+func PipSize(symbol string) float64 {
+	if strings.HasSuffix(strings.ToUpper(symbol), "JPY") {
+		return 0.01
+	}
+	return 0.0001
+}
+
+// Renko builds Renko bricks from ticks or candles. A brick completes each
+// time the price moves one brick size beyond the last brick; reversing needs
+// a move of two brick sizes. Bricks are candles with a zero Interval, timed
+// at the tick that completed them.
+type Renko struct {
+	BrickSize float64                                 // Absolute brick height, used when Pips is zero
+	Pips      float64                                 // Brick height in pips of each symbol, see PipSize
+	Price     func(tradermadews.QuoteMessage) float64 // Price used for the bricks (default mid)
+	OnBrick   func(Candle)                            // Called once per completed brick
+
+	mu     sync.Mutex
+	states map[string]*renkoState
+}
+
+// renkoState is the last brick of a symbol
+type renkoState struct {
+	level float64 // Close of the last brick, or the first price
+	dir   int     // Direction of the last brick, zero before the first
+}
+
+// NewRenko creates a Renko builder with an absolute brick size
+func NewRenko(brickSize float64) *Renko {
+	return &Renko{BrickSize: brickSize, states: make(map[string]*renkoState)}
+}
+
+// NewRenkoPips creates a Renko builder with a brick size in pips
+func NewRenkoPips(pips float64) *Renko {
+	return &Renko{Pips: pips, states: make(map[string]*renkoState)}
+}
+
+// SetBrickHandler sets the callback function for completed bricks
+func (r *Renko) SetBrickHandler(handler func(Candle)) {
+	r.OnBrick = handler
+}
+
+// AddQuote adds a tick and returns the bricks it completed
+func (r *Renko) AddQuote(quote tradermadews.QuoteMessage) ([]Candle, error) {
+	ts, err := quoteTime(quote)
+	if err != nil {
+		return nil, err
+	}
+	price := quote.Mid
+	if r.Price != nil {
+		price = r.Price(quote)
+	}
+	return r.add(quote.Symbol, price, ts), nil
+}
+
+// HandleQuote can be passed to WebSocketClient.SetMessageHandler directly
+func (r *Renko) HandleQuote(quote tradermadews.QuoteMessage, _ string) {
+	if _, err := r.AddQuote(quote); err != nil {
+		fmt.Printf("Renko: %v\n", err)
+	}
+}
+
+// AddCandle adds a bar's prices in the order they most likely traded (open,
+// the extreme nearer the open, the other extreme, close) and returns the
+// bricks they completed
+func (r *Renko) AddCandle(c Candle) []Candle {
+	var bricks []Candle
+	for _, price := range candlePath(c) {
+		bricks = append(bricks, r.add(c.Symbol, price, c.End())...)
+	}
+	return bricks
+}
+
+func (r *Renko) add(symbol string, price float64, t time.Time) []Candle {
+	size := r.BrickSize
+	if r.Pips > 0 {
+		size = r.Pips * PipSize(symbol)
+	}
+	if size <= 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	if r.states == nil {
+		r.states = make(map[string]*renkoState)
+	}
+	state := r.states[symbol]
+	if state == nil {
+		r.states[symbol] = &renkoState{level: price}
+		r.mu.Unlock()
+		return nil
+	}
+
+	var bricks []Candle
+	brick := func(open, close float64, dir int) {
+		bricks = append(bricks, Candle{Symbol: symbol, Time: t, Open: open, Close: close, High: max(open, close), Low: min(open, close)})
+		state.level, state.dir = close, dir
+	}
+	for {
+		switch {
+		case state.dir >= 0 && price >= state.level+size:
+			brick(state.level, state.level+size, 1)
+		case state.dir <= 0 && price <= state.level-size:
+			brick(state.level, state.level-size, -1)
+		case state.dir > 0 && price <= state.level-2*size:
+			brick(state.level-size, state.level-2*size, -1)
+		case state.dir < 0 && price >= state.level+2*size:
+			brick(state.level+size, state.level+2*size, 1)
+		default:
+			r.mu.Unlock()
+			if r.OnBrick != nil {
+				for _, b := range bricks {
+					r.OnBrick(b)
+				}
+			}
+			return bricks
+		}
+	}
+}
+
+// RangeBars builds bars that each span a fixed price range. A bar closes when
+// the price would take it beyond the range, and the next bar opens at its close.
+// Range bars are candles with a zero Interval, timed at the tick that opened them.
+type RangeBars struct {
+	Size    float64                                 // Absolute bar range, used when Pips is zero
+	Pips    float64                                 // Bar range in pips of each symbol, see PipSize
+	Price   func(tradermadews.QuoteMessage) float64 // Price used for the bars (default mid)
+	OnClose func(Candle)                            // Called once per completed bar
+
+	mu   sync.Mutex
+	bars map[string]*Candle
+}
+
+// NewRangeBars creates a range bar builder with an absolute range
+func NewRangeBars(size float64) *RangeBars {
+	return &RangeBars{Size: size, bars: make(map[string]*Candle)}
+}
+
+// NewRangeBarsPips creates a range bar builder with a range in pips
+func NewRangeBarsPips(pips float64) *RangeBars {
+	return &RangeBars{Pips: pips, bars: make(map[string]*Candle)}
+}
+
+// SetCloseHandler sets the callback function for completed bars
+func (rb *RangeBars) SetCloseHandler(handler func(Candle)) {
+	rb.OnClose = handler
+}
+
+// AddQuote adds a tick and returns the bars it completed
+func (rb *RangeBars) AddQuote(quote tradermadews.QuoteMessage) ([]Candle, error) {
+	ts, err := quoteTime(quote)
+	if err != nil {
+		return nil, err
+	}
+	price := quote.Mid
+	if rb.Price != nil {
+		price = rb.Price(quote)
+	}
+	return rb.add(quote.Symbol, price, ts), nil
+}
+
+// HandleQuote can be passed to WebSocketClient.SetMessageHandler directly
+func (rb *RangeBars) HandleQuote(quote tradermadews.QuoteMessage, _ string) {
+	if _, err := rb.AddQuote(quote); err != nil {
+		fmt.Printf("Range bars: %v\n", err)
+	}
+}
+
+// AddCandle adds a bar's prices in the order they most likely traded and
+// returns the range bars they completed
+func (rb *RangeBars) AddCandle(c Candle) []Candle {
+	var closed []Candle
+	for _, price := range candlePath(c) {
+		closed = append(closed, rb.add(c.Symbol, price, c.End())...)
+	}
+	return closed
+}
+
+// Current returns the open range bar for a symbol
+func (rb *RangeBars) Current(symbol string) (Candle, bool) {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	bar, ok := rb.bars[symbol]
+	if !ok {
+		return Candle{}, false
+	}
+	return *bar, true
+}
+
+func (rb *RangeBars) add(symbol string, price float64, t time.Time) []Candle {
+	size := rb.Size
+	if rb.Pips > 0 {
+		size = rb.Pips * PipSize(symbol)
+	}
+	if size <= 0 {
+		return nil
+	}
+
+	rb.mu.Lock()
+	if rb.bars == nil {
+		rb.bars = make(map[string]*Candle)
+	}
+	bar := rb.bars[symbol]
+	if bar == nil {
+		rb.bars[symbol] = &Candle{Symbol: symbol, Time: t, Open: price, High: price, Low: price, Close: price, Ticks: 1}
+		rb.mu.Unlock()
+		return nil
+	}
+
+	var closed []Candle
+	for {
+		if price > bar.Low+size {
+			// Close at the top of the range and continue from there
+			bar.High, bar.Close = bar.Low+size, bar.Low+size
+		} else if price < bar.High-size {
+			bar.Low, bar.Close = bar.High-size, bar.High-size
+		} else {
+			if price != bar.Close {
+				bar.Updates++
+			}
+			bar.High, bar.Low, bar.Close = max(bar.High, price), min(bar.Low, price), price
+			bar.Ticks++
+			break
+		}
+		closed = append(closed, *bar)
+		bar = &Candle{Symbol: symbol, Time: t, Open: bar.Close, High: bar.Close, Low: bar.Close, Close: bar.Close}
+		rb.bars[symbol] = bar
+	}
+	rb.mu.Unlock()
+
+	if rb.OnClose != nil {
+		for _, c := range closed {
+			rb.OnClose(c)
+		}
+	}
+	return closed
+}
+
+// candlePath returns a bar's prices in the order they most likely traded
+func candlePath(c Candle) []float64 {
+	if c.IsBullish() {
+		return []float64{c.Open, c.Low, c.High, c.Close}
+	}
+	return []float64{c.Open, c.High, c.Low, c.Close}
+}
+
+// quoteTime returns the quote's time, parsing Ts if Time is not set
+func quoteTime(quote tradermadews.QuoteMessage) (time.Time, error) {
+	if !quote.Time.IsZero() {
+		return quote.Time, nil
+	}
+	ts, err := quote.Timestamp()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q for %s: %w", quote.Ts, quote.Symbol, err)
+	}
+	return ts, nil
+}
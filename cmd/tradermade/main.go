@@ -0,0 +1,55 @@
+// Command tradermade is a small command line client for the TraderMade API.
+//
+// Usage:
+//
+//	tradermade watch [-key KEY] EURUSD GBPUSD ...
+//
+// The WebSocket key can also be supplied through TRADERMADE_WS_KEY.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "watch":
+		err = runWatch(os.Args[2:])
+	case "help", "-h", "--help":
+		usage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage: tradermade <command> [flags] [args]
+
+Commands:
+  watch SYMBOL...   live-updating table of streamed quotes
+
+Run "tradermade <command> -h" for command flags.`)
+}
+
+// envOrFlag returns the flag value, falling back to the environment variable
+func envOrFlag(value, env string) string {
+	if value != "" {
+		return value
+	}
+	return os.Getenv(env)
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// ANSI escape sequences used by the table renderer
+const (
+	ansiClear = "\033[H\033[2J"
+	ansiReset = "\033[0m"
+	ansiGreen = "\033[32m"
+	ansiRed   = "\033[31m"
+	ansiDim   = "\033[2m"
+)
+
+// watchRow holds the display state for one symbol
+type watchRow struct {
+	quote     tradermadews.QuoteMessage
+	firstMid  float64   // Mid of the first quote seen, used for the change column
+	direction int       // +1 last tick up, -1 down, 0 unchanged
+	updated   time.Time // Local time of the last update
+}
+
+// watchTable is the shared state between the message handler and the renderer
+type watchTable struct {
+	mu      sync.Mutex
+	rows    map[string]*watchRow
+	status  string
+	lastErr error // Latest client error, returned if the client gives up
+	noColor bool
+}
+
+func runWatch(args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	key := fs.String("key", "", "WebSocket API key (default $TRADERMADE_WS_KEY)")
+	refresh := fs.Duration("refresh", 250*time.Millisecond, "screen refresh interval")
+	noColor := fs.Bool("no-color", false, "disable colored output")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: tradermade watch [flags] SYMBOL...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	apiKey := envOrFlag(*key, "TRADERMADE_WS_KEY")
+	if apiKey == "" {
+		return fmt.Errorf("a WebSocket key is required (-key or TRADERMADE_WS_KEY)")
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("at least one symbol is required")
+	}
+
+	symbols := make([]string, 0, fs.NArg())
+	for _, arg := range fs.Args() {
+		for _, s := range strings.Split(arg, ",") {
+			if s = strings.ToUpper(strings.TrimSpace(s)); s != "" {
+				symbols = append(symbols, s)
+			}
+		}
+	}
+
+	table := &watchTable{
+		rows:    make(map[string]*watchRow),
+		status:  "connecting...",
+		noColor: *noColor,
+	}
+	for _, s := range symbols {
+		table.rows[s] = &watchRow{}
+	}
+
+	client := tradermadews.NewWebSocketClient(apiKey, strings.Join(symbols, ","))
+	client.SetConnectedHandler(func(msg tradermadews.ConnectedMessage) {
+		table.setStatus("connected")
+	})
+	client.SetReconnectionHandler(func(attempt int) {
+		table.setStatus(fmt.Sprintf("reconnecting (attempt %d)", attempt))
+	})
+	client.SetMessageHandler(func(quote tradermadews.QuoteMessage, _ string) {
+		table.update(quote)
+	})
+	client.SetErrorHandler(table.setError)
+
+	// The client prints reconnect progress and status frames to stdout, which
+	// would scroll the table; the handlers above show them in the status line
+	screen := os.Stdout
+	if devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0); err == nil {
+		os.Stdout = devNull
+		defer func() {
+			os.Stdout = screen
+			devNull.Close()
+		}()
+	}
+
+	if err := client.Connect(); err != nil {
+		return err
+	}
+	defer client.Disconnect()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	ticker := time.NewTicker(*refresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			fmt.Fprint(screen, table.render())
+		case <-client.Done():
+			fmt.Fprint(screen, table.render())
+			if err := table.err(); err != nil {
+				return fmt.Errorf("connection lost: %w", err)
+			}
+			return fmt.Errorf("connection lost")
+		case <-stop:
+			fmt.Fprintln(screen)
+			return nil
+		}
+	}
+}
+
+func (t *watchTable) setStatus(status string) {
+	t.mu.Lock()
+	t.status = status
+	t.mu.Unlock()
+}
+
+func (t *watchTable) setError(err error) {
+	t.mu.Lock()
+	t.status = "error: " + err.Error()
+	t.lastErr = err
+	t.mu.Unlock()
+}
+
+func (t *watchTable) err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lastErr
+}
+
+func (t *watchTable) update(quote tradermadews.QuoteMessage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	row, ok := t.rows[quote.Symbol]
+	if !ok {
+		row = &watchRow{}
+		t.rows[quote.Symbol] = row
+	}
+	if row.firstMid == 0 {
+		row.firstMid = quote.Mid
+	}
+	switch {
+	case row.quote.Mid == 0 || quote.Mid == row.quote.Mid:
+		row.direction = 0
+	case quote.Mid > row.quote.Mid:
+		row.direction = 1
+	default:
+		row.direction = -1
+	}
+	row.quote = quote
+	row.updated = time.Now()
+}
+
+// render draws the full table as a single string
+func (t *watchTable) render() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	symbols := make([]string, 0, len(t.rows))
+	for s := range t.rows {
+		symbols = append(symbols, s)
+	}
+	sort.Strings(symbols)
+
+	var sb strings.Builder
+	sb.WriteString(ansiClear)
+	fmt.Fprintf(&sb, "TraderMade live quotes - %s - %s\n\n", t.status, time.Now().Format("15:04:05"))
+	fmt.Fprintf(&sb, "%-10s %12s %12s %10s %12s %9s  %s\n", "SYMBOL", "BID", "ASK", "SPREAD", "CHANGE", "CHANGE%", "UPDATED")
+
+	for _, s := range symbols {
+		row := t.rows[s]
+		if row.updated.IsZero() {
+			fmt.Fprintf(&sb, "%s%-10s %12s %12s %10s %12s %9s  %s%s\n",
+				t.color(ansiDim), s, "-", "-", "-", "-", "-", "waiting", t.color(ansiReset))
+			continue
+		}
+
+		q := row.quote
+		change := q.Mid - row.firstMid
+		changePct := 0.0
+		if row.firstMid != 0 {
+			changePct = change / row.firstMid * 100
+		}
+
+		color := ""
+		switch row.direction {
+		case 1:
+			color = t.color(ansiGreen)
+		case -1:
+			color = t.color(ansiRed)
+		}
+		fmt.Fprintf(&sb, "%s%-10s %12.5f %12.5f %10.5f %+12.5f %+8.3f%%  %s%s\n",
+			color, s, q.Bid, q.Ask, q.Ask-q.Bid, change, changePct,
+			row.updated.Format("15:04:05.000"), t.color(ansiReset))
+	}
+	sb.WriteString("\nPress Ctrl+C to exit.\n")
+	return sb.String()
+}
+
+func (t *watchTable) color(code string) string {
+	if t.noColor {
+		return ""
+	}
+	return code
+}
@@ -0,0 +1,82 @@
+// Package codec defines the JSON codec used by the REST and WebSocket
+// clients, so encoding/json can be swapped for a faster implementation.
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Codec encodes and decodes JSON. Implementations must follow the semantics of
+// encoding/json closely enough to decode the SDK's struct tags.
+//
+// Adapting jsoniter, for example:
+//
+//	var fast = jsoniter.ConfigCompatibleWithStandardLibrary
+//	client.SetCodec(codec.Funcs{MarshalFunc: fast.Marshal, UnmarshalFunc: fast.Unmarshal})
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSON is the encoding/json codec, used when no codec is set
+type JSON struct{}
+
+// Marshal encodes v with encoding/json
+func (JSON) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes data into v with encoding/json
+func (JSON) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// Strict is the encoding/json codec with unknown fields rejected, for
+// detecting changes to the API's responses early
+type Strict struct{}
+
+// Marshal encodes v with encoding/json
+func (Strict) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes data into v, failing on fields v does not have
+func (Strict) Unmarshal(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// Funcs adapts a pair of functions to a Codec
+type Funcs struct {
+	MarshalFunc   func(v any) ([]byte, error)
+	UnmarshalFunc func(data []byte, v any) error
+}
+
+// Marshal calls MarshalFunc
+func (f Funcs) Marshal(v any) ([]byte, error) {
+	return f.MarshalFunc(v)
+}
+
+// Unmarshal calls UnmarshalFunc
+func (f Funcs) Unmarshal(data []byte, v any) error {
+	return f.UnmarshalFunc(data, v)
+}
+
+// OrDefault returns c, or JSON if c is nil
+func OrDefault(c Codec) Codec {
+	if c == nil {
+		return JSON{}
+	}
+	return c
+}
+
+// Lenient returns c for decoding partial views of a message, such as probing
+// a response for an error code: JSON if c is Strict or nil, otherwise c
+func Lenient(c Codec) Codec {
+	if _, ok := c.(Strict); ok {
+		return JSON{}
+	}
+	return OrDefault(c)
+}
@@ -0,0 +1,225 @@
+// Package consistency compares the WebSocket stream with the REST API. A
+// Monitor periodically fetches live rates for the streamed symbols and
+// reports symbols whose latest streamed quote disagrees with them, which
+// points at stuck subscriptions or stale caches:
+//
+//	m := consistency.New(wsClient, restClient)
+//	m.SetDivergenceHandler(func(d consistency.Divergence) {
+//		log.Printf("%s %s: stream %.5f rest %.5f", d.Symbol, d.Reason, d.Stream.Mid, d.REST.Mid)
+//	})
+//	m.Start()
+//	defer m.Stop()
+package consistency
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tradermade/Go-SDK/candle"
+	"github.com/tradermade/Go-SDK/clock"
+	"github.com/tradermade/Go-SDK/feed"
+	tradermade "github.com/tradermade/Go-SDK/rest"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Stream is the source of streamed quotes, such as a *tradermadews.WebSocketClient
+type Stream interface {
+	GetLastQuote(symbol string) (tradermadews.QuoteMessage, bool)
+	Symbols() []string
+}
+
+var _ Stream = (*tradermadews.WebSocketClient)(nil)
+
+// Reason says why a symbol diverged
+type Reason string
+
+const (
+	ReasonMissing Reason = "missing" // The stream has no quote for the symbol
+	ReasonStale   Reason = "stale"   // The streamed quote is older than MaxAge and the REST price has moved
+	ReasonPrice   Reason = "price"   // The mids differ by more than Threshold pips
+)
+
+// Divergence is a symbol whose streamed quote disagrees with the REST API
+type Divergence struct {
+	Symbol string
+	Time   time.Time // When the check ran
+	Reason Reason
+	Stream tradermadews.QuoteMessage // Latest streamed quote, zero when missing
+	REST   tradermadews.QuoteMessage // Live rate from the REST API
+	Pips   float64                   // Streamed mid minus REST mid, in pips
+	Age    time.Duration             // Age of the streamed quote when the check ran
+}
+
+// Monitor compares streamed quotes with REST live rates every Interval
+type Monitor struct {
+	Stream    Stream
+	Client    *tradermade.RESTClient
+	Symbols   []string      // Symbols to compare, empty means every streamed symbol
+	Interval  time.Duration // Time between checks (default 1m)
+	Threshold float64       // Mid difference in pips above which a symbol diverges (default 5)
+	MaxAge    time.Duration // Streamed quotes older than this are stale if the price moved, zero disables
+	Clock     clock.Clock   // Times checks, nil uses the system clock
+
+	OnDivergence func(Divergence) // Called for every divergence found by a periodic check
+	OnError      func(error)      // Called when a periodic check fails
+
+	mu   sync.Mutex
+	stop chan struct{}
+}
+
+// New creates a monitor comparing stream with client's live rates
+func New(stream Stream, client *tradermade.RESTClient) *Monitor {
+	return &Monitor{
+		Stream:    stream,
+		Client:    client,
+		Interval:  time.Minute,
+		Threshold: 5,
+	}
+}
+
+// SetThreshold sets the mid difference in pips above which a symbol diverges
+func (m *Monitor) SetThreshold(pips float64) {
+	m.Threshold = pips
+}
+
+// SetMaxAge sets the age after which a streamed quote is stale if the REST
+// price has moved since
+func (m *Monitor) SetMaxAge(age time.Duration) {
+	m.MaxAge = age
+}
+
+// SetDivergenceHandler sets the divergence handler
+func (m *Monitor) SetDivergenceHandler(handler func(Divergence)) {
+	m.OnDivergence = handler
+}
+
+// SetErrorHandler sets the error handler
+func (m *Monitor) SetErrorHandler(handler func(error)) {
+	m.OnError = handler
+}
+
+// Check fetches live rates once and returns the diverging symbols, largest
+// price difference first
+func (m *Monitor) Check() ([]Divergence, error) {
+	if m.Stream == nil || m.Client == nil {
+		return nil, fmt.Errorf("monitor needs a stream and a client")
+	}
+	symbols := m.Symbols
+	if len(symbols) == 0 {
+		symbols = m.Stream.Symbols()
+	}
+	if len(symbols) == 0 {
+		return nil, nil
+	}
+	rates, err := m.Client.GetLiveRates(symbols)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch live rates: %w", err)
+	}
+
+	now := clock.OrDefault(m.Clock).Now()
+	var divergences []Divergence
+	for _, rest := range feed.LiveRateQuotes(rates) {
+		if d, ok := m.compare(rest, now); ok {
+			divergences = append(divergences, d)
+		}
+	}
+	sort.SliceStable(divergences, func(i, j int) bool {
+		return math.Abs(divergences[i].Pips) > math.Abs(divergences[j].Pips)
+	})
+	return divergences, nil
+}
+
+// compare checks one REST rate against the latest streamed quote
+func (m *Monitor) compare(rest tradermadews.QuoteMessage, now time.Time) (Divergence, bool) {
+	d := Divergence{Symbol: rest.Symbol, Time: now, REST: rest}
+	stream, ok := m.Stream.GetLastQuote(rest.Symbol)
+	if !ok {
+		d.Reason = ReasonMissing
+		return d, true
+	}
+	d.Stream = stream
+	d.Pips = (mid(stream) - mid(rest)) / candle.PipSize(rest.Symbol)
+
+	streamTime := stream.Time
+	if streamTime.IsZero() {
+		streamTime, _ = stream.Timestamp()
+	}
+	if !streamTime.IsZero() {
+		d.Age = now.Sub(streamTime)
+	}
+
+	switch {
+	case m.MaxAge > 0 && d.Age > m.MaxAge && d.Pips != 0:
+		d.Reason = ReasonStale
+	case math.Abs(d.Pips) > m.Threshold:
+		d.Reason = ReasonPrice
+	default:
+		return d, false
+	}
+	return d, true
+}
+
+// mid returns the quote's mid, from bid and ask when it has none
+func mid(q tradermadews.QuoteMessage) float64 {
+	if q.Mid > 0 {
+		return q.Mid
+	}
+	return (q.Bid + q.Ask) / 2
+}
+
+// Start runs Check every Interval until Stop is called
+func (m *Monitor) Start() {
+	m.mu.Lock()
+	if m.stop != nil {
+		m.mu.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	m.stop = stop
+	m.mu.Unlock()
+
+	interval := m.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	go func() {
+		ticker := clock.OrDefault(m.Clock).NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C():
+				m.tick()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic checks
+func (m *Monitor) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+}
+
+func (m *Monitor) tick() {
+	divergences, err := m.Check()
+	if err != nil {
+		if m.OnError != nil {
+			m.OnError(err)
+		}
+		return
+	}
+	if m.OnDivergence != nil {
+		for _, d := range divergences {
+			m.OnDivergence(d)
+		}
+	}
+}
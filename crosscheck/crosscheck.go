@@ -0,0 +1,300 @@
+// Package crosscheck compares quoted cross rates with the rate implied by
+// their legs against a common currency, such as EURGBP against
+// EURUSD/GBPUSD, and reports the difference in pips. Large deviations point
+// at stale or bad data; a quoted bid above the synthetic ask (or ask below
+// the synthetic bid) is a triangular arbitrage.
+package crosscheck
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tradermade/Go-SDK/candle"
+	tradermade "github.com/tradermade/Go-SDK/rest"
+	"github.com/tradermade/Go-SDK/symbols"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// DefaultVia is the common currency used when none is given
+const DefaultVia = "USD"
+
+// Triangle is a cross and the two legs that imply it through Via
+type Triangle struct {
+	Cross    string // Quoted cross, e.g. EURGBP
+	Via      string // Common currency, e.g. USD
+	BaseLeg  string // Pair pricing the cross's base in Via, e.g. EURUSD
+	QuoteLeg string // Pair pricing the cross's quote currency in Via, e.g. GBPUSD
+
+	baseInverted  bool
+	quoteInverted bool
+}
+
+// NewTriangle returns the triangle for a six letter cross through via, using
+// the conventional pair for each leg
+func NewTriangle(cross, via string) (Triangle, error) {
+	cross, via = strings.ToUpper(cross), strings.ToUpper(via)
+	if via == "" {
+		via = DefaultVia
+	}
+	if len(cross) != 6 {
+		return Triangle{}, fmt.Errorf("cross %q is not a six letter currency pair", cross)
+	}
+	base, quote := cross[:3], cross[3:]
+	if base == via || quote == via {
+		return Triangle{}, fmt.Errorf("cross %s already contains %s", cross, via)
+	}
+	t := Triangle{Cross: cross, Via: via}
+	t.BaseLeg, t.baseInverted = symbols.Pair(base, via)
+	t.QuoteLeg, t.quoteInverted = symbols.Pair(quote, via)
+	return t, nil
+}
+
+// Symbols returns the cross and both legs
+func (t Triangle) Symbols() []string {
+	return []string{t.Cross, t.BaseLeg, t.QuoteLeg}
+}
+
+// Price is a bid, ask and mid
+type Price struct {
+	Bid, Ask, Mid float64
+}
+
+// normalize fills missing fields from the others
+func (p Price) normalize() Price {
+	if p.Mid <= 0 && p.Bid > 0 && p.Ask > 0 {
+		p.Mid = (p.Bid + p.Ask) / 2
+	}
+	if p.Bid <= 0 || p.Ask <= 0 {
+		p.Bid, p.Ask = p.Mid, p.Mid
+	}
+	return p
+}
+
+// invert returns the price of the reverse pair
+func (p Price) invert() Price {
+	return Price{Bid: 1 / p.Ask, Ask: 1 / p.Bid, Mid: 1 / p.Mid}
+}
+
+// Synthetic returns the cross price implied by the legs' prices
+func (t Triangle) Synthetic(baseLeg, quoteLeg Price) Price {
+	b, q := baseLeg.normalize(), quoteLeg.normalize()
+	if t.baseInverted {
+		b = b.invert()
+	}
+	if t.quoteInverted {
+		q = q.invert()
+	}
+	// Selling the base for Via at its bid and buying the quote currency at its ask
+	return Price{Bid: b.Bid / q.Ask, Ask: b.Ask / q.Bid, Mid: b.Mid / q.Mid}
+}
+
+// Deviation compares a quoted cross with its synthetic price
+type Deviation struct {
+	Cross     string
+	Via       string
+	Time      time.Time
+	Quoted    Price
+	Synthetic Price
+	Pips      float64 // Quoted mid minus synthetic mid, in pips of the cross
+	Arbitrage bool    // Quoted bid above the synthetic ask, or quoted ask below the synthetic bid
+}
+
+// Compare computes the deviation of a quoted cross from its legs at t
+func (t Triangle) Compare(cross, baseLeg, quoteLeg Price, at time.Time) Deviation {
+	quoted := cross.normalize()
+	synthetic := t.Synthetic(baseLeg, quoteLeg)
+	return Deviation{
+		Cross:     t.Cross,
+		Via:       t.Via,
+		Time:      at,
+		Quoted:    quoted,
+		Synthetic: synthetic,
+		Pips:      (quoted.Mid - synthetic.Mid) / candle.PipSize(t.Cross),
+		Arbitrage: quoted.Bid > synthetic.Ask || quoted.Ask < synthetic.Bid,
+	}
+}
+
+// triangles builds the triangles for the crosses
+func triangles(crosses []string, via string) ([]Triangle, error) {
+	list := make([]Triangle, 0, len(crosses))
+	for _, cross := range crosses {
+		t, err := NewTriangle(cross, via)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	return list, nil
+}
+
+// Symbols returns every pair needed to check the crosses through via
+func Symbols(crosses []string, via string) ([]string, error) {
+	list, err := triangles(crosses, via)
+	if err != nil {
+		return nil, err
+	}
+	return symbolsOf(list), nil
+}
+
+func symbolsOf(list []Triangle) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, t := range list {
+		for _, s := range t.Symbols() {
+			if !seen[s] {
+				seen[s] = true
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+// Check fetches the crosses and their legs with one live rates request and
+// returns each cross's deviation, largest first. An empty via means USD.
+func Check(client *tradermade.RESTClient, crosses []string, via string) ([]Deviation, error) {
+	list, err := triangles(crosses, via)
+	if err != nil {
+		return nil, err
+	}
+	rates, err := client.GetLiveRates(symbolsOf(list))
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make(map[string]Price, len(rates.Quotes))
+	for _, q := range rates.Quotes {
+		symbol := q.Instrument
+		if symbol == "" {
+			symbol = q.BaseCurrency + q.QuoteCurrency
+		}
+		prices[symbol] = Price{Bid: q.Bid, Ask: q.Ask, Mid: q.Mid}
+	}
+	at := time.Now()
+	if rates.Timestamp > 0 {
+		at = time.Unix(rates.Timestamp, 0)
+	}
+
+	deviations := make([]Deviation, 0, len(list))
+	for _, t := range list {
+		for _, s := range t.Symbols() {
+			if prices[s].normalize().Mid <= 0 {
+				return nil, fmt.Errorf("no rate for %s", s)
+			}
+		}
+		deviations = append(deviations, t.Compare(prices[t.Cross], prices[t.BaseLeg], prices[t.QuoteLeg], at))
+	}
+	sortDeviations(deviations)
+	return deviations, nil
+}
+
+// sortDeviations orders by absolute deviation, largest first
+func sortDeviations(d []Deviation) {
+	sort.SliceStable(d, func(i, j int) bool {
+		return abs(d[i].Pips) > abs(d[j].Pips)
+	})
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// Checker checks crosses continuously from streamed quotes. Every tick of a
+// cross or one of its legs recomputes that cross once all three have a price.
+type Checker struct {
+	Triangles   []Triangle
+	Threshold   float64         // Minimum absolute deviation in pips passed to OnDeviation
+	OnDeviation func(Deviation) // Called when a deviation reaches Threshold or is an arbitrage
+
+	mu     sync.Mutex
+	prices map[string]Price
+	last   map[string]Deviation
+}
+
+// NewChecker creates a checker for the crosses through via. An empty via
+// means USD. Subscribe the stream to the checker's Symbols.
+func NewChecker(via string, crosses ...string) (*Checker, error) {
+	list, err := triangles(crosses, via)
+	if err != nil {
+		return nil, err
+	}
+	return &Checker{
+		Triangles: list,
+		prices:    make(map[string]Price),
+		last:      make(map[string]Deviation),
+	}, nil
+}
+
+// SetThreshold sets the minimum deviation in pips reported to OnDeviation
+func (c *Checker) SetThreshold(pips float64) {
+	c.Threshold = pips
+}
+
+// SetDeviationHandler sets the callback function for deviations
+func (c *Checker) SetDeviationHandler(handler func(Deviation)) {
+	c.OnDeviation = handler
+}
+
+// Symbols returns every pair the checker needs
+func (c *Checker) Symbols() []string {
+	return symbolsOf(c.Triangles)
+}
+
+// HandleQuote matches the WebSocket client's message handler signature
+func (c *Checker) HandleQuote(quote tradermadews.QuoteMessage, _ string) {
+	c.AddQuote(quote)
+}
+
+// AddQuote updates a pair's price and rechecks every cross it belongs to
+func (c *Checker) AddQuote(quote tradermadews.QuoteMessage) {
+	t := quote.Time
+	if t.IsZero() {
+		t, _ = quote.Timestamp()
+	}
+
+	var report []Deviation
+	c.mu.Lock()
+	c.prices[quote.Symbol] = Price{Bid: quote.Bid, Ask: quote.Ask, Mid: quote.Mid}
+	for _, tri := range c.Triangles {
+		if quote.Symbol != tri.Cross && quote.Symbol != tri.BaseLeg && quote.Symbol != tri.QuoteLeg {
+			continue
+		}
+		cross, ok1 := c.prices[tri.Cross]
+		base, ok2 := c.prices[tri.BaseLeg]
+		quoteLeg, ok3 := c.prices[tri.QuoteLeg]
+		if !ok1 || !ok2 || !ok3 {
+			continue // Waiting for the remaining pairs
+		}
+		d := tri.Compare(cross, base, quoteLeg, t)
+		c.last[tri.Cross] = d
+		if d.Arbitrage || abs(d.Pips) >= c.Threshold {
+			report = append(report, d)
+		}
+	}
+	c.mu.Unlock()
+
+	if c.OnDeviation != nil {
+		for _, d := range report {
+			c.OnDeviation(d)
+		}
+	}
+}
+
+// Deviations returns the latest deviation of every cross with a price on all
+// three pairs, largest first
+func (c *Checker) Deviations() []Deviation {
+	c.mu.Lock()
+	out := make([]Deviation, 0, len(c.last))
+	for _, d := range c.last {
+		out = append(out, d)
+	}
+	c.mu.Unlock()
+	sortDeviations(out)
+	return out
+}
@@ -0,0 +1,125 @@
+// Package currency provides ISO 4217 metadata for the currencies and metals
+// TraderMade quotes, and turns instrument codes into human-readable names.
+package currency
+
+import (
+	"strings"
+
+	"github.com/tradermade/Go-SDK/internal/fuzzy"
+)
+
+// Currency is ISO 4217 metadata for one currency or metal
+type Currency struct {
+	Code       string // Alphabetic code, e.g. "EUR"
+	Numeric    int    // Numeric code, zero for codes outside ISO 4217 such as CNH
+	Name       string // English name, e.g. "Euro"
+	MinorUnits int    // Decimal places of the minor unit, -1 when not applicable (metals)
+	Symbol     string // Typical symbol, e.g. "€", or the code when there is none
+}
+
+// Lookup returns the metadata for an alphabetic code, ignoring case and
+// surrounding spaces
+func Lookup(code string) (Currency, bool) {
+	c, ok := currencies[strings.ToUpper(strings.TrimSpace(code))]
+	return c, ok
+}
+
+// All returns the metadata of every known currency, ordered by code
+func All() []Currency {
+	all := make([]Currency, 0, len(codes))
+	for _, code := range codes {
+		all = append(all, currencies[code])
+	}
+	return all
+}
+
+// Round rounds amount to the currency's minor units, e.g. 2 decimals for
+// EUR, none for JPY and 3 for BHD, rounding halves away from zero. Amounts in
+// unknown currencies and metals are returned unchanged. See RoundWith for
+// other rounding modes.
+func Round(amount float64, code string) float64 {
+	return RoundWith(amount, code, HalfAwayFromZero)
+}
+
+// Instruments names TraderMade instruments that are not currency pairs. It
+// can be extended with further CFD codes.
+var Instruments = map[string]string{
+	"UK100":  "FTSE 100",
+	"SPX500": "S&P 500",
+	"NAS100": "Nasdaq 100",
+	"US30":   "Dow Jones 30",
+	"GER30":  "DAX 30",
+	"FRA40":  "CAC 40",
+	"JPN225": "Nikkei 225",
+	"AUS200": "ASX 200",
+	"HKG33":  "Hang Seng",
+	"USOIL":  "WTI Crude Oil",
+	"UKOIL":  "Brent Crude Oil",
+	"NATGAS": "Natural Gas",
+}
+
+// Cryptos names the crypto currencies TraderMade quotes against USD. It can be
+// extended with further codes.
+var Cryptos = map[string]string{
+	"BTC": "Bitcoin",
+	"ETH": "Ethereum",
+	"LTC": "Litecoin",
+	"XRP": "Ripple",
+	"BCH": "Bitcoin Cash",
+	"ADA": "Cardano",
+	"SOL": "Solana",
+	"DOT": "Polkadot",
+	"XLM": "Stellar",
+	"EOS": "EOS",
+}
+
+// SplitPair splits a six letter pair such as "EURUSD" into its base and
+// quote codes. It reports false if either code is unknown.
+func SplitPair(symbol string) (base, quote string, ok bool) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if len(symbol) != 6 {
+		return "", "", false
+	}
+	base, quote = symbol[:3], symbol[3:]
+	_, baseOK := currencies[base]
+	_, quoteOK := currencies[quote]
+	if !baseOK || !quoteOK {
+		return "", "", false
+	}
+	return base, quote, true
+}
+
+// Name returns a human-readable name for a TraderMade instrument code, e.g.
+// "Euro / US Dollar" for "EURUSD" or "FTSE 100" for "UK100". It reports false
+// for unknown codes.
+func Name(symbol string) (string, bool) {
+	if name, ok := Instruments[strings.ToUpper(strings.TrimSpace(symbol))]; ok {
+		return name, true
+	}
+	base, quote, ok := SplitPair(symbol)
+	if !ok {
+		return "", false
+	}
+	return currencies[base].Name + " / " + currencies[quote].Name, true
+}
+
+// Suggest returns up to three known codes close to code: the code itself
+// when only case or spaces differ, otherwise codes one edit or one swap of
+// adjacent letters away, such as "GBP" for "GPB"
+func Suggest(code string) []string {
+	normalized := strings.ToUpper(strings.TrimSpace(code))
+	if _, ok := currencies[normalized]; ok {
+		return []string{normalized}
+	}
+
+	var suggestions []string
+	for _, known := range codes {
+		if fuzzy.Distance(normalized, known) == 1 {
+			suggestions = append(suggestions, known)
+			if len(suggestions) == 3 {
+				break
+			}
+		}
+	}
+	return suggestions
+}
@@ -0,0 +1,117 @@
+package currency
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Locale describes how amounts are written in a region
+type Locale struct {
+	Decimal     string // Decimal separator
+	Group       string // Thousands separator
+	Indian      bool   // Group by two digits after the first three, as in 12,34,567.00
+	SymbolAfter bool   // Write the symbol after the amount
+	SymbolSpace bool   // Separate the symbol from the amount with a space
+}
+
+// Locales holds the formats known to Format, by BCP 47 tag. It can be
+// extended with further locales.
+var Locales = map[string]Locale{
+	"en-US": {Decimal: ".", Group: ","},
+	"en-GB": {Decimal: ".", Group: ","},
+	"en-IN": {Decimal: ".", Group: ",", Indian: true},
+	"ja-JP": {Decimal: ".", Group: ","},
+	"zh-CN": {Decimal: ".", Group: ","},
+	"de-DE": {Decimal: ",", Group: ".", SymbolAfter: true, SymbolSpace: true},
+	"es-ES": {Decimal: ",", Group: ".", SymbolAfter: true, SymbolSpace: true},
+	"it-IT": {Decimal: ",", Group: ".", SymbolAfter: true, SymbolSpace: true},
+	"nl-NL": {Decimal: ",", Group: ".", SymbolSpace: true},
+	"fr-FR": {Decimal: ",", Group: " ", SymbolAfter: true, SymbolSpace: true},
+	"de-CH": {Decimal: ".", Group: "’", SymbolSpace: true},
+	"pt-BR": {Decimal: ",", Group: ".", SymbolSpace: true},
+}
+
+// DefaultLocale is used for tags missing from Locales
+const DefaultLocale = "en-US"
+
+// Format writes amount in the currency for a locale, rounded to the
+// currency's minor units, e.g. "$1,234.56" for en-US or "1.234,56 €" for
+// de-DE. Unknown locales use DefaultLocale; metals and unknown currencies are
+// written with their code and up to 4 decimals.
+func Format(amount float64, code, locale string) string {
+	loc, ok := Locales[locale]
+	if !ok {
+		loc = Locales[DefaultLocale]
+	}
+
+	places := 4
+	symbol := strings.ToUpper(strings.TrimSpace(code))
+	c, known := Lookup(code)
+	if known && c.MinorUnits >= 0 {
+		places = c.MinorUnits
+		amount = Round(amount, code)
+		symbol = c.Symbol
+	}
+	if isWord(symbol) {
+		// Codes and letter symbols such as "CHF" or "kr" need a space, "$" and "A$" do not
+		loc.SymbolSpace = true
+	}
+
+	number := strconv.FormatFloat(math.Abs(amount), 'f', places, 64)
+	if !known || c.MinorUnits < 0 {
+		number = strings.TrimRight(strings.TrimRight(number, "0"), ".")
+	}
+	whole, frac, _ := strings.Cut(number, ".")
+	number = group(whole, loc)
+	if frac != "" {
+		number += loc.Decimal + frac
+	}
+
+	sep := ""
+	if loc.SymbolSpace {
+		sep = " "
+	}
+	var b strings.Builder
+	if amount < 0 {
+		b.WriteByte('-')
+	}
+	if loc.SymbolAfter {
+		b.WriteString(number + sep + symbol)
+	} else {
+		b.WriteString(symbol + sep + number)
+	}
+	return b.String()
+}
+
+// group inserts the locale's thousands separators into whole
+func group(whole string, loc Locale) string {
+	if len(whole) <= 3 {
+		return whole
+	}
+	head, tail := whole[:len(whole)-3], whole[len(whole)-3:]
+	size := 3
+	if loc.Indian {
+		size = 2
+	}
+	var parts []string
+	for len(head) > size {
+		parts = append([]string{head[len(head)-size:]}, parts...)
+		head = head[:len(head)-size]
+	}
+	parts = append([]string{head}, parts...)
+	return strings.Join(append(parts, tail), loc.Group)
+}
+
+// isWord reports whether symbol is two or more letters
+func isWord(symbol string) bool {
+	n := 0
+	for _, r := range symbol {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+		n++
+	}
+	return n > 1
+}
@@ -0,0 +1,77 @@
+package currency
+
+import "sort"
+
+// currencies is keyed by alphabetic code
+var currencies = map[string]Currency{}
+
+// codes lists the keys of currencies in order
+var codes []string
+
+func init() {
+	for _, c := range table {
+		currencies[c.Code] = c
+		codes = append(codes, c.Code)
+	}
+	sort.Strings(codes)
+}
+
+var table = []Currency{
+	{"AED", 784, "UAE Dirham", 2, "AED"},
+	{"ARS", 32, "Argentine Peso", 2, "$"},
+	{"AUD", 36, "Australian Dollar", 2, "A$"},
+	{"BGN", 975, "Bulgarian Lev", 2, "лв"},
+	{"BHD", 48, "Bahraini Dinar", 3, "BD"},
+	{"BRL", 986, "Brazilian Real", 2, "R$"},
+	{"CAD", 124, "Canadian Dollar", 2, "C$"},
+	{"CHF", 756, "Swiss Franc", 2, "CHF"},
+	{"CLP", 152, "Chilean Peso", 0, "$"},
+	{"CNH", 0, "Chinese Yuan (Offshore)", 2, "¥"},
+	{"CNY", 156, "Chinese Yuan", 2, "¥"},
+	{"COP", 170, "Colombian Peso", 2, "$"},
+	{"CZK", 203, "Czech Koruna", 2, "Kč"},
+	{"DKK", 208, "Danish Krone", 2, "kr"},
+	{"EGP", 818, "Egyptian Pound", 2, "E£"},
+	{"EUR", 978, "Euro", 2, "€"},
+	{"GBP", 826, "British Pound", 2, "£"},
+	{"HKD", 344, "Hong Kong Dollar", 2, "HK$"},
+	{"HUF", 348, "Hungarian Forint", 2, "Ft"},
+	{"IDR", 360, "Indonesian Rupiah", 2, "Rp"},
+	{"ILS", 376, "Israeli New Shekel", 2, "₪"},
+	{"INR", 356, "Indian Rupee", 2, "₹"},
+	{"ISK", 352, "Icelandic Krona", 0, "kr"},
+	{"JOD", 400, "Jordanian Dinar", 3, "JD"},
+	{"JPY", 392, "Japanese Yen", 0, "¥"},
+	{"KES", 404, "Kenyan Shilling", 2, "KSh"},
+	{"KRW", 410, "South Korean Won", 0, "₩"},
+	{"KWD", 414, "Kuwaiti Dinar", 3, "KD"},
+	{"MAD", 504, "Moroccan Dirham", 2, "MAD"},
+	{"MXN", 484, "Mexican Peso", 2, "$"},
+	{"MYR", 458, "Malaysian Ringgit", 2, "RM"},
+	{"NGN", 566, "Nigerian Naira", 2, "₦"},
+	{"NOK", 578, "Norwegian Krone", 2, "kr"},
+	{"NZD", 554, "New Zealand Dollar", 2, "NZ$"},
+	{"OMR", 512, "Omani Rial", 3, "OMR"},
+	{"PEN", 604, "Peruvian Sol", 2, "S/"},
+	{"PHP", 608, "Philippine Peso", 2, "₱"},
+	{"PKR", 586, "Pakistani Rupee", 2, "₨"},
+	{"PLN", 985, "Polish Zloty", 2, "zł"},
+	{"QAR", 634, "Qatari Riyal", 2, "QAR"},
+	{"RON", 946, "Romanian Leu", 2, "lei"},
+	{"RUB", 643, "Russian Ruble", 2, "₽"},
+	{"SAR", 682, "Saudi Riyal", 2, "SAR"},
+	{"SEK", 752, "Swedish Krona", 2, "kr"},
+	{"SGD", 702, "Singapore Dollar", 2, "S$"},
+	{"THB", 764, "Thai Baht", 2, "฿"},
+	{"TND", 788, "Tunisian Dinar", 3, "DT"},
+	{"TRY", 949, "Turkish Lira", 2, "₺"},
+	{"TWD", 901, "New Taiwan Dollar", 2, "NT$"},
+	{"UAH", 980, "Ukrainian Hryvnia", 2, "₴"},
+	{"USD", 840, "US Dollar", 2, "$"},
+	{"VND", 704, "Vietnamese Dong", 0, "₫"},
+	{"XAG", 961, "Silver", -1, "XAG"},
+	{"XAU", 959, "Gold", -1, "XAU"},
+	{"XPD", 964, "Palladium", -1, "XPD"},
+	{"XPT", 962, "Platinum", -1, "XPT"},
+	{"ZAR", 710, "South African Rand", 2, "R"},
+}
@@ -0,0 +1,96 @@
+package currency
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RoundingMode decides how amounts halfway between two minor units round
+type RoundingMode int
+
+const (
+	HalfAwayFromZero RoundingMode = iota // 0.125 → 0.13, -0.125 → -0.13, the usual commercial rounding
+	HalfEven                             // 0.125 → 0.12, 0.135 → 0.14, banker's rounding
+	TowardZero                           // 0.129 → 0.12, truncation
+)
+
+// RoundWith rounds amount to the currency's minor units with the given mode.
+// Rounding works on the shortest decimal form of amount, so 1.005 EUR rounds
+// to 1.01 despite being stored as 1.00499... Amounts in unknown currencies and
+// metals are returned unchanged.
+func RoundWith(amount float64, code string, mode RoundingMode) float64 {
+	c, ok := Lookup(code)
+	if !ok || c.MinorUnits < 0 {
+		return amount
+	}
+	return roundDecimal(amount, c.MinorUnits, mode)
+}
+
+// ToMinor converts amount to an integer count of the currency's minor units,
+// e.g. cents, rounding half away from zero. Metals and unknown currencies have
+// no minor unit and report false.
+func ToMinor(amount float64, code string) (int64, bool) {
+	c, ok := Lookup(code)
+	if !ok || c.MinorUnits < 0 {
+		return 0, false
+	}
+	rounded := roundDecimal(amount, c.MinorUnits, HalfAwayFromZero)
+	return int64(math.Round(rounded * math.Pow10(c.MinorUnits))), true
+}
+
+// FromMinor converts a count of minor units back to an amount
+func FromMinor(minor int64, code string) (float64, bool) {
+	c, ok := Lookup(code)
+	if !ok || c.MinorUnits < 0 {
+		return 0, false
+	}
+	return float64(minor) / math.Pow10(c.MinorUnits), true
+}
+
+// roundDecimal rounds amount to places decimals on its shortest decimal form
+func roundDecimal(amount float64, places int, mode RoundingMode) float64 {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return amount
+	}
+	negative := amount < 0
+	digits := strconv.FormatFloat(math.Abs(amount), 'f', -1, 64)
+	whole, frac, _ := strings.Cut(digits, ".")
+	if len(frac) <= places {
+		return amount
+	}
+
+	kept := whole + frac[:places]
+	rest := frac[places:]
+	roundUp := false
+	switch mode {
+	case HalfAwayFromZero:
+		roundUp = rest[0] >= '5'
+	case HalfEven:
+		switch {
+		case rest[0] > '5':
+			roundUp = true
+		case rest[0] == '5':
+			if strings.TrimRight(rest[1:], "0") != "" {
+				roundUp = true // Above half
+			} else {
+				roundUp = (kept[len(kept)-1]-'0')%2 == 1
+			}
+		}
+	}
+
+	n, err := strconv.ParseInt(kept, 10, 64)
+	if err != nil {
+		// Too many digits for an int64, far beyond float64 precision anyway
+		scale := math.Pow10(places)
+		return math.Round(amount*scale) / scale
+	}
+	if roundUp {
+		n++
+	}
+	result := float64(n) / math.Pow10(places)
+	if negative {
+		result = -result
+	}
+	return result
+}
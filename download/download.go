@@ -0,0 +1,157 @@
+// Package download fetches long timeseries histories for many pairs in
+// windows, checkpointing each completed window so an interrupted job resumes
+// where it stopped instead of downloading (and paying for) the same data again.
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tradermade "github.com/tradermade/Go-SDK/rest"
+	"github.com/tradermade/Go-SDK/sinks"
+)
+
+// Progress reports one completed window
+type Progress struct {
+	Pair    string
+	From    time.Time // Start of the window
+	To      time.Time // End of the window, now checkpointed
+	Candles int       // Candles written from the window
+	Resumed bool      // The pair started from a checkpoint rather than Start
+}
+
+// Job downloads the candles of several pairs between Start and End
+type Job struct {
+	Name     string // Prefix for checkpoint keys, so jobs can share a store
+	Client   *tradermade.RESTClient
+	Pairs    []string
+	Start    time.Time
+	End      time.Time
+	Interval string        // "daily", "hourly" or "minute"
+	Period   int           // Bar length in hours or minutes for hourly and minute data (default 1)
+	Window   time.Duration // Range per request (default MaxTimeSeriesRange for the interval)
+	Store    Store         // Checkpoints, nil keeps them in memory for this run only
+	Sink     sinks.Sink    // Receives the candles and is flushed before each checkpoint
+
+	OnProgress func(Progress) // Called after every checkpointed window
+}
+
+// Key returns the checkpoint key for a pair
+func (j *Job) Key(pair string) string {
+	key := fmt.Sprintf("%s/%s/%d", pair, strings.ToLower(j.Interval), max(j.Period, 1))
+	if j.Name != "" {
+		key = j.Name + "/" + key
+	}
+	return key
+}
+
+// Run downloads every pair in turn. A failing pair does not stop the others;
+// their errors are joined. Cancelling ctx stops after the current window,
+// which stays unrecorded and is fetched again on the next run.
+func (j *Job) Run(ctx context.Context) error {
+	if j.Client == nil || j.Sink == nil {
+		return fmt.Errorf("download job needs a client and a sink")
+	}
+	if j.End.Before(j.Start) {
+		return &tradermade.RangeError{Interval: strings.ToLower(j.Interval), Start: j.Start, End: j.End}
+	}
+	if j.Store == nil {
+		j.Store = NewMemoryStore()
+	}
+
+	var errs []error
+	for _, pair := range j.Pairs {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if err := j.runPair(ctx, pair); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", pair, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runPair downloads one pair from its checkpoint to End
+func (j *Job) runPair(ctx context.Context, pair string) error {
+	key := j.Key(pair)
+	from := j.Start
+	done, resumed, err := j.Store.Load(key)
+	if err != nil {
+		return err
+	}
+	if resumed {
+		if !done.Before(j.End) {
+			return nil // Already complete
+		}
+		if done.After(from) {
+			from = done
+		}
+	}
+
+	window := j.Window
+	if window <= 0 {
+		window = tradermade.MaxTimeSeriesRange[strings.ToLower(j.Interval)]
+	}
+	bar := barLength(j.Interval, j.Period)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		to := j.End
+		if window > 0 && from.Add(window).Before(to) {
+			to = from.Add(window)
+		}
+
+		series, err := j.Client.GetTimeSeriesDataRange(pair, from, to, j.Interval, max(j.Period, 1))
+		if err != nil {
+			return fmt.Errorf("failed to fetch %s to %s: %w", from.Format(time.RFC3339), to.Format(time.RFC3339), err)
+		}
+		candles, err := series.Candles(bar)
+		if err != nil {
+			return err
+		}
+		written := 0
+		for _, c := range candles {
+			c.Symbol = pair
+			// Windows share their boundary bar, which the previous window already wrote
+			if (resumed || from.After(j.Start)) && !c.Time.After(from) {
+				continue
+			}
+			if err := j.Sink.WriteCandle(c); err != nil {
+				return err
+			}
+			written++
+		}
+		if err := j.Sink.Flush(); err != nil {
+			return fmt.Errorf("failed to flush sink: %w", err)
+		}
+		if err := j.Store.Save(key, to); err != nil {
+			return err
+		}
+		if j.OnProgress != nil {
+			j.OnProgress(Progress{Pair: pair, From: from, To: to, Candles: written, Resumed: resumed})
+		}
+
+		if !to.Before(j.End) {
+			return nil
+		}
+		from = to
+	}
+}
+
+// barLength returns the duration of one bar of the interval
+func barLength(interval string, period int) time.Duration {
+	period = max(period, 1)
+	switch strings.ToLower(interval) {
+	case "hourly":
+		return time.Duration(period) * time.Hour
+	case "minute":
+		return time.Duration(period) * time.Minute
+	}
+	return 24 * time.Hour
+}
@@ -0,0 +1,133 @@
+package download
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Store persists the end of the last completed window per key, so an
+// interrupted download resumes after it
+type Store interface {
+	Load(key string) (time.Time, bool, error)
+	Save(key string, done time.Time) error
+}
+
+// MemoryStore keeps checkpoints in memory, for tests and single-process retries
+type MemoryStore struct {
+	mu   sync.Mutex
+	done map[string]time.Time
+}
+
+// NewMemoryStore creates an empty memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{done: make(map[string]time.Time)}
+}
+
+// Load returns the checkpoint for key
+func (s *MemoryStore) Load(key string) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.done[key]
+	return t, ok, nil
+}
+
+// Save records the checkpoint for key
+func (s *MemoryStore) Save(key string, done time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done[key] = done
+	return nil
+}
+
+// FileStore keeps checkpoints in a small JSON file. Every Save rewrites the
+// file through a temporary file and rename, so a crash never leaves it half
+// written.
+type FileStore struct {
+	Path string
+
+	mu   sync.Mutex
+	done map[string]time.Time // Loaded on first use
+}
+
+// NewFileStore creates a store backed by the file at path, which is created
+// on the first Save
+func NewFileStore(path string) *FileStore {
+	return &FileStore{Path: path}
+}
+
+// Load returns the checkpoint for key
+func (s *FileStore) Load(key string) (time.Time, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.read(); err != nil {
+		return time.Time{}, false, err
+	}
+	t, ok := s.done[key]
+	return t, ok, nil
+}
+
+// Save records the checkpoint for key and writes the file
+func (s *FileStore) Save(key string, done time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.read(); err != nil {
+		return err
+	}
+	s.done[key] = done
+
+	data, err := json.MarshalIndent(s.done, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoints: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoints: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.Path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write checkpoints: %w", err)
+	}
+	return nil
+}
+
+// Reset removes the checkpoint file so the next run starts from scratch
+func (s *FileStore) Reset() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = nil
+	if err := os.Remove(s.Path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove checkpoints: %w", err)
+	}
+	return nil
+}
+
+// read loads the file once. Must be called with mu held.
+func (s *FileStore) read() error {
+	if s.done != nil {
+		return nil
+	}
+	s.done = make(map[string]time.Time)
+	data, err := os.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read checkpoints: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.done); err != nil {
+		s.done = nil
+		return fmt.Errorf("failed to parse checkpoints in %s: %w", s.Path, err)
+	}
+	return nil
+}
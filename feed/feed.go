@@ -0,0 +1,21 @@
+package feed
+
+import (
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// MarketDataFeed is the common interface of live, polled and recorded quote
+// sources, so application code can swap between them without changes
+type MarketDataFeed interface {
+	Connect() error                           // Starts delivering quotes
+	Subscribe(symbols ...string) error        // Adds symbols to the feed
+	Quotes() <-chan tradermadews.QuoteMessage // Receives every quote
+	Close() error                             // Stops the feed
+}
+
+var (
+	_ MarketDataFeed = (*tradermadews.WebSocketClient)(nil)
+	_ MarketDataFeed = (*tradermadews.ShardedClient)(nil)
+	_ MarketDataFeed = (*tradermadews.Replayer)(nil)
+	_ MarketDataFeed = (*Poller)(nil)
+)
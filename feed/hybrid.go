@@ -0,0 +1,225 @@
+package feed
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tradermade/Go-SDK/clock"
+	tradermade "github.com/tradermade/Go-SDK/rest"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Transport identifies where a quote came from
+type Transport string
+
+const (
+	TransportWebSocket Transport = "websocket"
+	TransportREST      Transport = "rest"
+)
+
+// Quality describes how fresh a quote is expected to be
+type Quality string
+
+const (
+	QualityRealtime Quality = "realtime" // Streamed tick
+	QualityPolled   Quality = "polled"   // REST snapshot, up to one poll interval old
+)
+
+// HybridQuote is a quote annotated with the transport that delivered it
+type HybridQuote struct {
+	tradermadews.QuoteMessage
+	Transport Transport
+	Quality   Quality
+}
+
+// Hybrid streams over the WebSocket while it is healthy and transparently falls
+// back to REST polling while it is down, switching back once ticks resume
+type Hybrid struct {
+	WS     *tradermadews.WebSocketClient
+	Poller *Poller
+
+	// StaleAfter is how long the WebSocket may go without a quote before the
+	// feed falls back to polling (default 15s). Set it above the quietest
+	// expected gap between ticks for the subscribed symbols.
+	StaleAfter       time.Duration
+	MessageHandler   func(HybridQuote) // Handles every quote with its annotations
+	TransportHandler func(Transport)   // Called whenever the active transport changes
+	Clock            clock.Clock       // Times staleness checks and reconnects, nil uses the system clock
+
+	mu        sync.Mutex
+	transport Transport
+	lastWS    time.Time
+	quotes    chan HybridQuote
+	stop      chan struct{}
+	done      chan struct{}
+	running   bool
+}
+
+// NewHybrid creates a hybrid feed from a WebSocket client and a REST client.
+// The symbols are polled at pollInterval while the WebSocket is down.
+func NewHybrid(ws *tradermadews.WebSocketClient, rest *tradermade.RESTClient, symbols []string, pollInterval time.Duration) *Hybrid {
+	h := &Hybrid{
+		WS:         ws,
+		Poller:     NewPoller(rest, symbols, pollInterval),
+		StaleAfter: 15 * time.Second,
+		transport:  TransportWebSocket,
+		quotes:     make(chan HybridQuote, 256),
+	}
+
+	ws.SetMessageHandler(func(quote tradermadews.QuoteMessage, _ string) {
+		h.handleWS(quote)
+	})
+	ws.SetReconnectionHandler(func(int) {
+		h.switchTo(TransportREST)
+	})
+	h.Poller.SetMessageHandler(func(quote tradermadews.QuoteMessage, _ string) {
+		h.handlePolled(quote)
+	})
+	return h
+}
+
+// SetMessageHandler sets the callback function to handle annotated quotes
+func (h *Hybrid) SetMessageHandler(handler func(HybridQuote)) {
+	h.MessageHandler = handler
+}
+
+// SetTransportHandler sets the callback function to handle transport switches
+func (h *Hybrid) SetTransportHandler(handler func(Transport)) {
+	h.TransportHandler = handler
+}
+
+// Quotes returns a channel receiving every annotated quote. Quotes are
+// dropped when the channel's buffer is full.
+func (h *Hybrid) Quotes() <-chan HybridQuote {
+	return h.quotes
+}
+
+// Transport returns the transport currently delivering quotes
+func (h *Hybrid) Transport() Transport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.transport
+}
+
+// Connect connects the WebSocket, falling back to polling straight away if it fails
+func (h *Hybrid) Connect() error {
+	h.mu.Lock()
+	if h.running {
+		h.mu.Unlock()
+		return nil
+	}
+	h.running = true
+	h.lastWS = clock.OrDefault(h.Clock).Now()
+	h.stop = make(chan struct{})
+	h.done = make(chan struct{})
+	h.mu.Unlock()
+
+	if err := h.WS.Connect(); err != nil {
+		h.switchTo(TransportREST)
+	}
+	go h.watch()
+	return nil
+}
+
+// Disconnect closes the WebSocket and stops polling
+func (h *Hybrid) Disconnect() error {
+	h.mu.Lock()
+	if !h.running {
+		h.mu.Unlock()
+		return nil
+	}
+	h.running = false
+	close(h.stop)
+	done := h.done
+	h.mu.Unlock()
+
+	<-done
+	h.Poller.Disconnect()
+	return h.WS.Disconnect()
+}
+
+// watch falls back to polling when the WebSocket goes quiet and keeps trying
+// to reconnect it while polling
+func (h *Hybrid) watch() {
+	defer close(h.done)
+
+	clk := clock.OrDefault(h.Clock)
+	ticker := clk.NewTicker(time.Second)
+	defer ticker.Stop()
+	lastAttempt := clk.Now()
+	for {
+		select {
+		case <-ticker.C():
+		case <-h.stop:
+			return
+		}
+
+		h.mu.Lock()
+		stale := clk.Now().Sub(h.lastWS) > h.StaleAfter
+		transport := h.transport
+		h.mu.Unlock()
+
+		if transport == TransportWebSocket && stale {
+			h.switchTo(TransportREST)
+			continue
+		}
+		if transport == TransportREST {
+			h.Poller.Connect() // No-op while polling; restarts it if a stale Disconnect raced the switch
+			if clk.Now().Sub(lastAttempt) >= h.WS.RetryInterval {
+				lastAttempt = clk.Now()
+				h.WS.Connect() // No-op while the client already holds a connection
+			}
+		}
+	}
+}
+
+func (h *Hybrid) handleWS(quote tradermadews.QuoteMessage) {
+	h.mu.Lock()
+	h.lastWS = clock.OrDefault(h.Clock).Now()
+	h.mu.Unlock()
+
+	h.switchTo(TransportWebSocket)
+	h.emit(HybridQuote{QuoteMessage: quote, Transport: TransportWebSocket, Quality: QualityRealtime})
+}
+
+func (h *Hybrid) handlePolled(quote tradermadews.QuoteMessage) {
+	if h.Transport() != TransportREST {
+		return // A poll that was in flight when the WebSocket recovered
+	}
+	h.emit(HybridQuote{QuoteMessage: quote, Transport: TransportREST, Quality: QualityPolled})
+}
+
+// switchTo changes the active transport, starting or stopping the poller
+func (h *Hybrid) switchTo(transport Transport) {
+	h.mu.Lock()
+	if h.transport == transport || !h.running {
+		h.mu.Unlock()
+		return
+	}
+	h.transport = transport
+	h.mu.Unlock()
+
+	if transport == TransportREST {
+		if err := h.Poller.Connect(); err != nil {
+			fmt.Printf("Failed to start REST fallback: %v\n", err)
+		}
+	} else {
+		// Disconnect waits for an in-flight poll, so don't block the read pump on it
+		go h.Poller.Disconnect()
+	}
+
+	if h.TransportHandler != nil {
+		h.TransportHandler(transport)
+	}
+}
+
+func (h *Hybrid) emit(quote HybridQuote) {
+	if h.MessageHandler != nil {
+		h.MessageHandler(quote)
+	}
+	select {
+	case h.quotes <- quote:
+	default:
+	}
+}
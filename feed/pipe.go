@@ -0,0 +1,95 @@
+package feed
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/tradermade/Go-SDK/candle"
+	"github.com/tradermade/Go-SDK/sinks"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Pipeline copies every quote from a feed into a set of sinks, see Pipe
+type Pipeline struct {
+	ErrorHandler func(error) // Handles failed writes, nil prints them
+
+	sinks *sinks.Registry
+	stop  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+}
+
+// Pipe starts copying quotes from f's Quotes channel into every sink until
+// the channel is closed or the pipeline is closed. It does not connect or
+// close the feed; call it before connecting so no quote is missed. Candles
+// can be added with WriteCandle, for example from a candle.Builder's handler.
+func Pipe(f MarketDataFeed, to ...sinks.Sink) *Pipeline {
+	registry := sinks.NewRegistry()
+	for i, s := range to {
+		registry.Register(fmt.Sprintf("sink %d", i), s) // Names are unique
+	}
+	p := &Pipeline{
+		sinks: registry,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go p.run(f.Quotes()) // Before the caller connects, so replays capture the channel
+	return p
+}
+
+// SetErrorHandler sets the callback function to handle failed writes
+func (p *Pipeline) SetErrorHandler(handler func(error)) {
+	p.ErrorHandler = handler
+}
+
+// WriteCandle writes a candle to every sink
+func (p *Pipeline) WriteCandle(c candle.Candle) {
+	if err := p.sinks.WriteCandle(c); err != nil {
+		p.reportError(err)
+	}
+}
+
+// Flush flushes every sink
+func (p *Pipeline) Flush() error {
+	return p.sinks.Flush()
+}
+
+// Done is closed when the pipeline stops copying quotes
+func (p *Pipeline) Done() <-chan struct{} {
+	return p.done
+}
+
+// Close stops copying quotes, then flushes and closes every sink
+func (p *Pipeline) Close() error {
+	p.once.Do(func() { close(p.stop) })
+	<-p.done
+	return p.sinks.Close()
+}
+
+func (p *Pipeline) run(quotes <-chan tradermadews.QuoteMessage) {
+	defer close(p.done)
+	for {
+		select {
+		case quote, ok := <-quotes:
+			if !ok {
+				if err := p.sinks.Flush(); err != nil {
+					p.reportError(err)
+				}
+				return
+			}
+			if err := p.sinks.WriteQuote(quote); err != nil {
+				p.reportError(err)
+			}
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+func (p *Pipeline) reportError(err error) {
+	if p.ErrorHandler != nil {
+		p.ErrorHandler(err)
+		return
+	}
+	fmt.Printf("Pipeline write failed: %v\n", err)
+}
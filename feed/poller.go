@@ -0,0 +1,233 @@
+// Package feed contains quote sources built on top of the REST and WebSocket
+// clients that share the WebSocket client's QuoteMessage shape.
+package feed
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tradermade/Go-SDK/clock"
+	tradermade "github.com/tradermade/Go-SDK/rest"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Poller periodically calls GetLiveRates and emits changed quotes, giving
+// REST-only plans a stream-like API matching the WebSocket client
+type Poller struct {
+	Client         *tradermade.RESTClient
+	Symbols        []string
+	Interval       time.Duration                           // Time between polls (default 1s)
+	MessageHandler func(tradermadews.QuoteMessage, string) // Handles quotes with a human-readable timestamp
+	ErrorHandler   func(error)                             // Handles failed polls
+	EmitUnchanged  bool                                    // Emit every polled quote, not only those whose bid/ask changed
+	MarketOpen     func(time.Time) bool                    // Skips polls while false, e.g. markethours.IsOpen; nil means always open
+	Clock          clock.Clock                             // Times polls, nil uses the system clock
+	Filters        []tradermadews.Filter                   // Applied in order to every emitted quote, see AddFilter
+
+	TimestampLayout   string         // Layout of the message handler's timestamp (default tradermadews.DefaultTimestampLayout)
+	TimestampLocation *time.Location // Time zone of the message handler's timestamp, nil means UTC
+
+	mu      sync.Mutex
+	last    map[string]tradermadews.QuoteMessage
+	quotes  chan tradermadews.QuoteMessage
+	stop    chan struct{}
+	done    chan struct{}
+	running bool
+}
+
+// NewPoller creates a poller for the given symbols
+func NewPoller(client *tradermade.RESTClient, symbols []string, interval time.Duration) *Poller {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return &Poller{
+		Client:   client,
+		Symbols:  symbols,
+		Interval: interval,
+		last:     make(map[string]tradermadews.QuoteMessage),
+		quotes:   make(chan tradermadews.QuoteMessage, 256),
+	}
+}
+
+// SetMessageHandler sets the callback function to handle polled quotes
+func (p *Poller) SetMessageHandler(handler func(tradermadews.QuoteMessage, string)) {
+	p.MessageHandler = handler
+}
+
+// SetErrorHandler sets the callback function to handle failed polls
+func (p *Poller) SetErrorHandler(handler func(error)) {
+	p.ErrorHandler = handler
+}
+
+// SetTimestampFormat sets the layout and time zone of the timestamp passed to
+// the message handler, see WebSocketClient.SetTimestampFormat
+func (p *Poller) SetTimestampFormat(layout string, loc *time.Location) {
+	p.TimestampLayout = layout
+	p.TimestampLocation = loc
+}
+
+// AddFilter appends filters to the chain applied to every quote before it is
+// emitted. Add filters before connecting.
+func (p *Poller) AddFilter(filters ...tradermadews.Filter) {
+	p.Filters = append(p.Filters, filters...)
+}
+
+// Quotes returns a channel receiving every emitted quote. Quotes are dropped
+// when the channel's buffer is full, so read it or use the message handler.
+func (p *Poller) Quotes() <-chan tradermadews.QuoteMessage {
+	return p.quotes
+}
+
+// Subscribe adds symbols to the polled set
+func (p *Poller) Subscribe(symbols ...string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	existing := make(map[string]bool, len(p.Symbols))
+	for _, s := range p.Symbols {
+		existing[s] = true
+	}
+	for _, s := range symbols {
+		if !existing[s] {
+			existing[s] = true
+			p.Symbols = append(p.Symbols, s)
+		}
+	}
+	return nil
+}
+
+// Close stops polling, see Disconnect
+func (p *Poller) Close() error {
+	return p.Disconnect()
+}
+
+// Connect starts polling in the background, beginning with an immediate poll
+func (p *Poller) Connect() error {
+	p.mu.Lock()
+	if p.running {
+		p.mu.Unlock()
+		return nil
+	}
+	if len(p.Symbols) == 0 {
+		p.mu.Unlock()
+		return fmt.Errorf("no symbols to poll")
+	}
+	p.running = true
+	p.stop = make(chan struct{})
+	p.done = make(chan struct{})
+	p.mu.Unlock()
+
+	go p.loop()
+	return nil
+}
+
+// Disconnect stops polling and waits for an in-flight poll to finish
+func (p *Poller) Disconnect() error {
+	p.mu.Lock()
+	if !p.running {
+		p.mu.Unlock()
+		return nil
+	}
+	p.running = false
+	close(p.stop)
+	done := p.done
+	p.mu.Unlock()
+
+	<-done
+	return nil
+}
+
+// Poll fetches live rates once and emits the quotes that changed
+func (p *Poller) Poll() error {
+	p.mu.Lock()
+	symbols := append([]string(nil), p.Symbols...)
+	p.mu.Unlock()
+
+	rates, err := p.Client.GetLiveRates(symbols)
+	if err != nil {
+		return err
+	}
+
+	for _, quote := range LiveRateQuotes(rates) {
+		p.mu.Lock()
+		prev, seen := p.last[quote.Symbol]
+		changed := !seen || prev.Bid != quote.Bid || prev.Ask != quote.Ask
+		p.last[quote.Symbol] = quote
+		p.mu.Unlock()
+
+		if changed || p.EmitUnchanged {
+			p.emit(quote)
+		}
+	}
+	return nil
+}
+
+func (p *Poller) loop() {
+	defer close(p.done)
+
+	clk := clock.OrDefault(p.Clock)
+	ticker := clk.NewTicker(p.Interval)
+	defer ticker.Stop()
+	for {
+		if p.MarketOpen == nil || p.MarketOpen(clk.Now()) {
+			if err := p.Poll(); err != nil {
+				p.reportError(err)
+			}
+		}
+		select {
+		case <-ticker.C():
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+func (p *Poller) emit(quote tradermadews.QuoteMessage) {
+	quote, ok := tradermadews.ApplyFilters(p.Filters, quote)
+	if !ok {
+		return
+	}
+	if p.MessageHandler != nil {
+		timestamp := ""
+		if ts, err := quote.Timestamp(); err == nil {
+			timestamp = tradermadews.FormatTimestamp(ts, p.TimestampLayout, p.TimestampLocation)
+		}
+		p.MessageHandler(quote, timestamp)
+	}
+	select {
+	case p.quotes <- quote:
+	default:
+	}
+}
+
+func (p *Poller) reportError(err error) {
+	if p.ErrorHandler != nil {
+		p.ErrorHandler(err)
+		return
+	}
+	fmt.Printf("Poll failed: %v\n", err)
+}
+
+// LiveRateQuotes converts a live rates response to WebSocket-shaped quotes
+func LiveRateQuotes(rates *tradermade.LiveRate) []tradermadews.QuoteMessage {
+	ts := strconv.FormatInt(rates.Timestamp*1000, 10)
+	t := time.Unix(rates.Timestamp, 0)
+	quotes := make([]tradermadews.QuoteMessage, 0, len(rates.Quotes))
+	for _, q := range rates.Quotes {
+		symbol := q.Instrument
+		if symbol == "" {
+			symbol = q.BaseCurrency + q.QuoteCurrency
+		}
+		quotes = append(quotes, tradermadews.QuoteMessage{
+			Symbol: symbol,
+			Bid:    q.Bid,
+			Ask:    q.Ask,
+			Mid:    q.Mid,
+			Ts:     ts,
+			Time:   t,
+		})
+	}
+	return quotes
+}
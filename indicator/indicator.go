@@ -0,0 +1,183 @@
+// Package indicator provides technical indicators that update incrementally,
+// one price at a time, in constant memory, so they can run inside message
+// handlers on every tick without buffering history. Indicators are not safe
+// for concurrent use; keep one per symbol, e.g. with PerSymbol.
+package indicator
+
+import (
+	"math"
+	"sync"
+)
+
+// Indicator is a single-input indicator such as EMA or RSI
+type Indicator interface {
+	Update(v float64) float64 // Adds a value and returns the new reading
+	Value() float64           // Returns the current reading
+	Ready() bool              // Reports whether enough values have been seen
+}
+
+var (
+	_ Indicator = (*EMA)(nil)
+	_ Indicator = (*RSI)(nil)
+)
+
+// EMA is an exponential moving average seeded with the simple average of its
+// first period values
+type EMA struct {
+	period int
+	alpha  float64
+	count  int
+	sum    float64
+	value  float64
+}
+
+// NewEMA creates an EMA over period values
+func NewEMA(period int) *EMA {
+	if period < 1 {
+		period = 1
+	}
+	return &EMA{period: period, alpha: 2 / float64(period+1)}
+}
+
+// Update adds a value and returns the new average
+func (e *EMA) Update(v float64) float64 {
+	e.count++
+	if e.count <= e.period {
+		e.sum += v
+		e.value = e.sum / float64(e.count)
+		return e.value
+	}
+	e.value += e.alpha * (v - e.value)
+	return e.value
+}
+
+// Value returns the current average
+func (e *EMA) Value() float64 {
+	return e.value
+}
+
+// Ready reports whether period values have been seen
+func (e *EMA) Ready() bool {
+	return e.count >= e.period
+}
+
+// RSI is the relative strength index with Wilder's smoothing
+type RSI struct {
+	period  int
+	count   int // Changes seen so far
+	prev    float64
+	avgGain float64
+	avgLoss float64
+}
+
+// NewRSI creates an RSI over period changes
+func NewRSI(period int) *RSI {
+	if period < 1 {
+		period = 1
+	}
+	return &RSI{period: period, count: -1}
+}
+
+// Update adds a price and returns the new RSI between 0 and 100
+func (r *RSI) Update(price float64) float64 {
+	if r.count < 0 {
+		r.prev, r.count = price, 0
+		return r.Value()
+	}
+
+	change := price - r.prev
+	r.prev = price
+	gain, loss := math.Max(change, 0), math.Max(-change, 0)
+
+	r.count++
+	if r.count <= r.period {
+		// Simple average over the first period changes
+		r.avgGain += (gain - r.avgGain) / float64(r.count)
+		r.avgLoss += (loss - r.avgLoss) / float64(r.count)
+	} else {
+		n := float64(r.period)
+		r.avgGain = (r.avgGain*(n-1) + gain) / n
+		r.avgLoss = (r.avgLoss*(n-1) + loss) / n
+	}
+	return r.Value()
+}
+
+// Value returns the current RSI, 50 before any change has been seen
+func (r *RSI) Value() float64 {
+	if r.count <= 0 {
+		return 50
+	}
+	if r.avgLoss == 0 {
+		if r.avgGain == 0 {
+			return 50
+		}
+		return 100
+	}
+	return 100 - 100/(1+r.avgGain/r.avgLoss)
+}
+
+// Ready reports whether period changes have been seen
+func (r *RSI) Ready() bool {
+	return r.count >= r.period
+}
+
+// VWAP is the volume-weighted average price. Spot FX quotes carry no volume,
+// so pass 1 per tick for a tick-weighted average, or traded size when known.
+type VWAP struct {
+	pv     float64 // Sum of price times volume
+	volume float64
+}
+
+// NewVWAP creates an empty VWAP
+func NewVWAP() *VWAP {
+	return &VWAP{}
+}
+
+// Add adds a price with its volume and returns the new average
+func (v *VWAP) Add(price, volume float64) float64 {
+	v.pv += price * volume
+	v.volume += volume
+	return v.Value()
+}
+
+// Value returns the current average, zero before any volume
+func (v *VWAP) Value() float64 {
+	if v.volume == 0 {
+		return 0
+	}
+	return v.pv / v.volume
+}
+
+// Reset starts a new session
+func (v *VWAP) Reset() {
+	v.pv, v.volume = 0, 0
+}
+
+// PerSymbol keeps one indicator per symbol, created on first use
+type PerSymbol[T any] struct {
+	mu    sync.Mutex
+	newFn func() T
+	items map[string]T
+}
+
+// NewPerSymbol creates a set of indicators built by newFn, e.g.
+//
+//	emas := indicator.NewPerSymbol(func() *indicator.EMA { return indicator.NewEMA(20) })
+//	client.SetMessageHandler(func(q tradermadews.QuoteMessage, _ string) {
+//		ema := emas.Get(q.Symbol).Update(q.Mid)
+//	})
+func NewPerSymbol[T any](newFn func() T) *PerSymbol[T] {
+	return &PerSymbol[T]{newFn: newFn, items: make(map[string]T)}
+}
+
+// Get returns the symbol's indicator, creating it if needed
+func (p *PerSymbol[T]) Get(symbol string) T {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	item, ok := p.items[symbol]
+	if !ok {
+		item = p.newFn()
+		p.items[symbol] = item
+	}
+	return item
+}
@@ -0,0 +1,49 @@
+// Package redact hides API keys in URLs, errors and debug output
+package redact
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+)
+
+// Mask replaces redacted values
+const Mask = "REDACTED"
+
+// keyParams matches the query parameters carrying API keys, including in
+// text that is not a well-formed URL
+var keyParams = regexp.MustCompile(`(?i)((?:api_key|apikey|userKey)=)[^&\s"']*`)
+
+// Key returns a form of key safe to log, keeping the last four characters of
+// long keys so they can still be told apart
+func Key(key string) string {
+	switch {
+	case key == "":
+		return ""
+	case len(key) < 12:
+		return Mask
+	default:
+		return "..." + key[len(key)-4:]
+	}
+}
+
+// URL replaces the values of key parameters in a URL or a text containing one
+func URL(s string) string {
+	return keyParams.ReplaceAllString(s, "${1}"+Mask)
+}
+
+// Error returns err with key parameters removed from its URL. The returned
+// error keeps the type of err, so net.Error and errors.As checks still work.
+func Error(err error) error {
+	var urlErr *url.Error
+	if err == nil || !errors.As(err, &urlErr) {
+		return err
+	}
+	if urlErr == err {
+		redacted := *urlErr
+		redacted.URL = URL(urlErr.URL)
+		return &redacted
+	}
+	urlErr.URL = URL(urlErr.URL) // Wrapped, so redact in place
+	return err
+}
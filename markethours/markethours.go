@@ -0,0 +1,104 @@
+// Package markethours answers whether the spot FX market is open and which
+// trading sessions are active, so pollers, watchdogs and alerts can go quiet
+// over the weekend.
+//
+// The FX week runs from Sunday 17:00 to Friday 17:00 New York time, which
+// follows US daylight saving. Public holidays are not taken into account.
+package markethours
+
+import "time"
+
+// Session is a regional trading session, defined by opening hours in its
+// local time zone
+type Session struct {
+	Name     string
+	Location *time.Location
+	Open     time.Duration // Opening time after local midnight
+	Close    time.Duration // Closing time after local midnight
+}
+
+var newYork = loadLocation("America/New_York", -5)
+
+// The four major FX sessions
+var (
+	Sydney  = Session{Name: "Sydney", Location: loadLocation("Australia/Sydney", 10), Open: 7 * time.Hour, Close: 16 * time.Hour}
+	Tokyo   = Session{Name: "Tokyo", Location: loadLocation("Asia/Tokyo", 9), Open: 9 * time.Hour, Close: 18 * time.Hour}
+	London  = Session{Name: "London", Location: loadLocation("Europe/London", 0), Open: 8 * time.Hour, Close: 17 * time.Hour}
+	NewYork = Session{Name: "New York", Location: newYork, Open: 8 * time.Hour, Close: 17 * time.Hour}
+)
+
+// Sessions lists the major sessions in the order they open each day
+var Sessions = []Session{Sydney, Tokyo, London, NewYork}
+
+// rollover is the New York time the FX week opens and closes
+const rollover = 17 * time.Hour
+
+// loadLocation loads a time zone, falling back to a fixed offset in hours
+// when the system has no time zone database
+func loadLocation(name string, offset int) *time.Location {
+	if loc, err := time.LoadLocation(name); err == nil {
+		return loc
+	}
+	return time.FixedZone(name, offset*60*60)
+}
+
+// IsOpen reports whether the FX market is open at t
+func IsOpen(t time.Time) bool {
+	local := t.In(newYork)
+	sinceMidnight := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute
+	switch local.Weekday() {
+	case time.Saturday:
+		return false
+	case time.Sunday:
+		return sinceMidnight >= rollover
+	case time.Friday:
+		return sinceMidnight < rollover
+	default:
+		return true
+	}
+}
+
+// NextOpen returns the first time after t that the market opens
+func NextOpen(t time.Time) time.Time {
+	return nextWeekly(t, time.Sunday)
+}
+
+// NextClose returns the first time after t that the market closes
+func NextClose(t time.Time) time.Time {
+	return nextWeekly(t, time.Friday)
+}
+
+// nextWeekly returns the first rollover on day after t, in t's location
+func nextWeekly(t time.Time, day time.Weekday) time.Time {
+	local := t.In(newYork)
+	offset := int(day - local.Weekday())
+	next := time.Date(local.Year(), local.Month(), local.Day()+offset, 0, 0, 0, 0, newYork).Add(rollover)
+	if !next.After(t) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+offset+7, 0, 0, 0, 0, newYork).Add(rollover)
+	}
+	return next.In(t.Location())
+}
+
+// Active reports whether the session is trading at t. Sessions trade on
+// their local weekdays while the market is open.
+func (s Session) Active(t time.Time) bool {
+	local := t.In(s.Location)
+	if !IsOpen(t) || local.Weekday() == time.Saturday || local.Weekday() == time.Sunday {
+		return false
+	}
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.Location)
+	since := local.Sub(midnight)
+	return since >= s.Open && since < s.Close
+}
+
+// ActiveSessions returns the names of the sessions trading at t, e.g.
+// ["London", "New York"] during the overlap
+func ActiveSessions(t time.Time) []string {
+	var names []string
+	for _, s := range Sessions {
+		if s.Active(t) {
+			names = append(names, s.Name)
+		}
+	}
+	return names
+}
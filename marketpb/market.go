@@ -0,0 +1,156 @@
+// Package marketpb encodes quotes, candles and ticks in the protobuf format
+// defined by market.proto, without depending on a protobuf runtime. Messages
+// produced here can be decoded by code generated from market.proto in any
+// language, and vice versa.
+package marketpb
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tradermade/Go-SDK/candle"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Tick is a single price observation for a symbol
+type Tick struct {
+	Symbol string
+	Time   time.Time
+	Price  float64
+}
+
+// EncodeQuote encodes a quote as a tradermade.market.v1.Quote. Its signature
+// matches the Kafka sink's Encoder.
+func EncodeQuote(quote tradermadews.QuoteMessage) ([]byte, error) {
+	ts, err := strconv.ParseInt(quote.Ts, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp %q for %s: %w", quote.Ts, quote.Symbol, err)
+	}
+
+	var e encoder
+	e.string(1, quote.Symbol)
+	e.double(2, quote.Bid)
+	e.double(3, quote.Ask)
+	e.double(4, quote.Mid)
+	e.int64(5, ts)
+	return e.buf, nil
+}
+
+// DecodeQuote decodes a tradermade.market.v1.Quote
+func DecodeQuote(data []byte) (tradermadews.QuoteMessage, error) {
+	var quote tradermadews.QuoteMessage
+	var ts int64
+	err := decodeFields(data, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			quote.Symbol, err = f.string()
+		case 2:
+			quote.Bid, err = f.double()
+		case 3:
+			quote.Ask, err = f.double()
+		case 4:
+			quote.Mid, err = f.double()
+		case 5:
+			ts, err = f.int64()
+		}
+		return err
+	})
+	if err != nil {
+		return tradermadews.QuoteMessage{}, fmt.Errorf("failed to decode quote: %w", err)
+	}
+	quote.Ts = strconv.FormatInt(ts, 10)
+	quote.Time = time.UnixMilli(ts)
+	return quote, nil
+}
+
+// EncodeCandle encodes a candle as a tradermade.market.v1.Candle
+func EncodeCandle(c candle.Candle) []byte {
+	var e encoder
+	e.string(1, c.Symbol)
+	e.int64(2, c.Time.UnixMilli())
+	e.int64(3, c.Interval.Milliseconds())
+	e.double(4, c.Open)
+	e.double(5, c.High)
+	e.double(6, c.Low)
+	e.double(7, c.Close)
+	e.int64(8, int64(c.Ticks))
+	e.int64(9, int64(c.Updates))
+	return e.buf
+}
+
+// DecodeCandle decodes a tradermade.market.v1.Candle. Times are in UTC.
+func DecodeCandle(data []byte) (candle.Candle, error) {
+	var c candle.Candle
+	var start, interval, ticks, updates int64
+	err := decodeFields(data, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			c.Symbol, err = f.string()
+		case 2:
+			start, err = f.int64()
+		case 3:
+			interval, err = f.int64()
+		case 4:
+			c.Open, err = f.double()
+		case 5:
+			c.High, err = f.double()
+		case 6:
+			c.Low, err = f.double()
+		case 7:
+			c.Close, err = f.double()
+		case 8:
+			ticks, err = f.int64()
+		case 9:
+			updates, err = f.int64()
+		}
+		return err
+	})
+	if err != nil {
+		return candle.Candle{}, fmt.Errorf("failed to decode candle: %w", err)
+	}
+	c.Ticks, c.Updates = int(ticks), int(updates)
+	c.Time = time.UnixMilli(start).UTC()
+	c.Interval = time.Duration(interval) * time.Millisecond
+	return c, nil
+}
+
+// EncodeTick encodes a tick as a tradermade.market.v1.Tick
+func EncodeTick(t Tick) []byte {
+	var e encoder
+	e.string(1, t.Symbol)
+	e.int64(2, t.Time.UnixMilli())
+	e.double(3, t.Price)
+	return e.buf
+}
+
+// DecodeTick decodes a tradermade.market.v1.Tick. Times are in UTC.
+func DecodeTick(data []byte) (Tick, error) {
+	var t Tick
+	var ts int64
+	err := decodeFields(data, func(f field) (err error) {
+		switch f.num {
+		case 1:
+			t.Symbol, err = f.string()
+		case 2:
+			ts, err = f.int64()
+		case 3:
+			t.Price, err = f.double()
+		}
+		return err
+	})
+	if err != nil {
+		return Tick{}, fmt.Errorf("failed to decode tick: %w", err)
+	}
+	t.Time = time.UnixMilli(ts).UTC()
+	return t, nil
+}
+
+// QuoteTick returns the mid price tick of a quote
+func QuoteTick(quote tradermadews.QuoteMessage) (Tick, error) {
+	ts, err := quote.Timestamp()
+	if err != nil {
+		return Tick{}, fmt.Errorf("invalid timestamp %q for %s: %w", quote.Ts, quote.Symbol, err)
+	}
+	return Tick{Symbol: quote.Symbol, Time: ts.UTC(), Price: quote.Mid}, nil
+}
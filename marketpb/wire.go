@@ -0,0 +1,124 @@
+package marketpb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Protobuf wire types used by the market messages
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// encoder appends fields in protobuf wire format. Zero values are omitted, as
+// proto3 requires.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) tag(field, wireType int) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(field)<<3|uint64(wireType))
+}
+
+func (e *encoder) string(field int, s string) {
+	if s == "" {
+		return
+	}
+	e.tag(field, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) double(field int, v float64) {
+	if v == 0 {
+		return
+	}
+	e.tag(field, wireFixed64)
+	e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(v))
+}
+
+func (e *encoder) int64(field int, v int64) {
+	if v == 0 {
+		return
+	}
+	e.tag(field, wireVarint)
+	e.buf = binary.AppendUvarint(e.buf, uint64(v))
+}
+
+// field is one decoded field
+type field struct {
+	num      int
+	wireType int
+	varint   uint64
+	bytes    []byte
+}
+
+func (f field) double() (float64, error) {
+	if f.wireType != wireFixed64 {
+		return 0, fmt.Errorf("field %d: expected fixed64, got wire type %d", f.num, f.wireType)
+	}
+	return math.Float64frombits(f.varint), nil
+}
+
+func (f field) int64() (int64, error) {
+	if f.wireType != wireVarint {
+		return 0, fmt.Errorf("field %d: expected varint, got wire type %d", f.num, f.wireType)
+	}
+	return int64(f.varint), nil
+}
+
+func (f field) string() (string, error) {
+	if f.wireType != wireBytes {
+		return "", fmt.Errorf("field %d: expected bytes, got wire type %d", f.num, f.wireType)
+	}
+	return string(f.bytes), nil
+}
+
+// decodeFields calls fn for every field in data. Unknown fields are passed
+// through and may be ignored by fn, as protobuf requires.
+func decodeFields(data []byte, fn func(field) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return fmt.Errorf("invalid field key")
+		}
+		data = data[n:]
+		f := field{num: int(key >> 3), wireType: int(key & 7)}
+
+		switch f.wireType {
+		case wireVarint:
+			v, n := binary.Uvarint(data)
+			if n <= 0 {
+				return fmt.Errorf("field %d: invalid varint", f.num)
+			}
+			f.varint, data = v, data[n:]
+		case wireFixed64:
+			if len(data) < 8 {
+				return fmt.Errorf("field %d: truncated fixed64", f.num)
+			}
+			f.varint, data = binary.LittleEndian.Uint64(data), data[8:]
+		case wireBytes:
+			size, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < size {
+				return fmt.Errorf("field %d: truncated bytes", f.num)
+			}
+			f.bytes, data = data[n:n+int(size)], data[n+int(size):]
+		case wireFixed32:
+			if len(data) < 4 {
+				return fmt.Errorf("field %d: truncated fixed32", f.num)
+			}
+			f.varint, data = uint64(binary.LittleEndian.Uint32(data)), data[4:]
+		default:
+			return fmt.Errorf("field %d: unsupported wire type %d", f.num, f.wireType)
+		}
+
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Package ohlc defines the OHLC bar type. It has no dependencies on the
+// SDK's clients, so the REST client can return bars without pulling in the
+// WebSocket client; package candle re-exports it as candle.Candle.
+package ohlc
+
+import (
+	"math"
+	"time"
+)
+
+// Candle is an OHLC bar for one symbol starting at Time
+type Candle struct {
+	Symbol   string
+	Time     time.Time     // Start of the bar
+	Interval time.Duration // Bar length, zero when unknown
+	Open     float64
+	High     float64
+	Low      float64
+	Close    float64
+	Ticks    int // Quotes aggregated into the bar, zero when unknown
+	Updates  int // Ticks that changed the price from the previous tick in the bar
+}
+
+// End returns the time the bar closes, or Time when the interval is unknown
+func (c Candle) End() time.Time {
+	return c.Time.Add(c.Interval)
+}
+
+// TickRate returns the average number of ticks per second over the bar, an
+// activity proxy in the absence of FX volume. It is zero when the tick count
+// or the interval is unknown.
+func (c Candle) TickRate() float64 {
+	if c.Interval <= 0 {
+		return 0
+	}
+	return float64(c.Ticks) / c.Interval.Seconds()
+}
+
+// Range returns the distance between the high and the low
+func (c Candle) Range() float64 {
+	return c.High - c.Low
+}
+
+// Body returns the distance between the open and the close
+func (c Candle) Body() float64 {
+	return math.Abs(c.Close - c.Open)
+}
+
+// IsBullish reports whether the bar closed above its open
+func (c Candle) IsBullish() bool {
+	return c.Close > c.Open
+}
+
+// IsBearish reports whether the bar closed below its open
+func (c Candle) IsBearish() bool {
+	return c.Close < c.Open
+}
+
+// Midpoint returns the price halfway between the high and the low
+func (c Candle) Midpoint() float64 {
+	return (c.High + c.Low) / 2
+}
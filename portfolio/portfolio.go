@@ -0,0 +1,216 @@
+// Package portfolio values holdings in several currencies in one target
+// currency, either once from a single batched live rates request or
+// continuously from the stream.
+package portfolio
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	tradermade "github.com/tradermade/Go-SDK/rest"
+	"github.com/tradermade/Go-SDK/symbols"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Position is an amount held in one currency
+type Position struct {
+	Currency string
+	Amount   float64
+}
+
+// PositionValue is a position converted to the target currency
+type PositionValue struct {
+	Position
+	Symbol string  // Pair used for the rate, empty for positions in the target currency
+	Rate   float64 // Target currency per unit of the position's currency
+	Value  float64 // Amount times Rate
+}
+
+// Valuation is a portfolio valued in one currency
+type Valuation struct {
+	Target    string
+	Time      time.Time
+	Positions []PositionValue
+	Total     float64
+}
+
+// leg is the pair pricing one currency in the target currency
+type leg struct {
+	symbol   string
+	inverted bool
+}
+
+// legs returns the pair for every currency other than target
+func legs(positions []Position, target string) map[string]leg {
+	needed := make(map[string]leg)
+	for _, p := range positions {
+		if p.Currency == target {
+			continue
+		}
+		symbol, inverted := symbols.Pair(p.Currency, target)
+		needed[p.Currency] = leg{symbol, inverted}
+	}
+	return needed
+}
+
+// Symbols returns the pairs needed to value the positions in target
+func Symbols(positions []Position, target string) []string {
+	var list []string
+	seen := make(map[string]bool)
+	for _, p := range positions {
+		if l, ok := legs([]Position{p}, target)[p.Currency]; ok && !seen[l.symbol] {
+			seen[l.symbol] = true
+			list = append(list, l.symbol)
+		}
+	}
+	return list
+}
+
+// value converts the positions with mid prices by symbol
+func value(positions []Position, target string, needed map[string]leg, mids map[string]float64, t time.Time) (*Valuation, error) {
+	v := &Valuation{Target: target, Time: t, Positions: make([]PositionValue, 0, len(positions))}
+	for _, p := range positions {
+		pv := PositionValue{Position: p, Rate: 1}
+		if p.Currency != target {
+			l := needed[p.Currency]
+			mid, ok := mids[l.symbol]
+			if !ok || mid <= 0 {
+				return nil, fmt.Errorf("no rate for %s", l.symbol)
+			}
+			pv.Symbol, pv.Rate = l.symbol, mid
+			if l.inverted {
+				pv.Rate = 1 / mid
+			}
+		}
+		pv.Value = p.Amount * pv.Rate
+		v.Total += pv.Value
+		v.Positions = append(v.Positions, pv)
+	}
+	return v, nil
+}
+
+// Value values the positions in target with one live rates request
+func Value(client *tradermade.RESTClient, positions []Position, target string) (*Valuation, error) {
+	needed := legs(positions, target)
+	list := Symbols(positions, target)
+	mids := make(map[string]float64, len(list))
+	t := time.Now()
+	if len(list) > 0 {
+		rates, err := client.GetLiveRates(list)
+		if err != nil {
+			return nil, err
+		}
+		for _, q := range rates.Quotes {
+			symbol := q.Instrument
+			if symbol == "" {
+				symbol = q.BaseCurrency + q.QuoteCurrency
+			}
+			mids[symbol] = q.Mid
+		}
+		if rates.Timestamp > 0 {
+			t = time.Unix(rates.Timestamp, 0)
+		}
+	}
+	return value(positions, target, needed, mids, t)
+}
+
+// Revaluer revalues positions from streamed quotes, emitting a new valuation
+// whenever a needed pair ticks once every pair has a price
+type Revaluer struct {
+	Positions []Position
+	Target    string
+	OnUpdate  func(*Valuation) // Called with every new valuation
+
+	mu     sync.Mutex
+	needed map[string]leg
+	mids   map[string]float64
+	last   *Valuation
+}
+
+// NewRevaluer creates a revaluer for the positions. Subscribe the stream to
+// Symbols(positions, target), or call Seed to start from live REST rates.
+func NewRevaluer(positions []Position, target string) *Revaluer {
+	return &Revaluer{
+		Positions: positions,
+		Target:    target,
+		needed:    legs(positions, target),
+		mids:      make(map[string]float64),
+	}
+}
+
+// SetUpdateHandler sets the callback function for new valuations
+func (r *Revaluer) SetUpdateHandler(handler func(*Valuation)) {
+	r.OnUpdate = handler
+}
+
+// Symbols returns the pairs the revaluer needs
+func (r *Revaluer) Symbols() []string {
+	return Symbols(r.Positions, r.Target)
+}
+
+// Seed fetches the current rates with one REST request, so a valuation is
+// available before every pair has ticked
+func (r *Revaluer) Seed(client *tradermade.RESTClient) error {
+	v, err := Value(client, r.Positions, r.Target)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	for _, pv := range v.Positions {
+		if pv.Symbol != "" {
+			r.mids[pv.Symbol] = pv.Rate
+			if r.needed[pv.Currency].inverted {
+				r.mids[pv.Symbol] = 1 / pv.Rate
+			}
+		}
+	}
+	r.last = v
+	r.mu.Unlock()
+	return nil
+}
+
+// HandleQuote matches the WebSocket client's message handler signature
+func (r *Revaluer) HandleQuote(quote tradermadews.QuoteMessage, _ string) {
+	r.AddQuote(quote)
+}
+
+// AddQuote updates a pair's price and revalues the positions
+func (r *Revaluer) AddQuote(quote tradermadews.QuoteMessage) {
+	t := quote.Time
+	if t.IsZero() {
+		t, _ = quote.Timestamp()
+	}
+
+	r.mu.Lock()
+	wanted := false
+	for _, l := range r.needed {
+		if l.symbol == quote.Symbol {
+			wanted = true
+			break
+		}
+	}
+	if !wanted {
+		r.mu.Unlock()
+		return
+	}
+	r.mids[quote.Symbol] = quote.Mid
+	v, err := value(r.Positions, r.Target, r.needed, r.mids, t)
+	if err != nil {
+		r.mu.Unlock()
+		return // Waiting for the remaining pairs
+	}
+	r.last = v
+	r.mu.Unlock()
+
+	if r.OnUpdate != nil {
+		r.OnUpdate(v)
+	}
+}
+
+// Valuation returns the latest valuation, or nil before every pair has a price
+func (r *Revaluer) Valuation() *Valuation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.last
+}
@@ -0,0 +1,261 @@
+// Package proxy provides an embeddable HTTP server that proxies the TraderMade
+// REST API for internal services. It adds the API key, caches responses,
+// coalesces identical in-flight requests and rate limits upstream calls, so
+// many services can share one key safely.
+//
+// Mount it anywhere; the last path element selects the endpoint:
+//
+//	srv := proxy.NewServer(proxy.Config{APIKey: "YOUR_API_KEY"})
+//	http.Handle("/api/v1/", srv)
+//	// GET /api/v1/live?currency=EURUSD,GBPUSD
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/tradermade/Go-SDK/internal/ratelimit"
+	tradermade "github.com/tradermade/Go-SDK/rest"
+)
+
+// Config holds the proxy settings
+type Config struct {
+	APIKey            string                   // Key added to every upstream request
+	Upstream          string                   // Upstream base URL (default tradermade.DefaultBaseURL)
+	DefaultTTL        time.Duration            // Cache lifetime for endpoints not in TTLs (default 1m)
+	TTLs              map[string]time.Duration // Cache lifetime per endpoint, e.g. "live": time.Second
+	MaxEntries        int                      // Maximum cached responses (default 10000)
+	RequestsPerSecond float64                  // Upstream request rate, zero disables limiting
+	Burst             int                      // Upstream burst size (default 1)
+	MaxWait           time.Duration            // How long a request may queue for the rate limiter (default 10s)
+
+	// StaleWhileRevalidate is how long after expiring an entry may still be
+	// served while a background request refreshes it, bounding how stale a
+	// response can be. Zero disables it.
+	StaleWhileRevalidate time.Duration
+}
+
+// Server is an http.Handler proxying the TraderMade REST API
+type Server struct {
+	Config     Config
+	HTTPClient *http.Client
+
+	limiter  *ratelimit.Limiter
+	mu       sync.Mutex
+	cache    map[string]*entry
+	inflight map[string]*call
+}
+
+// entry is a cached upstream response
+type entry struct {
+	status      int
+	contentType string
+	body        []byte
+	expires     time.Time
+}
+
+// call is an upstream request that other identical requests can wait on
+type call struct {
+	done chan struct{}
+	resp *entry
+	err  error
+}
+
+// NewServer creates a proxy server
+func NewServer(cfg Config) *Server {
+	if cfg.Upstream == "" {
+		cfg.Upstream = tradermade.DefaultBaseURL
+	}
+	if cfg.DefaultTTL == 0 {
+		cfg.DefaultTTL = time.Minute
+	}
+	if cfg.TTLs == nil {
+		cfg.TTLs = map[string]time.Duration{
+			"live":    time.Second,
+			"convert": time.Second,
+		}
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 10000
+	}
+	if cfg.MaxWait <= 0 {
+		cfg.MaxWait = 10 * time.Second
+	}
+
+	return &Server{
+		Config: cfg,
+		HTTPClient: &http.Client{
+			Timeout: time.Second * 10,
+		},
+		limiter:  ratelimit.New(cfg.RequestsPerSecond, cfg.Burst),
+		cache:    make(map[string]*entry),
+		inflight: make(map[string]*call),
+	}
+}
+
+// ServeHTTP answers from the cache when possible and forwards to the upstream API otherwise
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	endpoint := path.Base(r.URL.Path)
+	if endpoint == "/" || endpoint == "." {
+		http.Error(w, "endpoint required", http.StatusNotFound)
+		return
+	}
+
+	// Clients never send the key, and it must not be part of the cache key
+	query := r.URL.Query()
+	query.Del("api_key")
+	key := endpoint + "?" + query.Encode()
+
+	resp, source, err := s.get(r.Context(), endpoint, key, query)
+	if err != nil {
+		status := http.StatusBadGateway
+		if err == errRateLimited {
+			status = http.StatusTooManyRequests
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	if resp.contentType != "" {
+		w.Header().Set("Content-Type", resp.contentType)
+	}
+	w.Header().Set("X-Cache", source)
+	w.WriteHeader(resp.status)
+	if r.Method != http.MethodHead {
+		w.Write(resp.body)
+	}
+}
+
+var errRateLimited = fmt.Errorf("upstream rate limit exceeded")
+
+// get returns a cached response, joins an identical in-flight request, or fetches upstream.
+// The second return value is HIT, STALE, SHARED or MISS for the X-Cache header.
+func (s *Server) get(ctx context.Context, endpoint, key string, query url.Values) (*entry, string, error) {
+	s.mu.Lock()
+	if e, ok := s.cache[key]; ok {
+		now := time.Now()
+		if now.Before(e.expires) {
+			s.mu.Unlock()
+			return e, "HIT", nil
+		}
+		if now.Before(e.expires.Add(s.Config.StaleWhileRevalidate)) {
+			if _, refreshing := s.inflight[key]; !refreshing {
+				c := &call{done: make(chan struct{})}
+				s.inflight[key] = c
+				go s.refresh(c, endpoint, key, query)
+			}
+			s.mu.Unlock()
+			return e, "STALE", nil
+		}
+	}
+	if c, ok := s.inflight[key]; ok {
+		s.mu.Unlock()
+		select {
+		case <-c.done:
+			return c.resp, "SHARED", c.err
+		case <-ctx.Done():
+			return nil, "", ctx.Err()
+		}
+	}
+	c := &call{done: make(chan struct{})}
+	s.inflight[key] = c
+	s.mu.Unlock()
+
+	s.refresh(c, endpoint, key, query)
+	return c.resp, "MISS", c.err
+}
+
+// refresh performs the upstream request for c and caches a successful
+// response. Errors the API reports with status 200, such as an exhausted
+// quota, are passed on but not cached.
+func (s *Server) refresh(c *call, endpoint, key string, query url.Values) {
+	// Detach from the caller's context so that waiters are not failed by one client going away
+	fetchCtx, cancel := context.WithTimeout(context.Background(), s.Config.MaxWait+s.HTTPClient.Timeout)
+	c.resp, c.err = s.fetch(fetchCtx, endpoint, query)
+	cancel()
+
+	s.mu.Lock()
+	delete(s.inflight, key)
+	if c.err == nil && tradermade.CheckResponse(c.resp.status, c.resp.body) == nil {
+		s.store(key, c.resp)
+	}
+	s.mu.Unlock()
+	close(c.done)
+}
+
+// fetch performs the upstream request, waiting for the rate limiter first
+func (s *Server) fetch(ctx context.Context, endpoint string, query url.Values) (*entry, error) {
+	waitCtx, cancel := context.WithTimeout(ctx, s.Config.MaxWait)
+	err := s.limiter.Wait(waitCtx)
+	cancel()
+	if err != nil {
+		return nil, errRateLimited
+	}
+
+	upstreamQuery := url.Values{}
+	for k, v := range query {
+		upstreamQuery[k] = v
+	}
+	upstreamQuery.Set("api_key", s.Config.APIKey)
+	URL := fmt.Sprintf("%s/%s?%s", s.Config.Upstream, endpoint, upstreamQuery.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("upstream request failed for %s", endpoint)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upstream response: %v", err)
+	}
+
+	return &entry{
+		status:      resp.StatusCode,
+		contentType: resp.Header.Get("Content-Type"),
+		body:        body,
+		expires:     time.Now().Add(s.ttl(endpoint)),
+	}, nil
+}
+
+// store adds a response to the cache, evicting expired entries when full. Must be called with mu held.
+func (s *Server) store(key string, e *entry) {
+	if len(s.cache) >= s.Config.MaxEntries {
+		now := time.Now()
+		for k, old := range s.cache {
+			if now.After(old.expires.Add(s.Config.StaleWhileRevalidate)) {
+				delete(s.cache, k)
+			}
+		}
+		// Still full: drop an arbitrary entry
+		for k := range s.cache {
+			if len(s.cache) < s.Config.MaxEntries {
+				break
+			}
+			delete(s.cache, k)
+		}
+	}
+	s.cache[key] = e
+}
+
+func (s *Server) ttl(endpoint string) time.Duration {
+	if ttl, ok := s.Config.TTLs[endpoint]; ok {
+		return ttl
+	}
+	return s.Config.DefaultTTL
+}
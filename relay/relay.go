@@ -0,0 +1,263 @@
+// Package relay fans one upstream TraderMade WebSocket connection out to any
+// number of local WebSocket and Server-Sent Events subscribers, each with its
+// own symbol filter, so a cluster does not need a connection per service.
+//
+//	client := tradermadews.NewWebSocketClient("YOUR_WS_KEY", "EURUSD,GBPUSD,USDJPY")
+//	r := relay.New(client)
+//	http.Handle("/ws", r.WebSocketHandler())
+//	http.Handle("/sse", r.SSEHandler())
+//	client.Connect()
+//
+// Subscribers choose symbols with the symbols query parameter
+// (/ws?symbols=EURUSD,GBPUSD). WebSocket subscribers can change their filter
+// later by sending {"symbols":"EURUSD"}. No parameter means every symbol.
+//
+// Browsers may only open the WebSocket handler from pages on the relay's own
+// host, so other websites cannot use the relay through their visitors; list
+// other pages' origins in AllowedOrigins.
+package relay
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Relay distributes upstream quotes to local subscribers
+type Relay struct {
+	BufferSize     int                 // Per-subscriber queue length (default 256)
+	PingInterval   time.Duration       // Keep-alive interval for downstream connections (default 30s)
+	WriteTimeout   time.Duration       // Time allowed for each downstream write before the subscriber is dropped (default 10s)
+	AllowedOrigins []string            // Browser origins allowed besides the relay's own host, e.g. "https://app.example.com"; "*" allows any
+	Upgrader       *websocket.Upgrader // Upgrader for downstream WebSocket connections
+
+	mu          sync.RWMutex
+	subscribers map[*subscriber]struct{}
+	dropped     uint64
+}
+
+// subscriber is one downstream connection
+type subscriber struct {
+	mu      sync.RWMutex
+	symbols map[string]bool // Empty means every symbol
+	ch      chan []byte
+}
+
+// New creates a relay and installs it as the message handler of upstream.
+// Pass nil to feed quotes through HandleQuote manually.
+func New(upstream *tradermadews.WebSocketClient) *Relay {
+	r := &Relay{
+		BufferSize:   256,
+		PingInterval: 30 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		subscribers:  make(map[*subscriber]struct{}),
+	}
+	r.Upgrader = &websocket.Upgrader{CheckOrigin: r.checkOrigin}
+	if upstream != nil {
+		upstream.SetMessageHandler(r.HandleQuote)
+	}
+	return r
+}
+
+// SetAllowedOrigins sets the browser origins allowed to open the WebSocket
+// handler besides the relay's own host
+func (r *Relay) SetAllowedOrigins(origins ...string) {
+	r.AllowedOrigins = origins
+}
+
+// checkOrigin allows requests without an Origin header (non-browser
+// clients), from the relay's own host, and from AllowedOrigins
+func (r *Relay) checkOrigin(req *http.Request) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, req.Host) {
+		return true
+	}
+	for _, allowed := range r.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(strings.TrimRight(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// deadline returns the deadline for a downstream write started now
+func (r *Relay) deadline() time.Time {
+	timeout := r.WriteTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return time.Now().Add(timeout)
+}
+
+// HandleQuote encodes a quote once and queues it for every matching subscriber.
+// Subscribers whose queue is full miss the quote instead of stalling the feed.
+func (r *Relay) HandleQuote(quote tradermadews.QuoteMessage, _ string) {
+	payload, err := json.Marshal(quote)
+	if err != nil {
+		return
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for sub := range r.subscribers {
+		if !sub.wants(quote.Symbol) {
+			continue
+		}
+		select {
+		case sub.ch <- payload:
+		default:
+			atomic.AddUint64(&r.dropped, 1)
+		}
+	}
+}
+
+// Subscribers returns the number of connected downstream clients
+func (r *Relay) Subscribers() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.subscribers)
+}
+
+// Dropped returns how many quotes were discarded for slow subscribers
+func (r *Relay) Dropped() uint64 {
+	return atomic.LoadUint64(&r.dropped)
+}
+
+// WebSocketHandler serves downstream WebSocket subscribers
+func (r *Relay) WebSocketHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		conn, err := r.Upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return // Upgrade has already written the error response
+		}
+		defer conn.Close()
+
+		sub := r.subscribe(req.URL.Query().Get("symbols"))
+		defer r.unsubscribe(sub)
+
+		// Reader: filter updates from the client, and detection of closed connections
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			for {
+				var msg struct {
+					Symbols string `json:"symbols"`
+				}
+				if err := conn.ReadJSON(&msg); err != nil {
+					if _, ok := err.(*json.SyntaxError); ok {
+						continue
+					}
+					return
+				}
+				sub.setSymbols(msg.Symbols)
+			}
+		}()
+
+		ping := time.NewTicker(r.PingInterval)
+		defer ping.Stop()
+		for {
+			select {
+			case payload := <-sub.ch:
+				conn.SetWriteDeadline(r.deadline())
+				if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+					return
+				}
+			case <-ping.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, r.deadline()); err != nil {
+					return
+				}
+			case <-closed:
+				return
+			}
+		}
+	})
+}
+
+// SSEHandler serves downstream Server-Sent Events subscribers
+func (r *Relay) SSEHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming not supported", http.StatusInternalServerError)
+			return
+		}
+		rc := http.NewResponseController(w)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		sub := r.subscribe(req.URL.Query().Get("symbols"))
+		defer r.unsubscribe(sub)
+
+		ping := time.NewTicker(r.PingInterval)
+		defer ping.Stop()
+		for {
+			select {
+			case payload := <-sub.ch:
+				rc.SetWriteDeadline(r.deadline())
+				if _, err := fmt.Fprintf(w, "event: quote\ndata: %s\n\n", payload); err != nil {
+					return
+				}
+				flusher.Flush()
+			case <-ping.C:
+				rc.SetWriteDeadline(r.deadline())
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+				flusher.Flush()
+			case <-req.Context().Done():
+				return
+			}
+		}
+	})
+}
+
+func (r *Relay) subscribe(symbols string) *subscriber {
+	sub := &subscriber{ch: make(chan []byte, r.BufferSize)}
+	sub.setSymbols(symbols)
+
+	r.mu.Lock()
+	r.subscribers[sub] = struct{}{}
+	r.mu.Unlock()
+	return sub
+}
+
+func (r *Relay) unsubscribe(sub *subscriber) {
+	r.mu.Lock()
+	delete(r.subscribers, sub)
+	r.mu.Unlock()
+}
+
+// setSymbols replaces the filter with a comma separated symbol list
+func (s *subscriber) setSymbols(list string) {
+	symbols := make(map[string]bool)
+	for _, symbol := range strings.Split(list, ",") {
+		if symbol = strings.ToUpper(strings.TrimSpace(symbol)); symbol != "" {
+			symbols[symbol] = true
+		}
+	}
+
+	s.mu.Lock()
+	s.symbols = symbols
+	s.mu.Unlock()
+}
+
+func (s *subscriber) wants(symbol string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.symbols) == 0 || s.symbols[symbol]
+}
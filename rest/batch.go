@@ -0,0 +1,121 @@
+package tradermade
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tradermade/Go-SDK/ohlc"
+)
+
+// DefaultBatchWorkers is the number of concurrent requests GetHistoricalBatch
+// makes when none is given
+const DefaultBatchWorkers = 4
+
+// BarKey identifies one bar of a batch: a pair and the bar's start in UTC
+type BarKey struct {
+	Pair string
+	Time time.Time
+}
+
+// BarResult is one bar of a batch, or the error fetching it
+type BarResult struct {
+	Candle ohlc.Candle
+	Err    error
+}
+
+// HistoricalBatch holds the results of GetHistoricalBatch
+type HistoricalBatch map[BarKey]BarResult
+
+// Get returns the result for pair at t, which may be any time within the bar
+func (b HistoricalBatch) Get(pair string, t time.Time, interval string) (BarResult, bool) {
+	r, ok := b[BarKey{Pair: pair, Time: barStart(t, interval)}]
+	return r, ok
+}
+
+// Errors returns the keys that failed and their errors
+func (b HistoricalBatch) Errors() map[BarKey]error {
+	errs := make(map[BarKey]error)
+	for key, r := range b {
+		if r.Err != nil {
+			errs[key] = r.Err
+		}
+	}
+	return errs
+}
+
+// barStart truncates t to the start of its hour or minute bar in UTC
+func barStart(t time.Time, interval string) time.Time {
+	if interval == "hour" {
+		return t.UTC().Truncate(time.Hour)
+	}
+	return t.UTC().Truncate(time.Minute)
+}
+
+// GetHistoricalBatch fetches the hour or minute bar of every pair at every
+// time with up to workers concurrent requests (default DefaultBatchWorkers),
+// within the client's RateLimit. Times within the same bar are fetched once.
+// A failing bar does not stop the others: its error is in its result, and
+// the returned error only reports invalid arguments. Cancelling ctx fails the
+// bars not yet fetched.
+func (c *RESTClient) GetHistoricalBatch(ctx context.Context, pairs []string, times []time.Time, interval string, workers int) (HistoricalBatch, error) {
+	if interval != "hour" && interval != "minute" {
+		return nil, fmt.Errorf("invalid interval for a historical batch: %s", interval)
+	}
+	if workers <= 0 {
+		workers = DefaultBatchWorkers
+	}
+
+	var keys []BarKey
+	seen := make(map[BarKey]bool)
+	for _, pair := range pairs {
+		for _, t := range times {
+			key := BarKey{Pair: pair, Time: barStart(t, interval)}
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	batch := make(HistoricalBatch, len(keys))
+	var mu sync.Mutex
+	jobs := make(chan BarKey)
+	var wg sync.WaitGroup
+	for i := 0; i < min(workers, len(keys)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range jobs {
+				result := c.historicalBar(ctx, key, interval)
+				mu.Lock()
+				batch[key] = result
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, key := range keys {
+		jobs <- key
+	}
+	close(jobs)
+	wg.Wait()
+	return batch, nil
+}
+
+// historicalBar fetches one bar of a batch
+func (c *RESTClient) historicalBar(ctx context.Context, key BarKey, interval string) BarResult {
+	if err := ctx.Err(); err != nil {
+		return BarResult{Err: err}
+	}
+	rate, err := c.historical(ctx, key.Pair, formatHistoricalDate(key.Time, interval), interval)
+	if err != nil {
+		return BarResult{Err: err}
+	}
+	bar, err := rate.(*HistoricalData).Candle()
+	if err != nil {
+		return BarResult{Err: err}
+	}
+	bar.Symbol = key.Pair
+	return BarResult{Candle: bar}
+}
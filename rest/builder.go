@@ -0,0 +1,249 @@
+package tradermade
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tradermade/Go-SDK/clock"
+	"github.com/tradermade/Go-SDK/ohlc"
+)
+
+// Interval is the bar length unit of a request built with TimeSeries or
+// Historical
+type Interval string
+
+const (
+	Daily  Interval = "daily"
+	Hourly Interval = "hourly"
+	Minute Interval = "minute"
+)
+
+// duration returns the length of one bar of the interval
+func (i Interval) duration(period int) time.Duration {
+	switch i {
+	case Hourly:
+		return time.Duration(period) * time.Hour
+	case Minute:
+		return time.Duration(period) * time.Minute
+	}
+	return 24 * time.Hour
+}
+
+// TimeSeriesRequest builds a timeseries request step by step:
+//
+//	series, err := client.TimeSeries("EURUSD").
+//		From(start).To(end).
+//		Interval(tradermade.Minute, 15).
+//		Fetch(ctx)
+//
+// Invalid settings are reported by Fetch, so a chain needs one error check.
+type TimeSeriesRequest struct {
+	client   *RESTClient
+	currency string
+	start    time.Time
+	end      time.Time
+	interval Interval
+	period   int
+	fields   Fields
+	split    bool
+	err      error // First invalid setting
+}
+
+// TimeSeries starts a daily timeseries request for currency
+func (c *RESTClient) TimeSeries(currency string) *TimeSeriesRequest {
+	return &TimeSeriesRequest{client: c, currency: currency, interval: Daily, period: 1}
+}
+
+// From sets the start of the range
+func (r *TimeSeriesRequest) From(start time.Time) *TimeSeriesRequest {
+	r.start = start
+	return r
+}
+
+// To sets the end of the range (default now)
+func (r *TimeSeriesRequest) To(end time.Time) *TimeSeriesRequest {
+	r.end = end
+	return r
+}
+
+// Last sets the range to the d before To, or before now
+func (r *TimeSeriesRequest) Last(d time.Duration) *TimeSeriesRequest {
+	if r.end.IsZero() {
+		r.end = clock.OrDefault(r.client.Clock).Now()
+	}
+	r.start = r.end.Add(-d)
+	return r
+}
+
+// Interval sets the bar length: Daily, or Hourly or Minute with a period of
+// 1, 2, 4, 6, 8 or 24 hours or 1, 5, 10, 15 or 30 minutes (default 1)
+func (r *TimeSeriesRequest) Interval(interval Interval, period ...int) *TimeSeriesRequest {
+	p := 1
+	if len(period) > 0 {
+		p = period[0]
+	}
+	switch {
+	case interval == Daily && p == 1:
+	case interval == Hourly && isValidPeriodForHourly(p):
+	case interval == Minute && isValidPeriodForMinute(p):
+	case interval == Daily:
+		r.fail(fmt.Errorf("daily interval takes no period, got %d", p))
+	case interval == Hourly || interval == Minute:
+		r.fail(fmt.Errorf("invalid period for %s interval: %d", interval, p))
+	default:
+		r.fail(fmt.Errorf("invalid interval: %s", interval))
+	}
+	r.interval, r.period = interval, p
+	return r
+}
+
+// Fields selects the price fields returned, e.g. FieldClose for closes only,
+// to cut the size of long ranges. Fields that were not requested are marked
+// Absent in every quote.
+func (r *TimeSeriesRequest) Fields(fields Fields) *TimeSeriesRequest {
+	if _, err := fields.timeSeriesParam(); err != nil {
+		r.fail(err)
+	}
+	r.fields = fields
+	return r
+}
+
+// CloseOnly requests closing prices only, see Fields
+func (r *TimeSeriesRequest) CloseOnly() *TimeSeriesRequest {
+	return r.Fields(FieldClose)
+}
+
+// Split fetches ranges longer than MaxTimeSeriesRange in several requests,
+// see GetTimeSeriesDataSplit
+func (r *TimeSeriesRequest) Split() *TimeSeriesRequest {
+	r.split = true
+	return r
+}
+
+// fail records the first invalid setting
+func (r *TimeSeriesRequest) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// Params returns the request as TimeSeriesParams, e.g. for GetTimeSeriesCSVTo
+func (r *TimeSeriesRequest) Params() (TimeSeriesParams, error) {
+	if r.err != nil {
+		return TimeSeriesParams{}, r.err
+	}
+	if r.start.IsZero() {
+		return TimeSeriesParams{}, fmt.Errorf("timeseries request for %s has no start, see From", r.currency)
+	}
+	end := r.end
+	if end.IsZero() {
+		end = clock.OrDefault(r.client.Clock).Now()
+	}
+	params := TimeSeriesParams{Currency: r.currency, Start: r.start, End: end, Interval: string(r.interval), Fields: r.fields}
+	if r.interval != Daily {
+		params.Period = r.period
+	}
+	return params, nil
+}
+
+// Fetch sends the request
+func (r *TimeSeriesRequest) Fetch(ctx context.Context) (*TimeSeriesRate, error) {
+	params, err := r.Params()
+	if err != nil {
+		return nil, err
+	}
+	var period []int
+	if params.Period > 0 {
+		period = []int{params.Period}
+	}
+	if r.split {
+		return r.client.timeSeriesSplit(ctx, params.Currency, params.Start, params.End, params.Interval, params.Fields, period...)
+	}
+	return r.client.timeSeriesRange(ctx, params.Currency, params.Start, params.End, params.Interval, params.Fields, period...)
+}
+
+// Candles sends the request and returns the quotes as candles. With close
+// only requests, open, high and low equal the close.
+func (r *TimeSeriesRequest) Candles(ctx context.Context) ([]ohlc.Candle, error) {
+	series, err := r.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	candles, err := series.Candles(r.interval.duration(r.period))
+	if err != nil {
+		return nil, err
+	}
+	if r.fields == FieldClose {
+		for i := range candles {
+			candles[i].Open, candles[i].High, candles[i].Low = candles[i].Close, candles[i].Close, candles[i].Close
+		}
+	}
+	return candles, nil
+}
+
+// HistoricalRequest builds a request for the bar at one time:
+//
+//	candles, err := client.Historical("EURUSD,GBPUSD").At(t).Interval(tradermade.Hourly).Fetch(ctx)
+type HistoricalRequest struct {
+	client   *RESTClient
+	currency string
+	at       time.Time
+	interval Interval
+}
+
+// Historical starts a daily historical request for currency, which may list
+// several symbols for daily data
+func (c *RESTClient) Historical(currency string) *HistoricalRequest {
+	return &HistoricalRequest{client: c, currency: currency, interval: Daily}
+}
+
+// At sets the time of the bar
+func (r *HistoricalRequest) At(t time.Time) *HistoricalRequest {
+	r.at = t
+	return r
+}
+
+// Interval sets the bar length: Daily, Hourly or Minute
+func (r *HistoricalRequest) Interval(interval Interval) *HistoricalRequest {
+	r.interval = interval
+	return r
+}
+
+// Fetch sends the request and returns the bar of every requested symbol
+func (r *HistoricalRequest) Fetch(ctx context.Context) ([]ohlc.Candle, error) {
+	if r.at.IsZero() {
+		return nil, fmt.Errorf("historical request for %s has no time, see At", r.currency)
+	}
+	var endpoint string
+	switch r.interval {
+	case Daily:
+		endpoint = "day"
+	case Hourly:
+		endpoint = "hour"
+	case Minute:
+		endpoint = "minute"
+	default:
+		return nil, fmt.Errorf("invalid interval: %s", r.interval)
+	}
+	if endpoint != "day" && strings.Contains(r.currency, ",") {
+		return nil, fmt.Errorf("%s historical requests take one symbol, got %s", r.interval, r.currency)
+	}
+
+	rate, err := r.client.historical(ctx, r.currency, formatHistoricalDate(r.at, endpoint), endpoint)
+	if err != nil {
+		return nil, err
+	}
+	switch rate := rate.(type) {
+	case *HistoricalRate:
+		return rate.Candles()
+	case *HistoricalData:
+		c, err := rate.Candle()
+		if err != nil {
+			return nil, err
+		}
+		return []ohlc.Candle{c}, nil
+	}
+	return nil, fmt.Errorf("unexpected historical response %T", rate)
+}
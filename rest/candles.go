@@ -0,0 +1,88 @@
+package tradermade
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tradermade/Go-SDK/ohlc"
+)
+
+// Candle converts a timeseries quote of symbol to a candle with the given bar length
+func (q TimeSeriesQuote) Candle(symbol string, interval time.Duration) (ohlc.Candle, error) {
+	t, err := ParseDate(q.Date)
+	if err != nil {
+		return ohlc.Candle{}, fmt.Errorf("failed to parse quote date: %w", err)
+	}
+	return ohlc.Candle{Symbol: symbol, Time: t, Interval: interval, Open: q.Open, High: q.High, Low: q.Low, Close: q.Close}, nil
+}
+
+// Candles converts every quote in the timeseries to a candle with the given bar length
+func (r *TimeSeriesRate) Candles(interval time.Duration) ([]ohlc.Candle, error) {
+	symbol := r.BaseCurrency + r.QuoteCurrency
+	candles := make([]ohlc.Candle, 0, len(r.Quotes))
+	for _, q := range r.Quotes {
+		c, err := q.Candle(symbol, interval)
+		if err != nil {
+			return nil, err
+		}
+		candles = append(candles, c)
+	}
+	return candles, nil
+}
+
+// Candle converts a minute or hour historical rate to a candle
+func (h *HistoricalData) Candle() (ohlc.Candle, error) {
+	t, err := ParseDate(h.DateTime)
+	if err != nil {
+		return ohlc.Candle{}, fmt.Errorf("failed to parse rate date: %w", err)
+	}
+	var interval time.Duration
+	switch h.Endpoint {
+	case "minute_historical":
+		interval = time.Minute
+	case "hour_historical":
+		interval = time.Hour
+	}
+	return ohlc.Candle{Symbol: h.Currency, Time: t, Interval: interval, Open: h.Open, High: h.High, Low: h.Low, Close: h.Close}, nil
+}
+
+// Candles converts every quote of a daily historical rate to a daily candle
+func (r *HistoricalRate) Candles() ([]ohlc.Candle, error) {
+	t, err := ParseDate(r.Date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rate date: %w", err)
+	}
+	candles := make([]ohlc.Candle, 0, len(r.Quotes))
+	for _, q := range r.Quotes {
+		candles = append(candles, ohlc.Candle{
+			Symbol:   q.BaseCurrency + q.QuoteCurrency,
+			Time:     t,
+			Interval: 24 * time.Hour,
+			Open:     q.Open,
+			High:     q.High,
+			Low:      q.Low,
+			Close:    q.Close,
+		})
+	}
+	return candles, nil
+}
+
+// GetDailyHistoryRange returns the daily candles of pair from from to to,
+// inclusive, using as few timeseries requests as the range allows instead of
+// one historical request per day
+func (c *RESTClient) GetDailyHistoryRange(pair string, from, to time.Time) ([]ohlc.Candle, error) {
+	series, err := c.GetTimeSeriesDataSplit(pair, from, to, "daily")
+	if err != nil {
+		return nil, err
+	}
+
+	candles := make([]ohlc.Candle, 0, len(series.Quotes))
+	for _, q := range series.Quotes {
+		bar, err := q.Candle(pair, 24*time.Hour)
+		if err != nil {
+			return nil, err
+		}
+		candles = append(candles, bar)
+	}
+	return candles, nil
+}
@@ -1,15 +1,20 @@
 package tradermade
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/tradermade/Go-SDK/clock"
+	"github.com/tradermade/Go-SDK/codec"
+	"github.com/tradermade/Go-SDK/internal/redact"
 )
 
-const baseURL = "https://marketdata.tradermade.com/api/v1"
+// DefaultBaseURL is the TraderMade REST API
+const DefaultBaseURL = "https://marketdata.tradermade.com/api/v1"
 
 // Structure for the entire API response for live rates
 type LiveRate struct {
@@ -27,6 +32,7 @@ type Quote struct {
 	BaseCurrency  string  `json:"base_currency,omitempty"`  // Optional field
 	QuoteCurrency string  `json:"quote_currency,omitempty"` // Optional field
 	Instrument    string  `json:"instrument,omitempty"`     // Optional field for indices
+	Absent        Fields  `json:"-"`                        // Numeric fields that were null or missing
 }
 type HistoricalRate struct {
 	Date        string            `json:"date"`
@@ -41,6 +47,7 @@ type ConvertResponse struct {
 	Total         float64 `json:"total"`
 	RequestedTime string  `json:"requested_time"`
 	Timestamp     int64   `json:"timestamp"`
+	Absent        Fields  `json:"-"` // Numeric fields that were null or missing
 }
 
 // Structure for handling API error responses
@@ -63,6 +70,7 @@ type HistoricalQuote struct {
 	High          float64 `json:"high"`
 	Low           float64 `json:"low"`
 	Close         float64 `json:"close"`
+	Absent        Fields  `json:"-"` // Numeric fields that were null or missing
 }
 
 type HistoricalData struct {
@@ -74,6 +82,7 @@ type HistoricalData struct {
 	Low         float64 `json:"low"`
 	Close       float64 `json:"close"`
 	RequestTime string  `json:"request_time"`
+	Absent      Fields  `json:"-"` // Numeric fields that were null or missing
 }
 
 // Structure for parsing timeseries data
@@ -94,94 +103,162 @@ type TimeSeriesQuote struct {
 	High  float64 `json:"high"`
 	Low   float64 `json:"low"`
 	Close float64 `json:"close"`
+
+	Absent Fields `json:"-"` // Numeric fields that were null or missing
 }
 
 // RESTClient structure that includes the HTTP client and API key
 type RESTClient struct {
 	APIKey     string
+	BaseURL    string // API base URL without a trailing slash (default DefaultBaseURL)
 	HTTPClient *http.Client
+	Codec      codec.Codec // Decodes responses, nil uses encoding/json
+
+	SymbolResolver func(string) (string, error) // Normalizes requested symbols, e.g. symbols.Resolve; nil uses them as given
+
+	MaxRetries    int           // Retries for failures classified as retryable, see IsRetryable (default 2)
+	RetryInterval time.Duration // Delay before the first retry, doubled each retry (default 500ms)
+
+	RateLimit float64 // Requests per second across all methods, zero disables limiting, see SetRateLimit
+	RateBurst int     // Requests allowed at once before RateLimit applies (default 1)
+
+	ConditionalCacheSize int // Responses kept for conditional requests, zero disables them, see SetConditionalRequests
+
+	Clock clock.Clock // Times retries and usage, nil uses the system clock
+
+	usage      usage               // Request counters behind Usage()
+	validators validatorCache      // Responses kept for conditional requests
+	supported  supportedCurrencies // Codes loaded by LoadSupportedCurrencies
+	limiter    limiter             // Token bucket behind RateLimit
 }
 
 // NewRESTClient initializes a new REST client
 func NewRESTClient(apiKey string) *RESTClient {
 	return &RESTClient{
-		APIKey: apiKey,
+		APIKey:  apiKey,
+		BaseURL: DefaultBaseURL,
 		HTTPClient: &http.Client{
-			Timeout: time.Second * 10,
+			Timeout:   time.Second * 10,
+			Transport: NewTransport(DefaultTransportOptions),
 		},
+		MaxRetries:    2,
+		RetryInterval: 500 * time.Millisecond,
 	}
 }
 
-// GetLiveRates fetches live rates for specified currencies or instruments
-func (c *RESTClient) GetLiveRates(currencies []string) (*LiveRate, error) {
-	// Construct the URL
-	URL := fmt.Sprintf("https://marketdata.tradermade.com/api/v1/live?currency=%s&api_key=%s", joinStrings(currencies), c.APIKey)
+// SetRetryPolicy sets how often and after what delay retryable failures are
+// retried. Zero retries disables retrying.
+func (c *RESTClient) SetRetryPolicy(maxRetries int, interval time.Duration) {
+	c.MaxRetries = maxRetries
+	c.RetryInterval = interval
+}
 
-	encodedURL := strings.ReplaceAll(URL, " ", "%20")
-	resp, err := c.HTTPClient.Get(encodedURL)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+// SetBaseURL sets the API base URL, e.g. a dedicated enterprise endpoint or a mock server
+func (c *RESTClient) SetBaseURL(URL string) {
+	c.BaseURL = strings.TrimSuffix(URL, "/")
+}
 
-	// Read the response body
-	var body []byte
-	if body, err = ioutil.ReadAll(resp.Body); err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
+// baseURL returns the configured base URL or DefaultBaseURL
+func (c *RESTClient) baseURL() string {
+	if c.BaseURL == "" {
+		return DefaultBaseURL
 	}
-	if resp.StatusCode != http.StatusOK {
-		// Try to decode the error response
-		var errorResponse ErrorResponse
+	return c.BaseURL
+}
+
+// SetSymbolResolver sets the function normalizing requested symbols, e.g.
+// symbols.Resolve so "eur/usd" requests EURUSD. Requests fail with the
+// resolver's error for symbols it rejects.
+func (c *RESTClient) SetSymbolResolver(resolve func(string) (string, error)) {
+	c.SymbolResolver = resolve
+}
 
-		if err := json.Unmarshal(body, &errorResponse); err != nil {
-			return nil, fmt.Errorf("API request failed with status code %d: %s", resp.StatusCode, string(body))
+// resolveSymbols passes each symbol of a comma separated list through the
+// symbol resolver, if any
+func (c *RESTClient) resolveSymbols(list string) (string, error) {
+	if c.SymbolResolver == nil {
+		return list, nil
+	}
+	symbols := strings.Split(list, ",")
+	for i, s := range symbols {
+		resolved, err := c.SymbolResolver(s)
+		if err != nil {
+			return "", err
 		}
-		return nil, fmt.Errorf("API request failed with status code %d: %v ", resp.StatusCode, errorResponse.Errors)
+		symbols[i] = resolved
 	}
+	return strings.Join(symbols, ","), nil
+}
 
-	// DEBUG: Print the raw response body to check the content
-	//	fmt.Printf("Raw Response Body: %s\n", string(body))
+// SetCodec sets the JSON codec used to decode responses, e.g. a faster
+// implementation for large timeseries payloads
+func (c *RESTClient) SetCodec(cd codec.Codec) {
+	c.Codec = cd
+}
 
-	// Check if the JSON response contains an "error" field
-	var errorResponse ErrorResponseOK
-	if err := json.Unmarshal(body, &errorResponse); err == nil {
-		// If the error field is not empty, return it as an error
-		if errorResponse.Error != 0 {
-			return nil, fmt.Errorf("API error: %d - %s", errorResponse.Error, errorResponse.Message)
-		}
+// unmarshal decodes a response body with the configured codec
+func (c *RESTClient) unmarshal(body []byte, v any) error {
+	return codec.OrDefault(c.Codec).Unmarshal(body, v)
+}
+
+// decode decodes a successful response and marks null or missing numeric
+// fields as absent
+func (c *RESTClient) decode(body []byte, v any) error {
+	if err := c.unmarshal(body, v); err != nil {
+		return err
 	}
-	// Check if the status code is not OK
-	// Check if the response contains an error message even with a 200 status code
+	return markAbsent(c.Codec, body, v)
+}
+
+// GetLiveRates fetches live rates for specified currencies or instruments
+func (c *RESTClient) GetLiveRates(currencies []string) (*LiveRate, error) {
+	symbols, err := c.resolveSymbols(joinStrings(currencies))
+	if err != nil {
+		return nil, err
+	}
+
+	// Construct the URL
+	URL := fmt.Sprintf("%s/live?currency=%s&api_key=%s", c.baseURL(), symbols, c.APIKey)
 
 	var liveRate LiveRate
-	if err := json.Unmarshal(body, &liveRate); err != nil {
-		return nil, fmt.Errorf("failed to parse successful response: %v", err)
+	if err := c.sendRequest(URL, &liveRate); err != nil {
+		return nil, err
 	}
 
 	return &liveRate, nil
 }
 
 func (c *RESTClient) GetHistoricalRates(currency, dateTime, interval string) (interface{}, error) {
+	return c.historical(context.Background(), currency, dateTime, interval)
+}
+
+// historical is GetHistoricalRates with a context
+func (c *RESTClient) historical(ctx context.Context, currency, dateTime, interval string) (interface{}, error) {
+	currency, err := c.resolveSymbols(currency)
+	if err != nil {
+		return nil, err
+	}
+
 	var URL string
 	switch interval {
 	case "minute":
-		URL = fmt.Sprintf("%s/minute_historical?currency=%s&date_time=%s&api_key=%s", baseURL, currency, dateTime, c.APIKey)
+		URL = fmt.Sprintf("%s/minute_historical?currency=%s&date_time=%s&api_key=%s", c.baseURL(), currency, dateTime, c.APIKey)
 		var minuteRate HistoricalData
-		if err := c.sendHistoricalRequest(URL, &minuteRate); err != nil {
+		if err := c.sendRequestContext(ctx, URL, &minuteRate); err != nil {
 			return nil, err
 		}
 		return &minuteRate, nil
 	case "hour":
-		URL = fmt.Sprintf("%s/hour_historical?currency=%s&date_time=%s&api_key=%s", baseURL, currency, dateTime, c.APIKey)
+		URL = fmt.Sprintf("%s/hour_historical?currency=%s&date_time=%s&api_key=%s", c.baseURL(), currency, dateTime, c.APIKey)
 		var hourRate HistoricalData
-		if err := c.sendHistoricalRequest(URL, &hourRate); err != nil {
+		if err := c.sendRequestContext(ctx, URL, &hourRate); err != nil {
 			return nil, err
 		}
 		return &hourRate, nil
 	case "day":
-		URL = fmt.Sprintf("%s/historical?currency=%s&date=%s&api_key=%s", baseURL, currency, dateTime, c.APIKey)
+		URL = fmt.Sprintf("%s/historical?currency=%s&date=%s&api_key=%s", c.baseURL(), currency, dateTime, c.APIKey)
 		var dailyRate HistoricalRate
-		if err := c.sendHistoricalRequest(URL, &dailyRate); err != nil {
+		if err := c.sendRequestContext(ctx, URL, &dailyRate); err != nil {
 			return nil, err
 		}
 		return &dailyRate, nil
@@ -198,176 +275,236 @@ func (c *RESTClient) GetTimeSeriesData(
 	interval string, // "daily", "hourly", or "minute"
 	period ...int) (*TimeSeriesRate, error) {
 
+	return c.timeSeries(context.Background(), currency, startDate, endDate, interval, 0, period...)
+}
+
+// timeSeries is GetTimeSeriesData with a context
+func (c *RESTClient) timeSeries(ctx context.Context, currency, startDate, endDate, interval string, fields Fields, period ...int) (*TimeSeriesRate, error) {
+	URL, err := c.timeSeriesURL(currency, startDate, endDate, interval, "records", fields, period...)
+	if err != nil {
+		return nil, err
+	}
+
+	var timeSeriesData TimeSeriesRate
+	if err := c.sendRequestContext(ctx, URL, &timeSeriesData); err != nil {
+		return nil, err
+	}
+
+	return &timeSeriesData, nil
+}
+
+// timeSeriesURL validates the interval, period and range of a timeseries
+// request and builds its URL for the given response format. Non-zero fields
+// select the price fields returned.
+func (c *RESTClient) timeSeriesURL(currency, startDate, endDate, interval, format string, fields Fields, period ...int) (string, error) {
+	currency, err := c.resolveSymbols(currency)
+	if err != nil {
+		return "", err
+	}
+	selected, err := fields.timeSeriesParam()
+	if err != nil {
+		return "", err
+	}
+
 	// Validate and construct URL based on interval
 	var URL string
 
-	// Base URL for timeseries endpoint with mandatory fields
-	baseURL := fmt.Sprintf("%s/timeseries?currency=%s&start_date=%s&end_date=%s&format=records&api_key=%s",
-		baseURL, currency, startDate, endDate, c.APIKey)
+	// Timeseries URL with mandatory fields
+	timeseriesURL := fmt.Sprintf("%s/timeseries?currency=%s&start_date=%s&end_date=%s&format=%s&api_key=%s",
+		c.baseURL(), currency, startDate, endDate, format, c.APIKey)
 
 	// If interval is daily, no period is required
 	if strings.ToLower(interval) == "daily" {
-		URL = baseURL + "&interval=daily"
+		URL = timeseriesURL + "&interval=daily"
 	} else if strings.ToLower(interval) == "hourly" || strings.ToLower(interval) == "minute" {
 		// Check if the period is provided for hourly or minute intervals
 		if len(period) == 0 {
-			return nil, fmt.Errorf("period must be provided for %s interval", interval)
+			return "", fmt.Errorf("period must be provided for %s interval", interval)
 		}
 
 		// Handle hourly interval with period validation
 		if strings.ToLower(interval) == "hourly" {
 			if isValidPeriodForHourly(period[0]) {
-				URL = fmt.Sprintf("%s&interval=hourly&period=%d", baseURL, period[0])
+				URL = fmt.Sprintf("%s&interval=hourly&period=%d", timeseriesURL, period[0])
 			} else {
-				return nil, fmt.Errorf("invalid period for hourly interval: %d", period[0])
+				return "", fmt.Errorf("invalid period for hourly interval: %d", period[0])
 			}
 		}
 
 		// Handle minute interval with period validation
 		if strings.ToLower(interval) == "minute" {
 			if isValidPeriodForMinute(period[0]) {
-				URL = fmt.Sprintf("%s&interval=minute&period=%d", baseURL, period[0])
+				URL = fmt.Sprintf("%s&interval=minute&period=%d", timeseriesURL, period[0])
 			} else {
-				return nil, fmt.Errorf("invalid period for minute interval: %d", period[0])
+				return "", fmt.Errorf("invalid period for minute interval: %d", period[0])
 			}
 		}
 	} else {
-		return nil, fmt.Errorf("invalid interval: %s", interval)
+		return "", fmt.Errorf("invalid interval: %s", interval)
 	}
 
-	// encode url to eliminate space
-	encodedURL := strings.ReplaceAll(URL, " ", "%20")
-	resp, err := c.HTTPClient.Get(encodedURL)
-	if err != nil {
-		return nil, err
+	if err := validateTimeSeriesDates(startDate, endDate, interval); err != nil {
+		return "", err
 	}
-	defer resp.Body.Close()
-
-	// Read the response body
-	var body []byte
-	if body, err = ioutil.ReadAll(resp.Body); err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
+	if selected != "" {
+		URL += "&fields=" + selected
 	}
+	return URL, nil
+}
 
-	// Check if the status code is not OK
-	if resp.StatusCode != http.StatusOK {
-		// Try to decode the error response
-		var errorResponse ErrorResponse
-
-		if err := json.Unmarshal(body, &errorResponse); err == nil {
-			return nil, fmt.Errorf("API request failed with status code %d: %s", resp.StatusCode, string(body))
+// ConvertCurrency sends a request to the TraderMade Convert API
+func (c *RESTClient) ConvertCurrency(from string, to string, amount float64) (*ConvertResponse, error) {
+	for _, code := range []string{from, to} {
+		if err := c.ValidateCurrency(code); err != nil {
+			return nil, err
 		}
-		return nil, fmt.Errorf("API request failed with status code %d: %v ", resp.StatusCode, formatErrorMap(errorResponse.Errors))
 	}
 
-	var errorResponse ErrorResponseOK
-	if err := json.Unmarshal(body, &errorResponse); err == nil {
-		// If the error field is not empty, return it as an error
-		if errorResponse.Error != 0 {
-			return nil, fmt.Errorf("API error: %d - %s", errorResponse.Error, errorResponse.Message)
-		}
-	}
+	// Construct the URL
+	URL := fmt.Sprintf("%s/convert?from=%s&to=%s&amount=%f&api_key=%s",
+		c.baseURL(), from, to, amount, c.APIKey)
 
-	// Decode the successful response into the TimeSeriesRate struct
-	var timeSeriesData TimeSeriesRate
-	if err := json.Unmarshal(body, &timeSeriesData); err != nil {
-		return nil, fmt.Errorf("failed to parse successful response: %v", err)
+	encodedURL := strings.ReplaceAll(URL, " ", "")
+
+	// Decode the successful response into the ConvertResponse struct
+	var convertResponse ConvertResponse
+	if err := c.sendRequest(encodedURL, &convertResponse); err != nil {
+		return nil, err
 	}
 
-	return &timeSeriesData, nil
+	return &convertResponse, nil
 }
 
-// ConvertCurrency sends a request to the TraderMade Convert API
-func (c *RESTClient) ConvertCurrency(from string, to string, amount float64) (*ConvertResponse, error) {
-	// Construct the URL
-	URL := fmt.Sprintf("https://marketdata.tradermade.com/api/v1/convert?from=%s&to=%s&amount=%f&api_key=%s",
-		from, to, amount, c.APIKey)
+// sendRequest makes the HTTP request, retrying failures classified as
+// retryable, and unmarshals the response into v
+func (c *RESTClient) sendRequest(URL string, v interface{}) error {
+	return c.sendRequestContext(context.Background(), URL, v)
+}
 
-	// Perform the request
-	encodedURL := strings.ReplaceAll(URL, " ", "")
-	resp, err := c.HTTPClient.Get(encodedURL)
+// sendRequestContext is sendRequest with a context, which cancels the request
+// and any wait for a retry or the rate limit
+func (c *RESTClient) sendRequestContext(ctx context.Context, URL string, v interface{}) error {
+	body, err := c.fetch(ctx, URL)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer resp.Body.Close()
-
-	// Read the response body
-	var body []byte
-	if body, err = ioutil.ReadAll(resp.Body); err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
+	// Decode the successful response into the provided interface (v)
+	if err := c.decode(body, v); err != nil {
+		return fmt.Errorf("failed to parse successful response: %v", err)
 	}
+	return nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		// Try to decode the error response
-		var errorResponse ErrorResponse
+// fetch makes the HTTP request, retrying failures classified as retryable,
+// and returns the body of the successful response
+func (c *RESTClient) fetch(ctx context.Context, URL string) ([]byte, error) {
+	encodedURL := strings.ReplaceAll(URL, " ", "%20")
+	delay := c.RetryInterval
 
-		if err := json.Unmarshal(body, &errorResponse); err == nil {
-			return nil, fmt.Errorf("API request failed with status code %d: %s", resp.StatusCode, string(body))
+	for attempt := 0; ; attempt++ {
+		body, err := c.get(ctx, encodedURL)
+		if err == nil {
+			return body, nil
 		}
-		return nil, fmt.Errorf("API request failed with status code %d: %v", resp.StatusCode, errorResponse.Errors)
-	}
-
-	// Check if the status code is not OK
-	var errorResponse ErrorResponseOK
-	if err := json.Unmarshal(body, &errorResponse); err == nil {
-		// If the error field is not empty, return it as an error
-		if errorResponse.Error != 0 {
-			return nil, fmt.Errorf("API error: %d - %s", errorResponse.Error, errorResponse.Message)
+		if attempt >= c.MaxRetries || !IsRetryable(err) {
+			return nil, err
+		}
+		select {
+		case <-clock.OrDefault(c.Clock).After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
 		}
+		delay *= 2
 	}
+}
 
-	// Decode the successful response into the ConvertResponse struct
-	var convertResponse ConvertResponse
-	if err := json.Unmarshal(body, &convertResponse); err != nil {
-		return nil, fmt.Errorf("failed to parse successful response: %v", err)
+// get makes one request and returns the body of a successful response, or an
+// *APIError when the API reports a failure
+func (c *RESTClient) get(ctx context.Context, URL string) ([]byte, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	c.usage.record(URL, clock.OrDefault(c.Clock).Now())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, URL, nil)
+	if err != nil {
+		return nil, redact.Error(err)
+	}
+	var kept *validated
+	if c.ConditionalCacheSize > 0 {
+		if kept = c.validators.lookup(URL); kept != nil {
+			kept.addConditionalHeaders(req)
+		}
 	}
 
-	return &convertResponse, nil
-}
-
-// sendHistoricalRequest is a helper function to make the HTTP request and unmarshal the response
-func (c *RESTClient) sendHistoricalRequest(URL string, v interface{}) error {
-	encodedURL := strings.ReplaceAll(URL, " ", "%20")
-	resp, err := c.HTTPClient.Get(encodedURL)
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return err
+		return nil, redact.Error(err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && kept != nil {
+		return kept.body, nil
+	}
+
 	// Read the response body
 	var body []byte
 	if body, err = ioutil.ReadAll(resp.Body); err != nil {
-		return fmt.Errorf("failed to read response body: %v", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	// Check if the status code is not OK
-	if resp.StatusCode != http.StatusOK {
-		// Try to decode the error response
-		var errorResponse ErrorResponse
+	if err := c.checkResponse(resp.StatusCode, body); err != nil {
+		return nil, err
+	}
+	if c.ConditionalCacheSize > 0 {
+		c.validators.store(URL, resp.Header, body, c.ConditionalCacheSize)
+	}
+	return body, nil
+}
 
-		if err := json.Unmarshal(body, &errorResponse); err == nil {
-			return fmt.Errorf("API request failed with status code %d: %s", resp.StatusCode, string(body))
+// CheckResponse returns an *APIError if the status code or the body of an
+// API response reports a failure, including errors reported with status 200
+func CheckResponse(status int, body []byte) error {
+	return checkResponse(nil, status, body)
+}
 
-		}
-		return fmt.Errorf("not nil API request failed with status code %d: %v", resp.StatusCode, errorResponse.Errors)
+// checkResponse is CheckResponse with the client's codec
+func (c *RESTClient) checkResponse(status int, body []byte) error {
+	return checkResponse(c.Codec, status, body)
+}
 
-	}
-	var errorResponse ErrorResponseOK
-	if err := json.Unmarshal(body, &errorResponse); err == nil {
-		// If the error field is not empty, return it as an error
-		if errorResponse.Error != 0 {
-			return fmt.Errorf("API error: %d - %s", errorResponse.Error, errorResponse.Message)
+func checkResponse(cd codec.Codec, status int, body []byte) error {
+	// Check if the status code is not OK
+	if status != http.StatusOK {
+		apiErr := &APIError{StatusCode: status, Message: string(body)}
+		var errorResponse ErrorResponse
+		if err := codec.Lenient(cd).Unmarshal(body, &errorResponse); err == nil && (errorResponse.Message != "" || len(errorResponse.Errors) > 0) {
+			apiErr.Message = errorResponse.Message
+			apiErr.Errors = errorResponse.Errors
 		}
+		apiErr.ErrorCode = classifyAPIError(apiErr)
+		return apiErr
 	}
 
-	// Decode the successful response into the provided interface (v)
-	if err := json.Unmarshal(body, v); err != nil {
-		return fmt.Errorf("failed to parse successful response: %v", err)
+	// Check if the response contains an error message even with a 200 status code
+	var errorResponse ErrorResponseOK
+	if err := codec.Lenient(cd).Unmarshal(body, &errorResponse); err == nil && errorResponse.Error != 0 {
+		apiErr := &APIError{StatusCode: status, Code: errorResponse.Error, Message: errorResponse.Message}
+		apiErr.ErrorCode = classifyAPIError(apiErr)
+		return apiErr
 	}
-
 	return nil
 }
 
+// ParseDate parses the date and date-time layouts returned by the historical and timeseries endpoints as UTC
+func ParseDate(date string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02-15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date format: %q", date)
+}
+
 // Helper function to join currency pairs into a single string
 func joinStrings(strs []string) string {
 	result := ""
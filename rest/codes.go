@@ -0,0 +1,175 @@
+package tradermade
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ErrorCode identifies the cause of a failed request independently of the
+// API's wording, so callers can branch on it instead of matching messages.
+// It is an error, so errors.Is(err, tradermade.CodeInvalidAPIKey) works on
+// anything the client returns.
+type ErrorCode string
+
+const (
+	CodeUnknown          ErrorCode = ""                   // The cause is not recognised, see APIError.Message
+	CodeInvalidAPIKey    ErrorCode = "invalid_api_key"    // The API key is missing or wrong
+	CodeNotEntitled      ErrorCode = "not_entitled"       // The plan does not include the endpoint or data requested
+	CodeQuotaExceeded    ErrorCode = "quota_exceeded"     // The plan's monthly request allowance is used up
+	CodeRateLimited      ErrorCode = "rate_limited"       // Too many requests in a short time
+	CodePairNotSupported ErrorCode = "pair_not_supported" // A currency, pair or instrument is invalid or not available
+	CodeDateOutOfRange   ErrorCode = "date_out_of_range"  // A date is outside the available history or the allowed range
+	CodeInvalidDate      ErrorCode = "invalid_date"       // A date is malformed
+	CodeInvalidInterval  ErrorCode = "invalid_interval"   // The interval or period is not supported
+	CodeNoData           ErrorCode = "no_data"            // The request was valid but no data is available
+	CodeBadRequest       ErrorCode = "bad_request"        // The request was rejected for another reason
+	CodeNotFound         ErrorCode = "not_found"          // The endpoint does not exist
+	CodeServerError      ErrorCode = "server_error"       // The API failed to handle the request
+	CodeUnavailable      ErrorCode = "unavailable"        // The API is temporarily unavailable
+)
+
+func (c ErrorCode) Error() string {
+	if c == CodeUnknown {
+		return "unknown API error"
+	}
+	return string(c)
+}
+
+// errorCodeRules recognise causes from the words of an error message. Rules
+// are tried in order and every word of a rule must appear.
+var errorCodeRules = []struct {
+	code  ErrorCode
+	words []string
+}{
+	{CodeInvalidAPIKey, []string{"key", "invalid"}},
+	{CodeInvalidAPIKey, []string{"key", "wrong"}},
+	{CodeInvalidAPIKey, []string{"key", "incorrect"}},
+	{CodeInvalidAPIKey, []string{"key", "missing"}},
+	{CodeQuotaExceeded, []string{"limit", "reached"}},
+	{CodeQuotaExceeded, []string{"quota"}},
+	{CodeQuotaExceeded, []string{"limit", "exceeded"}},
+	{CodeRateLimited, []string{"too many"}},
+	{CodeNotEntitled, []string{"not authorized"}},
+	{CodeNotEntitled, []string{"not authorised"}},
+	{CodeNotEntitled, []string{"upgrade"}},
+	{CodeNotEntitled, []string{"not available", "plan"}},
+	{CodeNoData, []string{"no data"}},
+	{CodeDateOutOfRange, []string{"date", "range"}},
+	{CodeDateOutOfRange, []string{"date", "before"}},
+	{CodeDateOutOfRange, []string{"date", "after"}},
+	{CodeDateOutOfRange, []string{"date", "future"}},
+	{CodeDateOutOfRange, []string{"date", "older"}},
+	{CodeInvalidDate, []string{"date"}},
+	{CodeInvalidInterval, []string{"interval"}},
+	{CodeInvalidInterval, []string{"period"}},
+	{CodePairNotSupported, []string{"currency"}},
+	{CodePairNotSupported, []string{"symbol"}},
+	{CodePairNotSupported, []string{"pair"}},
+	{CodePairNotSupported, []string{"instrument"}},
+}
+
+// classifyAPIError derives the error code from the status, the reported code
+// and the message
+func classifyAPIError(e *APIError) ErrorCode {
+	status := e.StatusCode
+	if e.Code != 0 {
+		status = e.Code
+	}
+	switch status {
+	case http.StatusUnauthorized:
+		return CodeInvalidAPIKey
+	case http.StatusTooManyRequests:
+		if code := matchErrorCode(e.messages()); code == CodeRateLimited {
+			return code
+		}
+		return CodeQuotaExceeded
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return CodeUnavailable
+	}
+	if status >= 500 {
+		return CodeServerError
+	}
+
+	if code := matchErrorCode(e.messages()); code != CodeUnknown {
+		return code
+	}
+	switch status {
+	case http.StatusForbidden:
+		return CodeNotEntitled
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return CodeBadRequest
+	}
+	return CodeUnknown
+}
+
+// matchErrorCode returns the first rule matching the message
+func matchErrorCode(message string) ErrorCode {
+	message = strings.ToLower(message)
+	for _, rule := range errorCodeRules {
+		matched := true
+		for _, word := range rule.words {
+			if !strings.Contains(message, word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return rule.code
+		}
+	}
+	return CodeUnknown
+}
+
+// messages returns the message and the field errors as one string
+func (e *APIError) messages() string {
+	parts := []string{e.Message}
+	keys := make([]string, 0, len(e.Errors))
+	for key := range e.Errors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s %v", key, e.Errors[key]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Is reports whether target is the error's code, so errors.Is(err, CodeNoData)
+// matches API errors with that code
+func (e *APIError) Is(target error) bool {
+	code, ok := target.(ErrorCode)
+	return ok && code != CodeUnknown && code == e.ErrorCode
+}
+
+// Is matches CodeDateOutOfRange, the code the API would have returned
+func (e *RangeError) Is(target error) bool {
+	return target == CodeDateOutOfRange
+}
+
+// Is matches CodePairNotSupported, the code the API would have returned
+func (e *CurrencyError) Is(target error) bool {
+	return target == CodePairNotSupported
+}
+
+// Code returns the error code of an error returned by the client, or
+// CodeUnknown if it has none
+func Code(err error) ErrorCode {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode
+	}
+	var rangeErr *RangeError
+	if errors.As(err, &rangeErr) {
+		return CodeDateOutOfRange
+	}
+	var currencyErr *CurrencyError
+	if errors.As(err, &currencyErr) {
+		return CodePairNotSupported
+	}
+	return CodeUnknown
+}
@@ -0,0 +1,75 @@
+package tradermade
+
+import (
+	"net/http"
+	"sync"
+)
+
+// validatorCache keeps the bodies of responses that carried an ETag or
+// Last-Modified header, so repeated requests can be made conditional
+type validatorCache struct {
+	mu      sync.Mutex
+	entries map[string]*validated
+	order   []string // Insertion order, oldest first, for eviction
+}
+
+// validated is a response body with its cache validators
+type validated struct {
+	etag         string
+	lastModified string
+	body         []byte
+}
+
+// SetConditionalRequests keeps up to maxEntries responses that carry cache
+// validators and revalidates them with If-None-Match and If-Modified-Since,
+// returning the kept body when the API answers 304 Not Modified. Zero
+// disables conditional requests and drops the kept responses.
+func (c *RESTClient) SetConditionalRequests(maxEntries int) {
+	c.ConditionalCacheSize = maxEntries
+	if maxEntries <= 0 {
+		c.validators.mu.Lock()
+		c.validators.entries = nil
+		c.validators.order = nil
+		c.validators.mu.Unlock()
+	}
+}
+
+// lookup returns the kept response for URL, if any
+func (vc *validatorCache) lookup(URL string) *validated {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+	return vc.entries[URL]
+}
+
+// addConditionalHeaders sets the validators of a kept response on req
+func (v *validated) addConditionalHeaders(req *http.Request) {
+	if v.etag != "" {
+		req.Header.Set("If-None-Match", v.etag)
+	}
+	if v.lastModified != "" {
+		req.Header.Set("If-Modified-Since", v.lastModified)
+	}
+}
+
+// store keeps body if header has validators, evicting the oldest entries
+// beyond max
+func (vc *validatorCache) store(URL string, header http.Header, body []byte, max int) {
+	etag, lastModified := header.Get("ETag"), header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return
+	}
+
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+	if vc.entries == nil {
+		vc.entries = make(map[string]*validated)
+	}
+	if _, ok := vc.entries[URL]; !ok {
+		vc.order = append(vc.order, URL)
+	}
+	vc.entries[URL] = &validated{etag: etag, lastModified: lastModified, body: body}
+	for len(vc.order) > max {
+		delete(vc.entries, vc.order[0])
+		vc.order = vc.order[1:]
+	}
+}
@@ -0,0 +1,116 @@
+package tradermade
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tradermade/Go-SDK/clock"
+	"github.com/tradermade/Go-SDK/internal/redact"
+)
+
+// TimeSeriesParams describes a timeseries request
+type TimeSeriesParams struct {
+	Currency string
+	Start    time.Time
+	End      time.Time
+	Interval string // "daily", "hourly" or "minute"
+	Period   int    // Bar length in hours or minutes, required for hourly and minute
+	Fields   Fields // Price fields to return, e.g. FieldClose; zero returns open, high, low and close
+}
+
+// GetTimeSeriesCSVTo requests the timeseries in CSV format and copies the
+// response to w as it arrives, without holding it in memory, and returns the
+// number of bytes written. Failures before the first byte is written are
+// retried like other requests. The HTTP client's Timeout would cut off long
+// downloads, so it does not apply here: bound the export with ctx instead.
+func (c *RESTClient) GetTimeSeriesCSVTo(ctx context.Context, w io.Writer, params TimeSeriesParams) (int64, error) {
+	daily := strings.ToLower(params.Interval) == "daily"
+	var period []int
+	if params.Period > 0 {
+		period = []int{params.Period}
+	}
+	URL, err := c.timeSeriesURL(params.Currency, formatTimeSeriesDate(params.Start, daily), formatTimeSeriesDate(params.End, daily), params.Interval, "csv", params.Fields, period...)
+	if err != nil {
+		return 0, err
+	}
+	URL = strings.ReplaceAll(URL, " ", "%20")
+
+	delay := c.RetryInterval
+	for attempt := 0; ; attempt++ {
+		var body io.ReadCloser
+		if body, err = c.stream(ctx, URL); err == nil {
+			defer body.Close()
+			n, err := io.Copy(w, body)
+			if err != nil {
+				return n, fmt.Errorf("failed to copy CSV response: %w", err)
+			}
+			return n, nil
+		}
+		if attempt >= c.MaxRetries || !IsRetryable(err) {
+			return 0, err
+		}
+		select {
+		case <-clock.OrDefault(c.Clock).After(delay):
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+		delay *= 2
+	}
+}
+
+// stream makes one request and returns the body of a successful response
+// unread, or an *APIError when the API reports a failure
+func (c *RESTClient) stream(ctx context.Context, URL string) (io.ReadCloser, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	c.usage.record(URL, clock.OrDefault(c.Clock).Now())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, URL, nil)
+	if err != nil {
+		return nil, redact.Error(err)
+	}
+	// Timeout covers reading the body too, ctx bounds the download instead
+	streaming := *c.HTTPClient
+	streaming.Timeout = 0
+	resp, err := streaming.Do(req)
+	if err != nil {
+		return nil, redact.Error(err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		return nil, c.checkResponse(resp.StatusCode, body)
+	}
+
+	// Errors are reported as JSON even with a 200 status code, CSV never starts with "{"
+	reader := bufio.NewReader(resp.Body)
+	if start, _ := reader.Peek(1); bytes.Equal(start, []byte("{")) {
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		if err := c.checkResponse(resp.StatusCode, body); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("expected a CSV response, got %.100s", body)
+	}
+	return readCloser{reader, resp.Body}, nil
+}
+
+// readCloser reads from a buffered reader and closes the underlying body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
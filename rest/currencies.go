@@ -0,0 +1,116 @@
+package tradermade
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/tradermade/Go-SDK/currency"
+)
+
+// ErrUnsupportedCurrency is wrapped by the error returned for currency codes
+// that are malformed or not supported by the API
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// CurrencyError reports an invalid currency code with likely corrections
+type CurrencyError struct {
+	Code        string
+	Suggestions []string // Known codes close to Code, e.g. "GBP" for "GPB"
+}
+
+func (e *CurrencyError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("%v: %q", ErrUnsupportedCurrency, e.Code)
+	}
+	return fmt.Sprintf("%v: %q, did you mean %s?", ErrUnsupportedCurrency, e.Code, strings.Join(e.Suggestions, " or "))
+}
+
+func (e *CurrencyError) Unwrap() error {
+	return ErrUnsupportedCurrency
+}
+
+// CurrencyList is the response of the live currencies list endpoint
+type CurrencyList struct {
+	Currencies map[string]string `json:"available_currencies"` // Name by code
+	Endpoint   string            `json:"endpoint"`
+}
+
+// supportedCurrencies holds the codes loaded by LoadSupportedCurrencies
+type supportedCurrencies struct {
+	mu    sync.RWMutex
+	codes map[string]bool
+}
+
+// GetCurrencyList fetches the currencies supported by the API
+func (c *RESTClient) GetCurrencyList() (*CurrencyList, error) {
+	URL := fmt.Sprintf("%s/live_currencies_list?api_key=%s", c.baseURL(), c.APIKey)
+	var list CurrencyList
+	if err := c.sendRequest(URL, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// LoadSupportedCurrencies fetches the supported currency list once, after
+// which ConvertCurrency also rejects codes the API does not support
+func (c *RESTClient) LoadSupportedCurrencies() error {
+	list, err := c.GetCurrencyList()
+	if err != nil {
+		return err
+	}
+	codes := make(map[string]bool, len(list.Currencies))
+	for code := range list.Currencies {
+		codes[strings.ToUpper(code)] = true
+	}
+	c.supported.mu.Lock()
+	c.supported.codes = codes
+	c.supported.mu.Unlock()
+	return nil
+}
+
+// ValidateCurrency checks that code looks like a currency code: three
+// letters in any case. Once LoadSupportedCurrencies has been called it must
+// also be a code supported by the API. Codes missing from the currency
+// package's table, such as crypto codes, are left to the API until then. It
+// returns a *CurrencyError wrapping ErrUnsupportedCurrency otherwise.
+func (c *RESTClient) ValidateCurrency(code string) error {
+	c.supported.mu.RLock()
+	supported := c.supported.codes
+	c.supported.mu.RUnlock()
+
+	upper := strings.ToUpper(code)
+	if supported != nil {
+		if supported[upper] {
+			return nil
+		}
+	} else if isCurrencyCode(code) {
+		return nil
+	}
+	return &CurrencyError{Code: code, Suggestions: currency.Suggest(code)}
+}
+
+// isCurrencyCode reports whether code is three ASCII letters
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+// RoundedTotal returns Total rounded to the minor units of the target
+// currency, e.g. cents for USD, see currency.RoundWith
+func (r *ConvertResponse) RoundedTotal(mode currency.RoundingMode) float64 {
+	return currency.RoundWith(r.Total, r.QuoteCurrency, mode)
+}
+
+// FormatTotal writes Total in the target currency for a locale, e.g.
+// "1.234,56 €" for de-DE, see currency.Format
+func (r *ConvertResponse) FormatTotal(locale string) string {
+	return currency.Format(r.Total, r.QuoteCurrency, locale)
+}
@@ -0,0 +1,90 @@
+package tradermade
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/tradermade/Go-SDK/internal/ratelimit"
+)
+
+// Do calls any API endpoint, such as one the SDK has no method for yet, with
+// the same handling as the built-in methods: the API key is added, params are
+// URL encoded, failures are returned as *APIError and retried if retryable,
+// and RateLimit applies. path is relative to BaseURL, e.g. "live" or
+// "timeseries". The response is decoded into out; a *[]byte receives the raw
+// body and nil discards it.
+//
+//	var rates tradermade.LiveRate
+//	err := client.Do(ctx, "live", url.Values{"currency": {"EURUSD"}}, &rates)
+func (c *RESTClient) Do(ctx context.Context, path string, params url.Values, out interface{}) error {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return fmt.Errorf("endpoint path required")
+	}
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = append([]string(nil), values...)
+	}
+	if currency := query.Get("currency"); currency != "" {
+		resolved, err := c.resolveSymbols(currency)
+		if err != nil {
+			return err
+		}
+		query.Set("currency", resolved)
+	}
+	query.Set("api_key", c.APIKey)
+
+	body, err := c.fetch(ctx, c.baseURL()+"/"+path+"?"+query.Encode())
+	if err != nil {
+		return err
+	}
+	switch out := out.(type) {
+	case nil:
+		return nil
+	case *[]byte:
+		*out = body
+		return nil
+	}
+	if err := c.decode(body, out); err != nil {
+		return fmt.Errorf("failed to parse successful response: %w", err)
+	}
+	return nil
+}
+
+// SetRateLimit limits the client to requestsPerSecond requests, allowing
+// bursts of up to burst, across all methods and retries. Requests wait for
+// their turn. Zero disables limiting.
+func (c *RESTClient) SetRateLimit(requestsPerSecond float64, burst int) {
+	c.limiter.mu.Lock()
+	defer c.limiter.mu.Unlock()
+	c.RateLimit = requestsPerSecond
+	c.RateBurst = burst
+}
+
+// limiter holds the token bucket behind RateLimit, created on first use
+type limiter struct {
+	mu     sync.Mutex
+	bucket *ratelimit.Limiter
+	rate   float64 // RateLimit the bucket was created with
+	burst  int     // RateBurst the bucket was created with
+}
+
+// wait blocks until RateLimit allows another request or ctx is done
+func (c *RESTClient) wait(ctx context.Context) error {
+	l := &c.limiter
+	l.mu.Lock()
+	if c.RateLimit <= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	if l.bucket == nil || l.rate != c.RateLimit || l.burst != c.RateBurst {
+		l.bucket = ratelimit.New(c.RateLimit, c.RateBurst)
+		l.rate, l.burst = c.RateLimit, c.RateBurst
+	}
+	bucket := l.bucket
+	l.mu.Unlock()
+	return bucket.Wait(ctx)
+}
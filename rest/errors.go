@@ -0,0 +1,124 @@
+package tradermade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// ErrorClass tells whether a failed request is worth retrying
+type ErrorClass int
+
+const (
+	ClassOther       ErrorClass = iota // Unclassified failure, not retried
+	ClassBadRequest                    // The request was rejected as invalid (400, 404, 422)
+	ClassAuth                          // The API key is missing, invalid or not entitled (401, 403)
+	ClassQuota                         // The plan's request quota is used up (429)
+	ClassServer                        // The API failed to handle the request (500 and other 5xx)
+	ClassUnavailable                   // The API is temporarily unavailable (502, 503, 504)
+	ClassNetwork                       // Timeouts, connection resets and other transport failures
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ClassBadRequest:
+		return "bad_request"
+	case ClassAuth:
+		return "auth"
+	case ClassQuota:
+		return "quota"
+	case ClassServer:
+		return "server"
+	case ClassUnavailable:
+		return "unavailable"
+	case ClassNetwork:
+		return "network"
+	default:
+		return "other"
+	}
+}
+
+// Retryable reports whether requests failing with this class may succeed if repeated
+func (c ErrorClass) Retryable() bool {
+	return c == ClassUnavailable || c == ClassNetwork
+}
+
+// APIError is returned when the API rejects a request, either with an error
+// status or with an error reported in a 200 response
+type APIError struct {
+	StatusCode int                    // HTTP status code
+	Code       int                    // Numeric error code reported in a 200 response, zero otherwise
+	Message    string                 // Error message, or the raw body if it could not be decoded
+	Errors     map[string]interface{} // Field errors, if any
+	ErrorCode  ErrorCode              // Cause of the failure, CodeUnknown if not recognised
+}
+
+func (e *APIError) Error() string {
+	if e.Code != 0 {
+		return fmt.Sprintf("API error: %d - %s", e.Code, e.Message)
+	}
+	if len(e.Errors) > 0 {
+		return fmt.Sprintf("API request failed with status code %d: %s", e.StatusCode, formatErrorMap(e.Errors))
+	}
+	return fmt.Sprintf("API request failed with status code %d: %s", e.StatusCode, e.Message)
+}
+
+// Class classifies the error by its status code, or by the reported code for
+// errors in a 200 response
+func (e *APIError) Class() ErrorClass {
+	status := e.StatusCode
+	if e.Code != 0 {
+		status = e.Code
+	}
+	switch status {
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity:
+		return ClassBadRequest
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ClassAuth
+	case http.StatusTooManyRequests:
+		return ClassQuota
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return ClassUnavailable
+	}
+	if status >= 500 {
+		return ClassServer
+	}
+	return ClassOther
+}
+
+// Retryable reports whether the request may succeed if repeated
+func (e *APIError) Retryable() bool {
+	return e.Class().Retryable()
+}
+
+// Classify returns the class of an error returned by the client
+func Classify(err error) ErrorClass {
+	if err == nil {
+		return ClassOther
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Class()
+	}
+	if errors.Is(err, context.Canceled) {
+		return ClassOther
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ClassNetwork
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return ClassNetwork
+	}
+	return ClassOther
+}
+
+// IsRetryable reports whether a request failing with err may succeed if repeated
+func IsRetryable(err error) bool {
+	return Classify(err).Retryable()
+}
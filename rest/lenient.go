@@ -0,0 +1,193 @@
+package tradermade
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/tradermade/Go-SDK/codec"
+)
+
+// Fields is a set of numeric response fields. Responses mark the fields that
+// were null or missing in their Absent set, telling them apart from real zeros.
+type Fields uint16
+
+const (
+	FieldBid Fields = 1 << iota
+	FieldAsk
+	FieldMid
+	FieldOpen
+	FieldHigh
+	FieldLow
+	FieldClose
+	FieldQuote
+	FieldTotal
+)
+
+// fieldsByName maps JSON names to fields
+var fieldsByName = map[string]Fields{
+	"bid":   FieldBid,
+	"ask":   FieldAsk,
+	"mid":   FieldMid,
+	"open":  FieldOpen,
+	"high":  FieldHigh,
+	"low":   FieldLow,
+	"close": FieldClose,
+	"quote": FieldQuote,
+	"total": FieldTotal,
+}
+
+// Has reports whether all of the given fields are in the set, e.g.
+// quote.Absent.Has(tradermade.FieldBid)
+func (f Fields) Has(fields Fields) bool {
+	return f&fields == fields
+}
+
+// Any reports whether the set is not empty
+func (f Fields) Any() bool {
+	return f != 0
+}
+
+// ohlcFields are the fields a timeseries request can select, in the order
+// they are listed in its fields parameter
+var ohlcFields = []struct {
+	field Fields
+	name  string
+}{{FieldOpen, "open"}, {FieldHigh, "high"}, {FieldLow, "low"}, {FieldClose, "close"}}
+
+// timeSeriesParam returns the fields parameter of a timeseries request
+// selecting f, empty when f is empty
+func (f Fields) timeSeriesParam() (string, error) {
+	var names []string
+	for _, ohlc := range ohlcFields {
+		if f.Has(ohlc.field) {
+			names = append(names, ohlc.name)
+			f &^= ohlc.field
+		}
+	}
+	if f != 0 {
+		return "", fmt.Errorf("timeseries requests can only select open, high, low and close fields")
+	}
+	return strings.Join(names, ","), nil
+}
+
+var fieldsType = reflect.TypeOf(Fields(0))
+
+// markAbsent sets the Absent field of every struct in v whose numeric fields
+// were null or missing in body. v has already been decoded from body by cd,
+// which decodes it again into a generic value to see which keys are present.
+// Types without an Absent field are skipped.
+func markAbsent(cd codec.Codec, body []byte, v any) error {
+	if v == nil || !hasAbsent(reflect.TypeOf(v)) {
+		return nil
+	}
+	var raw any
+	if err := codec.Lenient(cd).Unmarshal(body, &raw); err != nil {
+		return nil // The codec accepted it, so leave the decoded value as is
+	}
+	walkAbsent(raw, reflect.ValueOf(v))
+	return nil
+}
+
+// absentTypes caches hasAbsent by type
+var absentTypes sync.Map
+
+// hasAbsent reports whether values of t can contain a struct with an Absent
+// field that walkAbsent would set
+func hasAbsent(t reflect.Type) bool {
+	if has, ok := absentTypes.Load(t); ok {
+		return has.(bool)
+	}
+	has := findAbsent(t, map[reflect.Type]bool{})
+	absentTypes.Store(t, has)
+	return has
+}
+
+func findAbsent(t reflect.Type, visited map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Interface {
+		return true // Decided by the dynamic value
+	}
+	if t.Kind() != reflect.Struct || visited[t] {
+		return false
+	}
+	visited[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		if sf.Type == fieldsType && sf.Name == "Absent" {
+			return true
+		}
+		if findAbsent(sf.Type, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+func walkAbsent(raw any, v reflect.Value) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		items, ok := raw.([]any)
+		if !ok {
+			return
+		}
+		for i := 0; i < v.Len() && i < len(items); i++ {
+			walkAbsent(items[i], v.Index(i))
+		}
+	case reflect.Struct:
+		object, ok := raw.(map[string]any)
+		if !ok {
+			return
+		}
+		var absent Fields
+		var absentField reflect.Value
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			if sf.Type == fieldsType && sf.Name == "Absent" {
+				absentField = v.Field(i)
+				continue
+			}
+			name := strings.Split(sf.Tag.Get("json"), ",")[0]
+			if name == "-" {
+				continue
+			}
+			if sf.Anonymous && name == "" {
+				walkAbsent(raw, v.Field(i)) // Embedded structs share the object
+				continue
+			}
+			if name == "" {
+				name = sf.Name
+			}
+			value, present := object[name]
+			if field, numeric := fieldsByName[name]; numeric && sf.Type.Kind() == reflect.Float64 {
+				if !present || value == nil {
+					absent |= field
+				}
+				continue
+			}
+			if present {
+				walkAbsent(value, v.Field(i))
+			}
+		}
+		if absentField.IsValid() && absentField.CanSet() {
+			absentField.SetUint(uint64(absent))
+		}
+	}
+}
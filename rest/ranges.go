@@ -0,0 +1,117 @@
+package tradermade
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxTimeSeriesRange is the longest range the timeseries endpoint accepts per
+// request for each interval. Requests outside it fail with a RangeError
+// before being sent.
+var MaxTimeSeriesRange = map[string]time.Duration{
+	"minute": 2 * 24 * time.Hour,
+	"hourly": 31 * 24 * time.Hour,
+	"daily":  366 * 24 * time.Hour,
+}
+
+// RangeError reports a timeseries range the API would reject
+type RangeError struct {
+	Interval string
+	Start    time.Time
+	End      time.Time
+	Max      time.Duration // Longest range allowed for Interval, zero when the range is inverted
+}
+
+func (e *RangeError) Error() string {
+	if e.Max == 0 {
+		return fmt.Sprintf("invalid %s range: end %s is before start %s", e.Interval, e.End.Format(time.RFC3339), e.Start.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("%s range of %s exceeds the maximum of %s per request, use GetTimeSeriesDataSplit to fetch it in parts",
+		e.Interval, formatDays(e.End.Sub(e.Start)), formatDays(e.Max))
+}
+
+// formatDays formats d in days, or hours below a day
+func formatDays(d time.Duration) string {
+	if d < 24*time.Hour {
+		return d.Round(time.Minute).String()
+	}
+	return fmt.Sprintf("%.4g days", d.Hours()/24)
+}
+
+// ValidateTimeSeriesRange checks that start and end are in order and within
+// MaxTimeSeriesRange for the interval
+func ValidateTimeSeriesRange(start, end time.Time, interval string) error {
+	interval = strings.ToLower(interval)
+	if end.Before(start) {
+		return &RangeError{Interval: interval, Start: start, End: end}
+	}
+	if max, ok := MaxTimeSeriesRange[interval]; ok && end.Sub(start) > max {
+		return &RangeError{Interval: interval, Start: start, End: end, Max: max}
+	}
+	return nil
+}
+
+// validateTimeSeriesDates validates the string bounds passed to
+// GetTimeSeriesData. Dates the SDK cannot parse are left for the API to reject.
+func validateTimeSeriesDates(startDate, endDate, interval string) error {
+	start, err := ParseDate(startDate)
+	if err != nil {
+		return nil
+	}
+	end, err := ParseDate(endDate)
+	if err != nil {
+		return nil
+	}
+	return ValidateTimeSeriesRange(start, end, interval)
+}
+
+// GetTimeSeriesDataSplit fetches a range of any length by splitting it into
+// requests within MaxTimeSeriesRange and merging their quotes in order
+func (c *RESTClient) GetTimeSeriesDataSplit(currency string, start, end time.Time, interval string, period ...int) (*TimeSeriesRate, error) {
+	return c.timeSeriesSplit(context.Background(), currency, start, end, interval, 0, period...)
+}
+
+// timeSeriesSplit is GetTimeSeriesDataSplit with a context
+func (c *RESTClient) timeSeriesSplit(ctx context.Context, currency string, start, end time.Time, interval string, fields Fields, period ...int) (*TimeSeriesRate, error) {
+	if end.Before(start) {
+		return nil, &RangeError{Interval: strings.ToLower(interval), Start: start, End: end}
+	}
+	max, ok := MaxTimeSeriesRange[strings.ToLower(interval)]
+	if !ok || max <= 0 {
+		return c.timeSeriesRange(ctx, currency, start, end, interval, fields, period...)
+	}
+
+	var merged *TimeSeriesRate
+	seen := make(map[string]bool)
+	for from := start; ; {
+		to := from.Add(max)
+		if to.After(end) {
+			to = end
+		}
+		part, err := c.timeSeriesRange(ctx, currency, from, to, interval, fields, period...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s to %s: %w", from.Format(time.RFC3339), to.Format(time.RFC3339), err)
+		}
+
+		if merged == nil {
+			first := *part
+			first.Quotes = nil
+			merged = &first
+		}
+		merged.EndDate = part.EndDate
+		for _, q := range part.Quotes {
+			// Consecutive parts share their boundary bar
+			if !seen[q.Date] {
+				seen[q.Date] = true
+				merged.Quotes = append(merged.Quotes, q)
+			}
+		}
+
+		if !to.Before(end) {
+			return merged, nil
+		}
+		from = to
+	}
+}
@@ -0,0 +1,33 @@
+package tradermade
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/tradermade/Go-SDK/internal/redact"
+)
+
+// RedactURL replaces the API key in a request URL, or in a text containing
+// one, so it can be logged safely
+func RedactURL(s string) string {
+	return redact.URL(s)
+}
+
+// String describes the client with its API key redacted, so printing the
+// client with %v or %+v does not leak the key
+func (c *RESTClient) String() string {
+	return fmt.Sprintf("tradermade.RESTClient{BaseURL: %q, APIKey: %q}", c.baseURL(), redact.Key(c.APIKey))
+}
+
+// GoString is String, covering %#v
+func (c *RESTClient) GoString() string {
+	return c.String()
+}
+
+// LogValue describes the client to log/slog with its API key redacted
+func (c *RESTClient) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("base_url", c.baseURL()),
+		slog.String("api_key", redact.Key(c.APIKey)),
+	)
+}
@@ -0,0 +1,26 @@
+package tradermade
+
+// WithAPIKey returns a client that sends apiKey instead of c's key. It shares
+// c's HTTP client, and with it the connection pool, and copies its settings,
+// so services serving several customers can create one per request cheaply.
+// Usage is counted, and RateLimit applied, separately for each returned
+// client, as the API limits each key on its own.
+func (c *RESTClient) WithAPIKey(apiKey string) *RESTClient {
+	tenant := &RESTClient{
+		APIKey:               apiKey,
+		BaseURL:              c.BaseURL,
+		HTTPClient:           c.HTTPClient,
+		Codec:                c.Codec,
+		SymbolResolver:       c.SymbolResolver,
+		MaxRetries:           c.MaxRetries,
+		RetryInterval:        c.RetryInterval,
+		RateLimit:            c.RateLimit,
+		RateBurst:            c.RateBurst,
+		ConditionalCacheSize: c.ConditionalCacheSize,
+		Clock:                c.Clock,
+	}
+	c.supported.mu.RLock()
+	tenant.supported.codes = c.supported.codes // Never modified after loading
+	c.supported.mu.RUnlock()
+	return tenant
+}
@@ -0,0 +1,135 @@
+package tradermade
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ZonedTimeSeries is a timeseries response with every quote's date converted
+// to the requested time zone
+type ZonedTimeSeries struct {
+	*TimeSeriesRate
+	Location *time.Location
+	Quotes   []ZonedQuote // Shadows TimeSeriesRate.Quotes
+}
+
+// ZonedQuote is a timeseries quote with its date parsed in the requested zone
+type ZonedQuote struct {
+	TimeSeriesQuote
+	Time time.Time
+}
+
+// ZonedHistorical is a historical rate with its time in the requested zone
+type ZonedHistorical struct {
+	Result   interface{} // *HistoricalData for minute and hour, *HistoricalRate for day
+	Time     time.Time   // Time of the rate in Location
+	Location *time.Location
+}
+
+// GetTimeSeriesIn fetches time series data between start and end and
+// annotates each quote with its time in loc, nil meaning UTC. Intraday
+// bounds are converted to UTC for the API; daily bounds use the calendar
+// date in loc.
+func (c *RESTClient) GetTimeSeriesIn(currency string, start, end time.Time, loc *time.Location, interval string, period ...int) (*ZonedTimeSeries, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	daily := strings.ToLower(interval) == "daily"
+	rates, err := c.GetTimeSeriesDataRange(currency, start.In(loc), end.In(loc), interval, period...)
+	if err != nil {
+		return nil, err
+	}
+
+	zoned := &ZonedTimeSeries{TimeSeriesRate: rates, Location: loc, Quotes: make([]ZonedQuote, 0, len(rates.Quotes))}
+	for _, q := range rates.Quotes {
+		t, err := parseDateIn(q.Date, loc, daily)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse quote date: %w", err)
+		}
+		zoned.Quotes = append(zoned.Quotes, ZonedQuote{TimeSeriesQuote: q, Time: t})
+	}
+	return zoned, nil
+}
+
+// GetHistoricalRatesIn fetches the historical rate at t for the interval
+// ("minute", "hour" or "day") and returns it with its time in loc, nil
+// meaning UTC. Minute and hour times are converted to UTC for the API; day
+// requests use the calendar date in loc.
+func (c *RESTClient) GetHistoricalRatesIn(currency string, t time.Time, loc *time.Location, interval string) (*ZonedHistorical, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	result, err := c.GetHistoricalRatesAt(currency, t.In(loc), interval)
+	if err != nil {
+		return nil, err
+	}
+
+	zoned := &ZonedHistorical{Result: result, Location: loc}
+	switch r := result.(type) {
+	case *HistoricalData:
+		zoned.Time, err = parseDateIn(r.DateTime, loc, false)
+	case *HistoricalRate:
+		zoned.Time, err = parseDateIn(r.Date, loc, true)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rate date: %w", err)
+	}
+	return zoned, nil
+}
+
+// GetTimeSeriesDataRange is GetTimeSeriesData with time.Time bounds, formatted
+// as the endpoint expects. Intraday bounds are converted to UTC; daily bounds
+// use the calendar date in each time's own location.
+func (c *RESTClient) GetTimeSeriesDataRange(currency string, start, end time.Time, interval string, period ...int) (*TimeSeriesRate, error) {
+	return c.timeSeriesRange(context.Background(), currency, start, end, interval, 0, period...)
+}
+
+// timeSeriesRange is GetTimeSeriesDataRange with a context
+func (c *RESTClient) timeSeriesRange(ctx context.Context, currency string, start, end time.Time, interval string, fields Fields, period ...int) (*TimeSeriesRate, error) {
+	daily := strings.ToLower(interval) == "daily"
+	return c.timeSeries(ctx, currency, formatTimeSeriesDate(start, daily), formatTimeSeriesDate(end, daily), interval, fields, period...)
+}
+
+// GetHistoricalRatesAt is GetHistoricalRates with a time.Time, formatted as
+// the interval's endpoint expects. Minute and hour times are converted to UTC;
+// day requests use the calendar date in t's location.
+func (c *RESTClient) GetHistoricalRatesAt(currency string, t time.Time, interval string) (interface{}, error) {
+	return c.GetHistoricalRates(currency, formatHistoricalDate(t, interval), interval)
+}
+
+// formatTimeSeriesDate formats a timeseries bound: the date for daily data,
+// otherwise the UTC date and minute
+func formatTimeSeriesDate(t time.Time, daily bool) string {
+	if daily {
+		return t.Format("2006-01-02")
+	}
+	return t.UTC().Format("2006-01-02-15:04")
+}
+
+// formatHistoricalDate formats the date_time or date parameter of the
+// historical endpoints. Hour requests are truncated to the hour.
+func formatHistoricalDate(t time.Time, interval string) string {
+	switch interval {
+	case "day":
+		return t.Format("2006-01-02")
+	case "hour":
+		return t.UTC().Truncate(time.Hour).Format("2006-01-02-15:04")
+	default:
+		return t.UTC().Format("2006-01-02-15:04")
+	}
+}
+
+// parseDateIn parses a date returned by the API. Dates with a time of day are
+// UTC and converted to loc; calendar dates are taken as midnight in loc.
+func parseDateIn(date string, loc *time.Location, daily bool) (time.Time, error) {
+	t, err := ParseDate(date)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if daily {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc), nil
+	}
+	return t.In(loc), nil
+}
@@ -0,0 +1,53 @@
+package tradermade
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
+// TransportOptions tunes the connection pool of the client's HTTP transport
+type TransportOptions struct {
+	MaxIdleConns        int           // Idle connections kept across all hosts, zero means no limit
+	MaxIdleConnsPerHost int           // Idle connections kept per host, zero uses net/http's default of 2
+	MaxConnsPerHost     int           // Connections per host including active ones, zero means no limit
+	IdleConnTimeout     time.Duration // How long an idle connection is kept, zero means forever
+	DisableKeepAlives   bool          // Use a new connection for every request
+	DisableHTTP2        bool          // Stay on HTTP/1.1, spreading concurrent requests over several connections
+}
+
+// DefaultTransportOptions are used by NewRESTClient. They keep enough idle
+// connections for bursts of concurrent requests to reuse them.
+var DefaultTransportOptions = TransportOptions{
+	MaxIdleConns:        100,
+	MaxIdleConnsPerHost: 16,
+	IdleConnTimeout:     90 * time.Second,
+}
+
+// HighThroughputTransportOptions suit frequent polling and backfills with
+// many concurrent requests
+var HighThroughputTransportOptions = TransportOptions{
+	MaxIdleConns:        256,
+	MaxIdleConnsPerHost: 64,
+	IdleConnTimeout:     5 * time.Minute,
+}
+
+// NewTransport returns a copy of http.DefaultTransport with opts applied
+func NewTransport(opts TransportOptions) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = opts.MaxIdleConns
+	transport.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	transport.MaxConnsPerHost = opts.MaxConnsPerHost
+	transport.IdleConnTimeout = opts.IdleConnTimeout
+	transport.DisableKeepAlives = opts.DisableKeepAlives
+	if opts.DisableHTTP2 {
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+	return transport
+}
+
+// SetTransportOptions replaces the HTTP client's transport with one tuned by opts
+func (c *RESTClient) SetTransportOptions(opts TransportOptions) {
+	c.HTTPClient.Transport = NewTransport(opts)
+}
@@ -0,0 +1,157 @@
+package tradermade
+
+import (
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tradermade/Go-SDK/clock"
+)
+
+// Usage is a snapshot of the requests the client has sent to the API. Every
+// attempt counts, including retries and failed requests.
+type Usage struct {
+	Total      uint64            // Requests since the client was created
+	ByEndpoint map[string]uint64 // Requests per endpoint, e.g. "live" or "timeseries"
+	LastHour   uint64            // Requests in the last 60 minutes
+	LastDay    uint64            // Requests in the last 24 hours
+	ThisMonth  uint64            // Requests since the start of the current UTC month
+}
+
+// usageMinutes is the number of per-minute buckets kept for the rolling counts
+const usageMinutes = 24 * 60
+
+// usage holds the live counters behind Usage()
+type usage struct {
+	mu         sync.Mutex
+	total      uint64
+	byEndpoint map[string]uint64
+	minutes    [usageMinutes]uint64 // Requests per minute, indexed by Unix minute modulo usageMinutes
+	stamps     [usageMinutes]int64  // Unix minute each bucket counts
+	month      time.Time            // Start of the month counted by thisMonth
+	thisMonth  uint64
+}
+
+// record counts a request to URL at t
+func (u *usage) record(URL string, t time.Time) {
+	endpoint := endpointName(URL)
+	minute := t.Unix() / 60
+	month := time.Date(t.UTC().Year(), t.UTC().Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.byEndpoint == nil {
+		u.byEndpoint = make(map[string]uint64)
+	}
+	u.total++
+	u.byEndpoint[endpoint]++
+
+	i := minute % usageMinutes
+	if u.stamps[i] != minute {
+		u.stamps[i] = minute
+		u.minutes[i] = 0
+	}
+	u.minutes[i]++
+
+	if !month.Equal(u.month) {
+		u.month = month
+		u.thisMonth = 0
+	}
+	u.thisMonth++
+}
+
+// endpointName returns the last path element of a request URL
+func endpointName(URL string) string {
+	if parsed, err := url.Parse(URL); err == nil {
+		return path.Base(parsed.Path)
+	}
+	if i := strings.IndexByte(URL, '?'); i >= 0 {
+		URL = URL[:i]
+	}
+	return path.Base(URL)
+}
+
+// Usage returns a snapshot of the requests the client has sent
+func (c *RESTClient) Usage() Usage {
+	now := clock.OrDefault(c.Clock).Now()
+	minute := now.Unix() / 60
+	month := time.Date(now.UTC().Year(), now.UTC().Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	u := &c.usage
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	snapshot := Usage{Total: u.total, ByEndpoint: make(map[string]uint64, len(u.byEndpoint))}
+	for endpoint, n := range u.byEndpoint {
+		snapshot.ByEndpoint[endpoint] = n
+	}
+	for i, stamp := range u.stamps {
+		age := minute - stamp
+		if age < 0 || age >= usageMinutes {
+			continue
+		}
+		snapshot.LastDay += u.minutes[i]
+		if age < 60 {
+			snapshot.LastHour += u.minutes[i]
+		}
+	}
+	if month.Equal(u.month) {
+		snapshot.ThisMonth = u.thisMonth
+	}
+	return snapshot
+}
+
+// Plan describes an API plan's monthly request allowance
+type Plan struct {
+	Name            string
+	MonthlyRequests int // Requests allowed per calendar month, zero means unlimited
+	Used            int // Requests already used this month by other clients, e.g. from the dashboard
+}
+
+// Estimate is the projected effect of a job on a plan's monthly allowance
+type Estimate struct {
+	Requests  int  // Requests the job will make
+	Used      int  // Requests used this month before the job
+	Remaining int  // Requests left after the job, negative if it does not fit
+	Fits      bool // Whether the job fits in the allowance
+}
+
+// EstimateRequests projects whether a job making requests requests fits in
+// plan this month, counting the plan's Used requests and those this client
+// has sent since the start of the month
+func (c *RESTClient) EstimateRequests(plan Plan, requests int) Estimate {
+	used := plan.Used + int(c.Usage().ThisMonth)
+	estimate := Estimate{Requests: requests, Used: used, Fits: true}
+	if plan.MonthlyRequests > 0 {
+		estimate.Remaining = plan.MonthlyRequests - used - requests
+		estimate.Fits = estimate.Remaining >= 0
+	}
+	return estimate
+}
+
+// TimeSeriesRequests returns the number of requests GetTimeSeriesDataSplit
+// makes for each symbol to fetch start to end at the interval
+func TimeSeriesRequests(start, end time.Time, interval string) int {
+	max := MaxTimeSeriesRange[strings.ToLower(interval)]
+	if max <= 0 || !end.After(start) {
+		return 1
+	}
+	span := end.Sub(start)
+	n := int(span / max)
+	if span%max != 0 {
+		n++
+	}
+	return n
+}
+
+// HistoricalRequests returns the number of requests needed to fetch every
+// bar from start to end one at a time with GetHistoricalRates
+func HistoricalRequests(start, end time.Time, interval string) int {
+	step := map[string]time.Duration{"minute": time.Minute, "hour": time.Hour, "day": 24 * time.Hour}[interval]
+	if step == 0 || end.Before(start) {
+		return 0
+	}
+	return int(end.Sub(start)/step) + 1
+}
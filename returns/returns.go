@@ -0,0 +1,149 @@
+// Package returns turns candle closes into simple, log and cumulative return
+// series, the usual first step of quantitative analysis. Series of several
+// pairs are aligned on their common dates first, so their returns cover the
+// same periods:
+//
+//	aligned := returns.Align(eurusd, gbpusd)
+//	a, b := returns.Log(aligned[0]), returns.Log(aligned[1])
+package returns
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/tradermade/Go-SDK/candle"
+	tradermade "github.com/tradermade/Go-SDK/rest"
+)
+
+// Point is the return over the period ending at Time
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// Series is a return series in time order
+type Series struct {
+	Symbol string
+	Log    bool    // Values are log returns rather than simple returns
+	Points []Point // One per close after the first
+}
+
+// Simple returns the close-to-close returns close/previous - 1 of the candles
+func Simple(candles []candle.Candle) Series {
+	return compute(candles, false)
+}
+
+// Log returns the close-to-close log returns ln(close/previous) of the candles
+func Log(candles []candle.Candle) Series {
+	return compute(candles, true)
+}
+
+// FromTimeSeries returns the simple or log returns of a timeseries response
+func FromTimeSeries(rate *tradermade.TimeSeriesRate, log bool) (Series, error) {
+	candles, err := rate.Candles(0)
+	if err != nil {
+		return Series{}, err
+	}
+	return compute(candles, log), nil
+}
+
+// compute sorts the candles by time and returns the returns between
+// consecutive closes. Candles without a positive close, such as missing
+// data, are skipped.
+func compute(candles []candle.Candle, log bool) Series {
+	sorted := sortedCloses(candles)
+	s := Series{Log: log}
+	if len(sorted) > 0 {
+		s.Symbol = sorted[0].Symbol
+	}
+	for i := 1; i < len(sorted); i++ {
+		ratio := sorted[i].Close / sorted[i-1].Close
+		value := ratio - 1
+		if log {
+			value = math.Log(ratio)
+		}
+		s.Points = append(s.Points, Point{Time: sorted[i].Time, Value: value})
+	}
+	return s
+}
+
+// sortedCloses returns the candles with a positive close in time order, one
+// per time
+func sortedCloses(candles []candle.Candle) []candle.Candle {
+	sorted := make([]candle.Candle, 0, len(candles))
+	for _, c := range candles {
+		if c.Close > 0 {
+			sorted = append(sorted, c)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+	out := sorted[:0]
+	for i, c := range sorted {
+		if i > 0 && c.Time.Equal(sorted[i-1].Time) {
+			out[len(out)-1] = c // Keep the last candle of a repeated time
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// Cumulative returns the running total return since the start of the
+// series: compounded for simple returns, summed for log returns
+func (s Series) Cumulative() Series {
+	out := Series{Symbol: s.Symbol, Log: s.Log, Points: make([]Point, len(s.Points))}
+	total := 0.0
+	growth := 1.0
+	for i, p := range s.Points {
+		if s.Log {
+			total += p.Value
+		} else {
+			growth *= 1 + p.Value
+			total = growth - 1
+		}
+		out.Points[i] = Point{Time: p.Time, Value: total}
+	}
+	return out
+}
+
+// Values returns the return values in time order
+func (s Series) Values() []float64 {
+	values := make([]float64, len(s.Points))
+	for i, p := range s.Points {
+		values[i] = p.Value
+	}
+	return values
+}
+
+// Total returns the return over the whole series
+func (s Series) Total() float64 {
+	if len(s.Points) == 0 {
+		return 0
+	}
+	return s.Cumulative().Points[len(s.Points)-1].Value
+}
+
+// Align keeps the candles of each series whose times appear in every series,
+// in time order, so returns computed from them cover the same periods. A date
+// missing from one pair, such as a holiday, is dropped from all of them.
+func Align(series ...[]candle.Candle) [][]candle.Candle {
+	counts := make(map[int64]int)
+	sorted := make([][]candle.Candle, len(series))
+	for i, candles := range series {
+		sorted[i] = sortedCloses(candles)
+		for _, c := range sorted[i] {
+			counts[c.Time.UnixNano()]++
+		}
+	}
+	aligned := make([][]candle.Candle, len(series))
+	for i, candles := range sorted {
+		aligned[i] = make([]candle.Candle, 0, len(candles))
+		for _, c := range candles {
+			if counts[c.Time.UnixNano()] == len(series) {
+				aligned[i] = append(aligned[i], c)
+			}
+		}
+	}
+	return aligned
+}
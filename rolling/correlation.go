@@ -0,0 +1,209 @@
+package rolling
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Correlation describes how two symbols' mids moved together over a window
+type Correlation struct {
+	Symbol      string
+	Benchmark   string
+	Window      time.Duration
+	Time        time.Time // End of the latest sample
+	Samples     int       // Return pairs in the window
+	Correlation float64   // Pearson correlation of the log returns, between -1 and 1
+	Beta        float64   // Symbol's return per unit of Benchmark's return
+}
+
+// Correlator keeps the rolling correlation and beta of Symbol against
+// Benchmark. The two streams quote at different times, so their latest mids
+// are sampled together once per SampleInterval, and the statistics cover the
+// log returns between samples within the last Window.
+type Correlator struct {
+	Symbol         string
+	Benchmark      string
+	Window         time.Duration     // Period the statistics cover (default 1h)
+	SampleInterval time.Duration     // Time between samples of both mids (default 1s)
+	MinSamples     int               // Return pairs needed before updates are reported (default 10)
+	OnUpdate       func(Correlation) // Called after every sample once MinSamples is reached
+
+	mu       sync.Mutex
+	mid      [2]float64 // Latest mid of Symbol and Benchmark
+	bucket   time.Time  // Start of the sample interval being filled
+	prev     [2]float64 // Mids at the previous sample
+	returns  []pairReturn
+	latest   Correlation
+	reported bool
+}
+
+// pairReturn is the log return of both symbols over one sample interval
+type pairReturn struct {
+	t    time.Time
+	a, b float64
+}
+
+// NewCorrelator creates a correlator of symbol against benchmark over window
+func NewCorrelator(symbol, benchmark string, window time.Duration) *Correlator {
+	return &Correlator{
+		Symbol:         symbol,
+		Benchmark:      benchmark,
+		Window:         window,
+		SampleInterval: time.Second,
+		MinSamples:     10,
+	}
+}
+
+// SetSampleInterval sets the time between samples of both mids
+func (c *Correlator) SetSampleInterval(interval time.Duration) {
+	c.SampleInterval = interval
+}
+
+// SetUpdateHandler sets the callback function for updated statistics
+func (c *Correlator) SetUpdateHandler(handler func(Correlation)) {
+	c.OnUpdate = handler
+}
+
+// Symbols returns the two symbols to subscribe to
+func (c *Correlator) Symbols() []string {
+	return []string{c.Symbol, c.Benchmark}
+}
+
+// AddQuote records a quote of either symbol. Quotes of other symbols are
+// ignored. A quote in a later sample interval samples both mids first.
+func (c *Correlator) AddQuote(quote tradermadews.QuoteMessage) error {
+	var i int
+	switch quote.Symbol {
+	case c.Symbol:
+		i = 0
+	case c.Benchmark:
+		i = 1
+	default:
+		return nil
+	}
+	t := quote.Time
+	if t.IsZero() {
+		var err error
+		if t, err = quote.Timestamp(); err != nil {
+			return fmt.Errorf("invalid timestamp %q for %s: %w", quote.Ts, quote.Symbol, err)
+		}
+	}
+	mid := quote.Mid
+	if mid <= 0 {
+		mid = (quote.Bid + quote.Ask) / 2
+	}
+	if mid <= 0 {
+		return nil
+	}
+
+	interval := c.SampleInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	c.mu.Lock()
+	var update *Correlation
+	bucket := t.Truncate(interval)
+	if c.bucket.IsZero() {
+		c.bucket = bucket
+	} else if bucket.After(c.bucket) {
+		update = c.sample(c.bucket.Add(interval))
+		c.bucket = bucket
+	}
+	c.mid[i] = mid
+	c.mu.Unlock()
+
+	if update != nil && c.OnUpdate != nil {
+		c.OnUpdate(*update)
+	}
+	return nil
+}
+
+// HandleQuote can be passed to WebSocketClient.SetMessageHandler directly
+func (c *Correlator) HandleQuote(quote tradermadews.QuoteMessage, _ string) {
+	if err := c.AddQuote(quote); err != nil {
+		fmt.Printf("Correlator: %v\n", err)
+	}
+}
+
+// sample records the returns since the previous sample and returns the
+// updated statistics once there are enough. Must be called with mu held.
+func (c *Correlator) sample(t time.Time) *Correlation {
+	if c.mid[0] <= 0 || c.mid[1] <= 0 {
+		return nil // Waiting for both symbols to quote
+	}
+	if c.prev[0] > 0 {
+		c.returns = append(c.returns, pairReturn{
+			t: t,
+			a: math.Log(c.mid[0] / c.prev[0]),
+			b: math.Log(c.mid[1] / c.prev[1]),
+		})
+	}
+	c.prev = c.mid
+
+	window := c.Window
+	if window <= 0 {
+		window = time.Hour
+	}
+	cutoff := t.Add(-window)
+	drop := 0
+	for drop < len(c.returns) && !c.returns[drop].t.After(cutoff) {
+		drop++
+	}
+	c.returns = c.returns[drop:]
+
+	if len(c.returns) < max(c.MinSamples, 2) {
+		return nil
+	}
+	c.latest = c.compute(t, window)
+	c.reported = true
+	latest := c.latest
+	return &latest
+}
+
+// compute returns the statistics of the returns in the window. Must be
+// called with mu held.
+func (c *Correlator) compute(t time.Time, window time.Duration) Correlation {
+	n := float64(len(c.returns))
+	var meanA, meanB float64
+	for _, r := range c.returns {
+		meanA += r.a
+		meanB += r.b
+	}
+	meanA /= n
+	meanB /= n
+
+	var cov, varA, varB float64
+	for _, r := range c.returns {
+		da, db := r.a-meanA, r.b-meanB
+		cov += da * db
+		varA += da * da
+		varB += db * db
+	}
+
+	corr := Correlation{
+		Symbol:    c.Symbol,
+		Benchmark: c.Benchmark,
+		Window:    window,
+		Time:      t,
+		Samples:   len(c.returns),
+	}
+	// Undefined while either symbol is flat, reported as zero
+	if varA > 0 && varB > 0 {
+		corr.Correlation = cov / math.Sqrt(varA*varB)
+	}
+	if varB > 0 {
+		corr.Beta = cov / varB
+	}
+	return corr
+}
+
+// Current returns the latest statistics, if MinSamples has been reached
+func (c *Correlator) Current() (Correlation, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.latest, c.reported
+}
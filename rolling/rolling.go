@@ -0,0 +1,242 @@
+// Package rolling aggregates streamed quotes into rolling statistics of the
+// mid price and spread per symbol over one or more windows.
+package rolling
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Stats summarizes a series of values
+type Stats struct {
+	Count  int
+	Min    float64
+	Max    float64
+	Mean   float64
+	StdDev float64 // Population standard deviation
+}
+
+// Summary holds the statistics of one symbol over one window
+type Summary struct {
+	Symbol string
+	Window time.Duration
+	Start  time.Time // Start of the window
+	End    time.Time // End of the window
+	Mid    Stats
+	Spread Stats // Ask minus bid
+}
+
+// Aggregator keeps rolling statistics for every symbol and window. Rolling
+// returns the statistics over the last window at any time, and OnClose
+// receives the statistics of each completed window, aligned to UTC
+// boundaries like candles.
+type Aggregator struct {
+	Windows []time.Duration
+	OnClose func(Summary) // Called once per completed window
+
+	mu     sync.Mutex
+	series map[seriesKey]*series
+}
+
+// seriesKey identifies the statistics of a symbol and window
+type seriesKey struct {
+	symbol string
+	window time.Duration
+}
+
+// sample is one quote's values
+type sample struct {
+	t      time.Time
+	mid    float64
+	spread float64
+}
+
+// series holds the samples of the last window and the open aligned window
+type series struct {
+	samples   []sample    // Samples within the last window, oldest first
+	openStart time.Time   // Start of the open aligned window
+	closedEnd time.Time   // End of the last closed aligned window; earlier quotes are late
+	mid       accumulator // Mid prices in the open aligned window
+	spread    accumulator // Spreads in the open aligned window
+}
+
+// accumulator computes Stats in constant memory with Welford's algorithm
+type accumulator struct {
+	stats Stats
+	m2    float64
+}
+
+func (acc *accumulator) add(v float64) {
+	st := &acc.stats
+	if st.Count == 0 {
+		st.Min, st.Max = v, v
+	}
+	st.Count++
+	st.Min = math.Min(st.Min, v)
+	st.Max = math.Max(st.Max, v)
+	delta := v - st.Mean
+	st.Mean += delta / float64(st.Count)
+	acc.m2 += delta * (v - st.Mean)
+	st.StdDev = math.Sqrt(acc.m2 / float64(st.Count))
+}
+
+// NewAggregator creates an aggregator for the given windows, e.g. time.Minute, time.Hour
+func NewAggregator(windows ...time.Duration) *Aggregator {
+	return &Aggregator{
+		Windows: windows,
+		series:  make(map[seriesKey]*series),
+	}
+}
+
+// SetCloseHandler sets the callback function for completed windows
+func (a *Aggregator) SetCloseHandler(handler func(Summary)) {
+	a.OnClose = handler
+}
+
+// AddQuote adds a quote to every window of its symbol. A quote falling in a
+// later aligned window closes the current one first; a quote for an aligned
+// window that has already closed only counts towards Rolling.
+func (a *Aggregator) AddQuote(quote tradermadews.QuoteMessage) error {
+	ts, err := quote.Timestamp()
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q for %s: %w", quote.Ts, quote.Symbol, err)
+	}
+	s := sample{t: ts, mid: quote.Mid, spread: quote.Ask - quote.Bid}
+
+	var closed []Summary
+	a.mu.Lock()
+	for _, window := range a.Windows {
+		if window <= 0 {
+			continue
+		}
+		key := seriesKey{quote.Symbol, window}
+		sr := a.series[key]
+		if sr == nil {
+			sr = &series{}
+			a.series[key] = sr
+		}
+
+		// Rolling samples: drop everything older than the window
+		cutoff := ts.Add(-window)
+		drop := 0
+		for drop < len(sr.samples) && !sr.samples[drop].t.After(cutoff) {
+			drop++
+		}
+		sr.samples = append(sr.samples[drop:], s)
+
+		// Aligned window: close it once a quote falls in a later one
+		start := ts.UTC().Truncate(window)
+		if start.Before(sr.openStart) || start.Before(sr.closedEnd) {
+			continue // Late quote for a window that has already closed
+		}
+		if sr.mid.stats.Count > 0 && start.After(sr.openStart) {
+			closed = append(closed, sr.closeOpen(quote.Symbol, window))
+		}
+		sr.openStart = start
+		sr.mid.add(s.mid)
+		sr.spread.add(s.spread)
+	}
+	a.mu.Unlock()
+
+	a.emit(closed)
+	return nil
+}
+
+// HandleQuote can be passed to WebSocketClient.SetMessageHandler directly
+func (a *Aggregator) HandleQuote(quote tradermadews.QuoteMessage, _ string) {
+	if err := a.AddQuote(quote); err != nil {
+		fmt.Printf("Rolling aggregator: %v\n", err)
+	}
+}
+
+// Rolling returns the statistics of a symbol over the window ending at its
+// latest quote
+func (a *Aggregator) Rolling(symbol string, window time.Duration) (Summary, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	sr := a.series[seriesKey{symbol, window}]
+	if sr == nil || len(sr.samples) == 0 {
+		return Summary{}, false
+	}
+	end := sr.samples[len(sr.samples)-1].t
+	return summarize(symbol, window, end.Add(-window), sr.samples), true
+}
+
+// CloseExpired closes every aligned window whose end is at or before now.
+// Without it a window only closes when the next quote for its symbol arrives.
+func (a *Aggregator) CloseExpired(now time.Time) {
+	var closed []Summary
+	a.mu.Lock()
+	for key, sr := range a.series {
+		if sr.mid.stats.Count > 0 && !now.Before(sr.openStart.Add(key.window)) {
+			closed = append(closed, sr.closeOpen(key.symbol, key.window))
+		}
+	}
+	a.mu.Unlock()
+
+	a.emit(closed)
+}
+
+// closeOpen returns the summary of the open aligned window and resets it
+func (sr *series) closeOpen(symbol string, window time.Duration) Summary {
+	summary := Summary{
+		Symbol: symbol,
+		Window: window,
+		Start:  sr.openStart,
+		End:    sr.openStart.Add(window),
+		Mid:    sr.mid.stats,
+		Spread: sr.spread.stats,
+	}
+	sr.mid, sr.spread = accumulator{}, accumulator{}
+	sr.closedEnd = summary.End
+	return summary
+}
+
+func (a *Aggregator) emit(closed []Summary) {
+	if a.OnClose == nil {
+		return
+	}
+	for _, s := range closed {
+		a.OnClose(s)
+	}
+}
+
+// summarize computes the statistics of samples
+func summarize(symbol string, window time.Duration, start time.Time, samples []sample) Summary {
+	return Summary{
+		Symbol: symbol,
+		Window: window,
+		Start:  start,
+		End:    start.Add(window),
+		Mid:    describe(samples, func(s sample) float64 { return s.mid }),
+		Spread: describe(samples, func(s sample) float64 { return s.spread }),
+	}
+}
+
+// describe computes Stats over one value of the samples
+func describe(samples []sample, value func(sample) float64) Stats {
+	if len(samples) == 0 {
+		return Stats{}
+	}
+	st := Stats{Count: len(samples), Min: math.Inf(1), Max: math.Inf(-1)}
+	var sum float64
+	for _, s := range samples {
+		v := value(s)
+		sum += v
+		st.Min = math.Min(st.Min, v)
+		st.Max = math.Max(st.Max, v)
+	}
+	st.Mean = sum / float64(len(samples))
+
+	var sq float64
+	for _, s := range samples {
+		d := value(s) - st.Mean
+		sq += d * d
+	}
+	st.StdDev = math.Sqrt(sq / float64(len(samples)))
+	return st
+}
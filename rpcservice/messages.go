@@ -0,0 +1,63 @@
+package rpcservice
+
+// The types below mirror the messages in tradermade.proto field for field, so
+// converting to and from the generated tradermadepb types is a plain copy.
+
+// LiveRatesRequest mirrors tradermade.v1.LiveRatesRequest
+type LiveRatesRequest struct {
+	Currencies []string
+}
+
+// LiveQuote mirrors tradermade.v1.LiveQuote
+type LiveQuote struct {
+	BaseCurrency  string
+	QuoteCurrency string
+	Instrument    string
+	Bid           float64
+	Ask           float64
+	Mid           float64
+}
+
+// LiveRatesResponse mirrors tradermade.v1.LiveRatesResponse
+type LiveRatesResponse struct {
+	Quotes    []*LiveQuote
+	Timestamp int64
+}
+
+// TimeSeriesRequest mirrors tradermade.v1.TimeSeriesRequest
+type TimeSeriesRequest struct {
+	Currency  string
+	StartDate string
+	EndDate   string
+	Interval  string // "daily", "hourly" or "minute"
+	Period    int32  // Required for hourly and minute intervals
+}
+
+// TimeSeriesBar mirrors tradermade.v1.TimeSeriesBar
+type TimeSeriesBar struct {
+	Date  string
+	Open  float64
+	High  float64
+	Low   float64
+	Close float64
+}
+
+// TimeSeriesResponse mirrors tradermade.v1.TimeSeriesResponse
+type TimeSeriesResponse struct {
+	Currency string
+	Quotes   []*TimeSeriesBar
+}
+
+// StreamQuotesRequest mirrors tradermade.v1.StreamQuotesRequest
+type StreamQuotesRequest struct {
+	Symbols []string // Empty streams every symbol the sidecar subscribes to
+}
+
+// StreamQuote mirrors tradermade.v1.StreamQuote
+type StreamQuote struct {
+	Symbol      string
+	Bid         float64
+	Ask         float64
+	Mid         float64
+	TimestampMs int64
+}
@@ -0,0 +1,199 @@
+// Package rpcservice implements the TraderMade sidecar service defined in
+// tradermade.proto on top of the REST and WebSocket clients, independent of
+// the transport.
+//
+// Service is not a gRPC server: it holds the RPC logic without depending on
+// grpc-go, so the SDK keeps a single dependency. To serve it over gRPC, add
+// google.golang.org/grpc and google.golang.org/protobuf to your module, run
+// go generate (requires protoc, protoc-gen-go and protoc-gen-go-grpc) to
+// produce the tradermadepb stubs, and register a thin server that copies
+// between the tradermadepb types and the mirrored types in this package. The
+// same Service can back any other transport, such as Connect or plain HTTP.
+package rpcservice
+
+//go:generate protoc --go_out=.. --go_opt=module=github.com/tradermade/Go-SDK --go-grpc_out=.. --go-grpc_opt=module=github.com/tradermade/Go-SDK tradermade.proto
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	tradermade "github.com/tradermade/Go-SDK/rest"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// QuoteStream is the server side of a StreamQuotes call. The generated
+// TraderMade_StreamQuotesServer has the same shape.
+type QuoteStream interface {
+	Send(*StreamQuote) error
+	Context() context.Context
+}
+
+// Service implements the TraderMade RPCs
+type Service struct {
+	REST       *tradermade.RESTClient
+	BufferSize int // Per-stream buffer; quotes are dropped for streams that fall this far behind (default 256)
+
+	mu      sync.Mutex
+	streams map[*quoteStream]struct{}
+}
+
+// quoteStream is one active StreamQuotes subscriber
+type quoteStream struct {
+	symbols map[string]bool
+	ch      chan *StreamQuote
+}
+
+// NewService creates a service backed by rest. When ws is not nil, its feed
+// is fanned out to StreamQuotes callers: the service's handler is installed
+// as ws's message handler and calls the handler set before, if any, first.
+// Set other handlers before calling NewService.
+func NewService(rest *tradermade.RESTClient, ws *tradermadews.WebSocketClient) *Service {
+	s := &Service{
+		REST:       rest,
+		BufferSize: 256,
+		streams:    make(map[*quoteStream]struct{}),
+	}
+	if ws != nil {
+		previous := ws.MessageHandler
+		ws.SetMessageHandler(func(quote tradermadews.QuoteMessage, ts string) {
+			if previous != nil {
+				previous(quote, ts)
+			}
+			s.HandleQuote(quote, ts)
+		})
+	}
+	return s
+}
+
+// GetLiveRates implements the GetLiveRates RPC
+func (s *Service) GetLiveRates(ctx context.Context, req *LiveRatesRequest) (*LiveRatesResponse, error) {
+	if len(req.Currencies) == 0 {
+		return nil, fmt.Errorf("at least one currency is required")
+	}
+	var rates tradermade.LiveRate
+	params := url.Values{"currency": {strings.Join(req.Currencies, ",")}}
+	if err := s.REST.Do(ctx, "live", params, &rates); err != nil {
+		return nil, err
+	}
+
+	resp := &LiveRatesResponse{Timestamp: rates.Timestamp}
+	for _, q := range rates.Quotes {
+		resp.Quotes = append(resp.Quotes, &LiveQuote{
+			BaseCurrency:  q.BaseCurrency,
+			QuoteCurrency: q.QuoteCurrency,
+			Instrument:    q.Instrument,
+			Bid:           q.Bid,
+			Ask:           q.Ask,
+			Mid:           q.Mid,
+		})
+	}
+	return resp, nil
+}
+
+// GetTimeSeries implements the GetTimeSeries RPC
+func (s *Service) GetTimeSeries(ctx context.Context, req *TimeSeriesRequest) (*TimeSeriesResponse, error) {
+	start, err := tradermade.ParseDate(req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date: %w", err)
+	}
+	end, err := tradermade.ParseDate(req.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date: %w", err)
+	}
+	interval := tradermade.Interval(strings.ToLower(strings.TrimSpace(req.Interval)))
+	switch interval {
+	case tradermade.Daily, tradermade.Hourly, tradermade.Minute:
+	default:
+		return nil, fmt.Errorf("invalid interval %q, expected daily, hourly or minute", req.Interval)
+	}
+	var period []int
+	if req.Period > 0 {
+		period = append(period, int(req.Period))
+	} else if interval != tradermade.Daily {
+		return nil, fmt.Errorf("period is required for the %s interval", interval)
+	}
+	series, err := s.REST.TimeSeries(req.Currency).
+		From(start).To(end).
+		Interval(interval, period...).
+		Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &TimeSeriesResponse{Currency: req.Currency}
+	for _, q := range series.Quotes {
+		resp.Quotes = append(resp.Quotes, &TimeSeriesBar{
+			Date:  q.Date,
+			Open:  q.Open,
+			High:  q.High,
+			Low:   q.Low,
+			Close: q.Close,
+		})
+	}
+	return resp, nil
+}
+
+// StreamQuotes implements the StreamQuotes RPC. It blocks until the client
+// goes away or a send fails.
+func (s *Service) StreamQuotes(req *StreamQuotesRequest, stream QuoteStream) error {
+	qs := &quoteStream{
+		symbols: make(map[string]bool, len(req.Symbols)),
+		ch:      make(chan *StreamQuote, s.BufferSize),
+	}
+	for _, symbol := range req.Symbols {
+		qs.symbols[symbol] = true
+	}
+
+	s.mu.Lock()
+	s.streams[qs] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.streams, qs)
+		s.mu.Unlock()
+	}()
+
+	ctx := stream.Context()
+	for {
+		select {
+		case quote := <-qs.ch:
+			if err := stream.Send(quote); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// HandleQuote fans a quote out to every matching stream. NewService installs
+// it on the WebSocket client.
+func (s *Service) HandleQuote(quote tradermadews.QuoteMessage, _ string) {
+	var tsMs int64
+	if ts, err := quote.Timestamp(); err == nil {
+		tsMs = ts.UnixMilli()
+	}
+	msg := &StreamQuote{
+		Symbol:      quote.Symbol,
+		Bid:         quote.Bid,
+		Ask:         quote.Ask,
+		Mid:         quote.Mid,
+		TimestampMs: tsMs,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for qs := range s.streams {
+		if len(qs.symbols) > 0 && !qs.symbols[quote.Symbol] {
+			continue
+		}
+		select {
+		case qs.ch <- msg:
+		default:
+			// Slow consumer, drop rather than stall the feed
+		}
+	}
+}
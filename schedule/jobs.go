@@ -0,0 +1,57 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tradermade/Go-SDK/feed"
+	tradermade "github.com/tradermade/Go-SDK/rest"
+	"github.com/tradermade/Go-SDK/sinks"
+)
+
+// LiveRates returns a job function that fetches live rates for the symbols
+// with one request and writes them as quotes
+func LiveRates(client *tradermade.RESTClient, symbols []string) Func {
+	return func(ctx context.Context, _ time.Time, out sinks.Sink) error {
+		rates, err := client.GetLiveRates(symbols)
+		if err != nil {
+			return err
+		}
+		for _, quote := range feed.LiveRateQuotes(rates) {
+			if err := out.WriteQuote(quote); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// DailyCandles returns a job function that fetches the last completed UTC
+// day's candle for every pair and writes it. Pairs are fetched one after
+// another; a failing pair does not stop the rest and the errors are joined.
+func DailyCandles(client *tradermade.RESTClient, pairs []string) Func {
+	return func(ctx context.Context, at time.Time, out sinks.Sink) error {
+		day := at.UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+		var errs []error
+		for _, pair := range pairs {
+			if err := ctx.Err(); err != nil {
+				errs = append(errs, err)
+				break
+			}
+			candles, err := client.GetDailyHistoryRange(pair, day, day)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", pair, err))
+				continue
+			}
+			for _, c := range candles {
+				if err := out.WriteCandle(c); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", pair, err))
+					break
+				}
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
@@ -0,0 +1,259 @@
+// Package schedule runs recurring fetch jobs, such as daily candles for a
+// list of pairs at 00:05 UTC or an hourly live rates snapshot, and delivers
+// their results into sinks. It replaces cron scripts wrapped around the SDK.
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/tradermade/Go-SDK/clock"
+	"github.com/tradermade/Go-SDK/sinks"
+)
+
+// Func is the work of a job. at is the scheduled run time, before jitter;
+// out writes to the job's sinks and is flushed after Func returns.
+type Func func(ctx context.Context, at time.Time, out sinks.Sink) error
+
+// Job is a recurring task
+type Job struct {
+	Name         string
+	Schedule     Schedule
+	Run          Func
+	Sinks        []sinks.Sink  // Destinations for the job's quotes and candles
+	Jitter       time.Duration // Random delay of up to Jitter added to each run, spreading load
+	Timeout      time.Duration // Cancels a run's context after this long, zero means no limit
+	AllowOverlap bool          // Start a run even while the previous one is still going
+}
+
+// Result describes one finished or skipped run
+type Result struct {
+	Job       string
+	Scheduled time.Time // Run time from the schedule
+	Started   time.Time
+	Duration  time.Duration
+	Skipped   bool  // The previous run was still going, see Job.AllowOverlap
+	Err       error // Error from the job or from flushing its sinks
+}
+
+// Scheduler runs jobs on their schedules
+type Scheduler struct {
+	Clock         clock.Clock  // Times runs, nil uses the system clock
+	ResultHandler func(Result) // Called after every run and skipped run
+	ErrorHandler  func(error)  // Handles failed runs, nil prints them
+
+	mu      sync.Mutex
+	jobs    map[string]*entry
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running bool
+	loops   sync.WaitGroup
+	runs    sync.WaitGroup
+}
+
+// entry is a job with its run state
+type entry struct {
+	job    Job
+	out    *sinks.Registry
+	mu     sync.Mutex
+	active int // Runs in progress
+	last   Result
+}
+
+// New creates an empty scheduler
+func New() *Scheduler {
+	return &Scheduler{jobs: make(map[string]*entry)}
+}
+
+// SetResultHandler sets the callback function called after every run
+func (s *Scheduler) SetResultHandler(handler func(Result)) {
+	s.ResultHandler = handler
+}
+
+// SetErrorHandler sets the callback function for failed runs
+func (s *Scheduler) SetErrorHandler(handler func(error)) {
+	s.ErrorHandler = handler
+}
+
+// Add registers a job. Jobs added while the scheduler is running start
+// immediately.
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" {
+		return fmt.Errorf("job has no name")
+	}
+	if job.Schedule == nil || job.Run == nil {
+		return fmt.Errorf("job %s needs a schedule and a run function", job.Name)
+	}
+	out := sinks.NewRegistry()
+	for i, sink := range job.Sinks {
+		out.Register(fmt.Sprintf("%s sink %d", job.Name, i), sink) // Names are unique
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[job.Name]; ok {
+		return fmt.Errorf("job %s already exists", job.Name)
+	}
+	e := &entry{job: job, out: out}
+	s.jobs[job.Name] = e
+	if s.running {
+		s.loops.Add(1)
+		go s.loop(s.ctx, e)
+	}
+	return nil
+}
+
+// Start runs every job on its schedule in the background
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return
+	}
+	s.running = true
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	for _, e := range s.jobs {
+		s.loops.Add(1)
+		go s.loop(s.ctx, e)
+	}
+}
+
+// Stop stops scheduling, cancels the context of runs in progress and waits
+// for them to return. Sinks are flushed but not closed.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	s.cancel()
+	s.mu.Unlock()
+
+	s.loops.Wait()
+	s.runs.Wait()
+}
+
+// RunNow runs a job once, outside its schedule, and returns its result. Overlap
+// protection still applies.
+func (s *Scheduler) RunNow(ctx context.Context, name string) (Result, error) {
+	s.mu.Lock()
+	e, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return Result{}, fmt.Errorf("no job named %s", name)
+	}
+	r := s.run(ctx, e, clock.OrDefault(s.Clock).Now())
+	return r, r.Err
+}
+
+// Last returns the result of a job's latest run. The boolean is false if the
+// job does not exist.
+func (s *Scheduler) Last(name string) (Result, bool) {
+	s.mu.Lock()
+	e, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return Result{}, false
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.last, true
+}
+
+// Next returns when a job runs next, before jitter
+func (s *Scheduler) Next(name string) (time.Time, bool) {
+	s.mu.Lock()
+	e, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return time.Time{}, false
+	}
+	return e.job.Schedule.Next(clock.OrDefault(s.Clock).Now()), true
+}
+
+// loop waits for each scheduled time of a job and starts its run
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	defer s.loops.Done()
+	clk := clock.OrDefault(s.Clock)
+
+	for {
+		now := clk.Now()
+		at := e.job.Schedule.Next(now)
+		if at.IsZero() {
+			return // The schedule never fires again
+		}
+		wait := at.Sub(now)
+		if e.job.Jitter > 0 {
+			wait += time.Duration(rand.Int63n(int64(e.job.Jitter)))
+		}
+
+		select {
+		case <-clk.After(wait):
+		case <-ctx.Done():
+			return
+		}
+
+		s.runs.Add(1)
+		go func() {
+			defer s.runs.Done()
+			s.run(ctx, e, at)
+		}()
+	}
+}
+
+// run executes one run of a job unless the previous one is still going
+func (s *Scheduler) run(ctx context.Context, e *entry, at time.Time) Result {
+	clk := clock.OrDefault(s.Clock)
+	r := Result{Job: e.job.Name, Scheduled: at, Started: clk.Now()}
+
+	e.mu.Lock()
+	if e.active > 0 && !e.job.AllowOverlap {
+		e.mu.Unlock()
+		r.Skipped = true
+		r.Err = fmt.Errorf("job %s skipped: previous run still in progress", e.job.Name)
+		s.report(r)
+		return r
+	}
+	e.active++
+	e.mu.Unlock()
+
+	if e.job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.job.Timeout)
+		defer cancel()
+	}
+	err := e.job.Run(ctx, at, e.out)
+	if ferr := e.out.Flush(); err == nil && ferr != nil {
+		err = fmt.Errorf("failed to flush sinks: %w", ferr)
+	}
+	if err != nil {
+		r.Err = fmt.Errorf("job %s failed: %w", e.job.Name, err)
+	}
+	r.Duration = clk.Now().Sub(r.Started)
+
+	e.mu.Lock()
+	e.active--
+	e.last = r
+	e.mu.Unlock()
+
+	s.report(r)
+	return r
+}
+
+func (s *Scheduler) report(r Result) {
+	if s.ResultHandler != nil {
+		s.ResultHandler(r)
+	}
+	if r.Err == nil {
+		return
+	}
+	if s.ErrorHandler != nil {
+		s.ErrorHandler(r.Err)
+		return
+	}
+	fmt.Printf("Scheduled job error: %v\n", r.Err)
+}
@@ -0,0 +1,397 @@
+// Package archive rolls streamed quotes into gzipped hourly CSV files per
+// symbol and uploads them to object storage (S3, GCS, ...) with retries.
+//
+// Objects are named with Hive-style partitions so they can be queried by
+// Athena, BigQuery or Spark directly:
+//
+//	<prefix>/symbol=EURUSD/date=2024-10-02/EURUSD-2024-10-02T13.csv.gz
+//
+// An hour whose file is uploaded early, by Flush or because ticks arrive
+// after its grace period, continues in further parts numbered from 1, e.g.
+// EURUSD-2024-10-02T13-1.csv.gz, so no upload overwrites an earlier one.
+// Ticks for an hour that ended more than a day ago, whose part count is no
+// longer kept, go to a part named after the time it was opened instead, e.g.
+// EURUSD-2024-10-02T13-late1727917200000000000.csv.gz.
+//
+// Candles are archived the same way under their own prefix (default
+// <prefix>/candles).
+//
+// The SDK does not depend on a cloud SDK; wrap the S3 uploader or GCS
+// bucket handle of your choice in the Uploader interface.
+package archive
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/tradermade/Go-SDK/candle"
+	"github.com/tradermade/Go-SDK/clock"
+	"github.com/tradermade/Go-SDK/sinks"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Uploader stores an object under key
+type Uploader interface {
+	Upload(ctx context.Context, key string, body io.Reader) error
+}
+
+// UploaderFunc adapts a plain function to the Uploader interface
+type UploaderFunc func(ctx context.Context, key string, body io.Reader) error
+
+// Upload calls f(ctx, key, body)
+func (f UploaderFunc) Upload(ctx context.Context, key string, body io.Reader) error {
+	return f(ctx, key, body)
+}
+
+// Archiver writes quotes to local hourly files and uploads them once the hour closes
+type Archiver struct {
+	Uploader      Uploader
+	Prefix        string        // Object key prefix, e.g. "ticks/raw"
+	CandlePrefix  string        // Object key prefix for candles (default Prefix + "/candles")
+	TempDir       string        // Directory for in-progress files (default os.TempDir())
+	MaxRetries    int           // Upload attempts per file (default 5)
+	RetryInterval time.Duration // Initial delay between upload attempts, doubled each retry (default 2s)
+	Grace         time.Duration // How long after the hour ends its file stays open for late ticks (default 1m)
+	ErrorHandler  func(error)   // Receives write and upload errors
+	Clock         clock.Clock   // Times upload retries and late parts, nil uses the system clock
+
+	mu      sync.Mutex
+	files   map[fileKey]*hourFile // Open files by name and hour
+	parts   map[fileKey]int       // Parts already finished by name and hour
+	late    int64                 // Suffix of the last late part, see lateSuffix
+	uploads sync.WaitGroup
+	stop    chan struct{}
+	done    chan struct{}
+	closed  bool
+}
+
+// fileKey identifies the file of a name (the symbol, or "candles/" + symbol
+// for candles) and hour
+type fileKey struct {
+	name string
+	hour int64 // Unix seconds
+}
+
+// partRetention is how long after an hour its part count is kept. Files
+// opened even later get a unique suffix instead of a part number.
+const partRetention = 24 * time.Hour
+
+// hourFile is an open gzip CSV file for one symbol and hour
+type hourFile struct {
+	symbol string
+	hour   time.Time
+	key    string // Object key the file is uploaded to
+	file   *os.File
+	gz     *gzip.Writer
+	csv    *csv.Writer
+}
+
+var _ sinks.Sink = (*Archiver)(nil)
+
+// NewArchiver creates an archiver and starts the loop that closes finished hours
+func NewArchiver(uploader Uploader, prefix string) *Archiver {
+	a := &Archiver{
+		Uploader:      uploader,
+		Prefix:        prefix,
+		MaxRetries:    5,
+		RetryInterval: 2 * time.Second,
+		Grace:         time.Minute,
+		files:         make(map[fileKey]*hourFile),
+		parts:         make(map[fileKey]int),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	go a.rotateLoop()
+	return a
+}
+
+// ObjectKey returns the object key for the first part of a symbol's hour
+func (a *Archiver) ObjectKey(symbol string, hour time.Time) string {
+	return objectKey(a.Prefix, symbol, hour, 0)
+}
+
+// CandleObjectKey returns the object key for the first part of a symbol's
+// candles in an hour
+func (a *Archiver) CandleObjectKey(symbol string, hour time.Time) string {
+	return objectKey(a.candlePrefix(), symbol, hour, 0)
+}
+
+func (a *Archiver) candlePrefix() string {
+	if a.CandlePrefix != "" {
+		return a.CandlePrefix
+	}
+	return path.Join(a.Prefix, "candles")
+}
+
+func objectKey(prefix, symbol string, hour time.Time, part int) string {
+	suffix := ""
+	if part > 0 {
+		suffix = fmt.Sprint(part)
+	}
+	return suffixedKey(prefix, symbol, hour, suffix)
+}
+
+func suffixedKey(prefix, symbol string, hour time.Time, suffix string) string {
+	hour = hour.UTC()
+	name := fmt.Sprintf("%s-%s", symbol, hour.Format("2006-01-02T15"))
+	if suffix != "" {
+		name += "-" + suffix
+	}
+	return path.Join(prefix,
+		"symbol="+symbol,
+		"date="+hour.Format("2006-01-02"),
+		name+".csv.gz")
+}
+
+// WriteQuote appends a quote to the file for its symbol and hour. Starting a
+// new hour closes the files of earlier hours whose grace period has passed
+// and uploads them in the background.
+func (a *Archiver) WriteQuote(quote tradermadews.QuoteMessage) error {
+	ts, err := quote.Timestamp()
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q for %s: %w", quote.Ts, quote.Symbol, err)
+	}
+	return a.write(quote.Symbol, a.Prefix, quote.Symbol, ts, quoteHeader, []string{
+		quote.Ts,
+		fmt.Sprint(quote.Bid),
+		fmt.Sprint(quote.Ask),
+		fmt.Sprint(quote.Mid),
+	})
+}
+
+// WriteCandle appends a candle to the candle file for its symbol and the hour it starts in
+func (a *Archiver) WriteCandle(c candle.Candle) error {
+	return a.write("candles/"+c.Symbol, a.candlePrefix(), c.Symbol, c.Time, candleHeader, []string{
+		fmt.Sprint(c.Time.UnixMilli()),
+		fmt.Sprint(c.Interval.Milliseconds()),
+		fmt.Sprint(c.Open),
+		fmt.Sprint(c.High),
+		fmt.Sprint(c.Low),
+		fmt.Sprint(c.Close),
+		fmt.Sprint(c.Ticks),
+	})
+}
+
+var (
+	quoteHeader  = []string{"ts", "bid", "ask", "mid"}
+	candleHeader = []string{"ts", "interval_ms", "open", "high", "low", "close", "ticks"}
+)
+
+// write appends a row timestamped t to the file of name for t's hour. A late
+// row goes to its own hour's file, reopened as a new part if it was already
+// uploaded.
+func (a *Archiver) write(name, prefix, symbol string, t time.Time, header []string, row []string) error {
+	a.mu.Lock()
+	finishErrs, err := a.writeLocked(name, prefix, symbol, t, header, row)
+	a.mu.Unlock()
+
+	for _, ferr := range finishErrs {
+		a.reportError(ferr)
+	}
+	return err
+}
+
+// writeLocked is write with mu held. It also returns the errors of finishing
+// earlier files, to be reported once mu is released.
+func (a *Archiver) writeLocked(name, prefix, symbol string, t time.Time, header []string, row []string) ([]error, error) {
+	if a.closed {
+		return nil, fmt.Errorf("archiver is closed")
+	}
+
+	hour := t.UTC().Truncate(time.Hour)
+	key := fileKey{name: name, hour: hour.Unix()}
+	hf := a.files[key]
+	var finishErrs []error
+	if hf == nil {
+		// Opening a file is the only time name's earlier hours can have ended
+		for k, old := range a.files {
+			if k.name == name && t.After(old.hour.Add(time.Hour+a.Grace)) {
+				if err := a.finishKey(k, old); err != nil {
+					finishErrs = append(finishErrs, err)
+				}
+			}
+		}
+		objKey := objectKey(prefix, symbol, hour, a.parts[key])
+		if now := clock.OrDefault(a.Clock).Now(); now.After(hour.Add(time.Hour + partRetention)) {
+			objKey = suffixedKey(prefix, symbol, hour, a.lateSuffix(now))
+		}
+		var err error
+		if hf, err = a.open(symbol, hour, header, objKey); err != nil {
+			return finishErrs, err
+		}
+		a.files[key] = hf
+	}
+	return finishErrs, hf.csv.Write(row)
+}
+
+// lateSuffix returns a suffix unique to this archiver for a file of an hour
+// whose part count may have been forgotten, based on now so that it is also
+// unlikely to repeat after a restart. Must be called with mu held.
+func (a *Archiver) lateSuffix(now time.Time) string {
+	a.late = max(now.UnixNano(), a.late+1)
+	return fmt.Sprintf("late%d", a.late)
+}
+
+// HandleQuote can be passed to WebSocketClient.SetMessageHandler directly
+func (a *Archiver) HandleQuote(quote tradermadews.QuoteMessage, _ string) {
+	if err := a.WriteQuote(quote); err != nil {
+		a.reportError(err)
+	}
+}
+
+// Flush closes and uploads every open file, including the current hour.
+// Later quotes of the same hour go to its next part. It returns the errors
+// of closing files; upload errors go to ErrorHandler.
+func (a *Archiver) Flush() error {
+	var errs []error
+	a.mu.Lock()
+	for key, hf := range a.files {
+		if err := a.finishKey(key, hf); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	a.mu.Unlock()
+
+	a.uploads.Wait()
+	return errors.Join(errs...)
+}
+
+// Close uploads all open files and waits for pending uploads to finish
+func (a *Archiver) Close() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+	a.mu.Unlock()
+
+	close(a.stop)
+	<-a.done
+	return a.Flush()
+}
+
+// open creates the temporary file for a symbol and hour
+func (a *Archiver) open(symbol string, hour time.Time, header []string, key string) (*hourFile, error) {
+	f, err := os.CreateTemp(a.TempDir, fmt.Sprintf("tradermade-%s-%s-*.csv.gz", symbol, hour.Format("2006010215")))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive file for %s: %w", symbol, err)
+	}
+	gz := gzip.NewWriter(f)
+	w := csv.NewWriter(gz)
+	if err := w.Write(header); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return &hourFile{symbol: symbol, hour: hour, key: key, file: f, gz: gz, csv: w}, nil
+}
+
+// finishKey removes the open file of key and finishes it, so the next file
+// of key is a new part. Must be called with mu held.
+func (a *Archiver) finishKey(key fileKey, hf *hourFile) error {
+	delete(a.files, key)
+	a.parts[key]++
+	return a.finish(hf)
+}
+
+// finish closes a file and uploads it in the background. Must be called
+// with mu held, so the caller reports the error once mu is released.
+func (a *Archiver) finish(hf *hourFile) error {
+	hf.csv.Flush()
+	err := hf.csv.Error()
+	if cerr := hf.gz.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		hf.file.Close()
+		os.Remove(hf.file.Name())
+		return fmt.Errorf("failed to finish archive file for %s: %w", hf.symbol, err)
+	}
+
+	a.uploads.Add(1)
+	go func() {
+		defer a.uploads.Done()
+		defer os.Remove(hf.file.Name())
+		defer hf.file.Close()
+
+		if err := a.upload(hf); err != nil {
+			a.reportError(err)
+		}
+	}()
+	return nil
+}
+
+// upload sends a finished file with exponential backoff between attempts
+func (a *Archiver) upload(hf *hourFile) error {
+	key := hf.key
+	delay := a.RetryInterval
+	attempts := a.MaxRetries
+	if attempts <= 0 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if _, err = hf.file.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to rewind archive file for %s: %w", key, err)
+		}
+		if err = a.Uploader.Upload(context.Background(), key, hf.file); err == nil {
+			return nil
+		}
+		if attempt < attempts {
+			<-clock.OrDefault(a.Clock).After(delay)
+			delay *= 2
+		}
+	}
+	return fmt.Errorf("failed to upload %s after %d attempts: %w", key, attempts, err)
+}
+
+// rotateLoop closes files whose hour (plus grace period) has passed, even if no new quotes arrive
+func (a *Archiver) rotateLoop() {
+	defer close(a.done)
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			var errs []error
+			a.mu.Lock()
+			for key, hf := range a.files {
+				if now.After(hf.hour.Add(time.Hour + a.Grace)) {
+					if err := a.finishKey(key, hf); err != nil {
+						errs = append(errs, err)
+					}
+				}
+			}
+			for key := range a.parts {
+				if now.After(time.Unix(key.hour, 0).Add(time.Hour + partRetention)) {
+					delete(a.parts, key)
+				}
+			}
+			a.mu.Unlock()
+
+			for _, err := range errs {
+				a.reportError(err)
+			}
+		case <-a.stop:
+			return
+		}
+	}
+}
+
+func (a *Archiver) reportError(err error) {
+	if a.ErrorHandler != nil {
+		a.ErrorHandler(err)
+		return
+	}
+	fmt.Printf("Archive error: %v\n", err)
+}
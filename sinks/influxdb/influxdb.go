@@ -0,0 +1,352 @@
+// Package influxdb converts quotes and candles to InfluxDB line protocol and
+// writes them through the InfluxDB v2 HTTP write API.
+package influxdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tradermade/Go-SDK/candle"
+	tradermade "github.com/tradermade/Go-SDK/rest"
+	"github.com/tradermade/Go-SDK/sinks"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Config holds the connection settings for the InfluxDB v2 API
+type Config struct {
+	URL               string        // Server URL, e.g. http://localhost:8086
+	Token             string        // API token
+	Org               string        // Organization name or ID
+	Bucket            string        // Destination bucket
+	QuoteMeasurement  string        // Measurement for quotes (default "quotes")
+	CandleMeasurement string        // Measurement for candles (default "candles")
+	BatchSize         int           // Points per write request (default 1000)
+	FlushInterval     time.Duration // Flush partially filled batches after this long (default 1s, negative disables)
+	MaxBuffered       int           // Points kept while InfluxDB is unavailable; the oldest are dropped beyond it (default 100000)
+}
+
+// DroppedError reports a batch InfluxDB rejected with a 4xx status, with its
+// lines so they can be dead-lettered. Retrying such a batch cannot succeed.
+type DroppedError struct {
+	Lines []string
+	Err   error
+}
+
+func (e *DroppedError) Error() string {
+	return fmt.Sprintf("dropped %d points: %v", len(e.Lines), e.Err)
+}
+
+func (e *DroppedError) Unwrap() error {
+	return e.Err
+}
+
+// Sink buffers line protocol points and posts them to InfluxDB in batches
+type Sink struct {
+	Config       Config
+	HTTPClient   *http.Client
+	ErrorHandler func(error) // Receives errors from background flushes and HandleQuote
+
+	mu      sync.Mutex
+	lines   []string
+	failing bool   // The last flush failed with a retryable error; writes leave retries to the flush loop
+	dropped uint64 // Points dropped by MaxBuffered
+	stop    chan struct{}
+	done    chan struct{}
+	closed  bool
+}
+
+var _ sinks.Sink = (*Sink)(nil)
+
+// NewSink creates a sink and starts the background flush loop
+func NewSink(cfg Config) *Sink {
+	if cfg.QuoteMeasurement == "" {
+		cfg.QuoteMeasurement = "quotes"
+	}
+	if cfg.CandleMeasurement == "" {
+		cfg.CandleMeasurement = "candles"
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 1000
+	}
+	if cfg.FlushInterval == 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.MaxBuffered <= 0 {
+		cfg.MaxBuffered = 100000
+	}
+
+	s := &Sink{
+		Config: cfg,
+		HTTPClient: &http.Client{
+			Timeout: time.Second * 10,
+		},
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go s.flushLoop()
+	return s
+}
+
+// QuoteLine formats a quote as a line protocol point with millisecond
+// precision. NaN and infinite prices, which InfluxDB rejects, are left out.
+func QuoteLine(measurement string, quote tradermadews.QuoteMessage) (string, error) {
+	ts, err := quote.Timestamp()
+	if err != nil {
+		return "", fmt.Errorf("invalid timestamp %q for %s: %w", quote.Ts, quote.Symbol, err)
+	}
+	line := point(measurement, quote.Symbol, ts, []string{"bid", "ask", "mid"}, []float64{quote.Bid, quote.Ask, quote.Mid})
+	if line == "" {
+		return "", fmt.Errorf("quote for %s at %s has no finite price", quote.Symbol, quote.Ts)
+	}
+	return line, nil
+}
+
+// CandleLine formats an OHLC bar as a line protocol point with millisecond
+// precision. NaN and infinite prices are left out, and the line is empty if
+// no price is finite.
+func CandleLine(measurement, symbol string, t time.Time, open, high, low, close float64) string {
+	return point(measurement, symbol, t, []string{"open", "high", "low", "close"}, []float64{open, high, low, close})
+}
+
+// point formats a line with the finite values as fields, or returns "" if there are none
+func point(measurement, symbol string, t time.Time, names []string, values []float64) string {
+	var fields []string
+	for i, v := range values {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			fields = append(fields, names[i]+"="+formatFloat(v))
+		}
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s,symbol=%s %s %d", escapeKey(measurement), escapeKey(symbol), strings.Join(fields, ","), t.UnixMilli())
+}
+
+// WriteQuote buffers a quote and flushes when the batch is full
+func (s *Sink) WriteQuote(quote tradermadews.QuoteMessage) error {
+	line, err := QuoteLine(s.Config.QuoteMeasurement, quote)
+	if err != nil {
+		return err
+	}
+	return s.add(line)
+}
+
+// HandleQuote can be passed to WebSocketClient.SetMessageHandler directly
+func (s *Sink) HandleQuote(quote tradermadews.QuoteMessage, _ string) {
+	if err := s.WriteQuote(quote); err != nil {
+		s.reportError(err)
+	}
+}
+
+// WriteCandle buffers a candle as a point in the candle measurement
+func (s *Sink) WriteCandle(c candle.Candle) error {
+	line := CandleLine(s.Config.CandleMeasurement, c.Symbol, c.Time, c.Open, c.High, c.Low, c.Close)
+	if line == "" {
+		return fmt.Errorf("candle for %s at %s has no finite price", c.Symbol, c.Time)
+	}
+	return s.add(line)
+}
+
+// WriteTimeSeries buffers every bar of a timeseries response as a candle point
+func (s *Sink) WriteTimeSeries(series *tradermade.TimeSeriesRate) error {
+	symbol := series.BaseCurrency + series.QuoteCurrency
+	for _, quote := range series.Quotes {
+		t, err := tradermade.ParseDate(quote.Date)
+		if err != nil {
+			return err
+		}
+		line := CandleLine(s.Config.CandleMeasurement, symbol, t, quote.Open, quote.High, quote.Low, quote.Close)
+		if line == "" {
+			continue // Missing bar
+		}
+		if err := s.add(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush posts all buffered points to InfluxDB. After a network error, a 5xx
+// or a 429 the failed batch and the rest of the buffer are kept for the next
+// flush, and full batches no longer trigger a flush until one succeeds. A
+// batch rejected with another 4xx is dropped and returned as a *DroppedError,
+// and the remaining batches are still written.
+func (s *Sink) Flush() error {
+	s.mu.Lock()
+	lines := s.lines
+	s.lines = nil
+	s.mu.Unlock()
+
+	var errs []error
+	retry := false
+	for len(lines) > 0 {
+		n := min(len(lines), s.Config.BatchSize)
+		if err := s.write(lines[:n]); err != nil {
+			var status *statusError
+			if retry = !errors.As(err, &status) || status.retryable(); retry {
+				errs = append(errs, err)
+				break
+			}
+			errs = append(errs, &DroppedError{Lines: lines[:n:n], Err: err})
+		}
+		lines = lines[n:]
+	}
+
+	s.mu.Lock()
+	s.failing = retry
+	if retry {
+		s.lines = append(lines, s.lines...)
+		s.trim()
+	}
+	s.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// trim drops the oldest points beyond MaxBuffered. Must be called with mu held.
+func (s *Sink) trim() {
+	if n := len(s.lines) - s.Config.MaxBuffered; n > 0 {
+		s.lines = append(s.lines[:0:0], s.lines[n:]...)
+		s.dropped += uint64(n)
+	}
+}
+
+// Dropped returns the number of points dropped because the buffer reached
+// MaxBuffered while InfluxDB was unavailable
+func (s *Sink) Dropped() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// Close flushes the remaining points and stops the background flush loop
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	close(s.stop)
+	<-s.done
+	err := s.Flush()
+	s.mu.Lock()
+	lost := len(s.lines)
+	s.lines = nil
+	s.mu.Unlock()
+	if lost > 0 {
+		return fmt.Errorf("%d points not written: %w", lost, err)
+	}
+	return err
+}
+
+func (s *Sink) add(line string) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return fmt.Errorf("sink is closed")
+	}
+	s.lines = append(s.lines, line)
+	s.trim()
+	full := len(s.lines) >= s.Config.BatchSize && !s.failing
+	s.mu.Unlock()
+
+	if full {
+		return s.Flush()
+	}
+	return nil
+}
+
+// write posts one batch of lines to the /api/v2/write endpoint
+func (s *Sink) write(lines []string) error {
+	params := url.Values{}
+	params.Set("org", s.Config.Org)
+	params.Set("bucket", s.Config.Bucket)
+	params.Set("precision", "ms")
+	URL := strings.TrimRight(s.Config.URL, "/") + "/api/v2/write?" + params.Encode()
+
+	body := strings.Join(lines, "\n")
+	req, err := http.NewRequest(http.MethodPost, URL, bytes.NewBufferString(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Token "+s.Config.Token)
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to write %d points: %w", len(lines), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return &statusError{code: resp.StatusCode, body: string(respBody)}
+	}
+	return nil
+}
+
+// statusError is a write rejected by InfluxDB
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("InfluxDB write failed with status code %d: %s", e.code, e.body)
+}
+
+// retryable reports whether the batch may succeed later: server errors and
+// rate limiting, not rejected data
+func (e *statusError) retryable() bool {
+	return e.code >= 500 || e.code == http.StatusTooManyRequests
+}
+
+// flushLoop periodically flushes partially filled batches
+func (s *Sink) flushLoop() {
+	defer close(s.done)
+	if s.Config.FlushInterval < 0 {
+		<-s.stop
+		return
+	}
+
+	ticker := time.NewTicker(s.Config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Flush(); err != nil {
+				s.reportError(err)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *Sink) reportError(err error) {
+	if s.ErrorHandler != nil {
+		s.ErrorHandler(err)
+		return
+	}
+	fmt.Printf("InfluxDB sink error: %v\n", err)
+}
+
+// escapeKey escapes commas, spaces and equals signs in measurement names and tag values
+func escapeKey(s string) string {
+	return strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`).Replace(s)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,242 @@
+// Package kafka publishes WebSocket quotes and candles to Kafka topics keyed by symbol.
+//
+// The SDK does not depend on a Kafka client library. Wrap the producer of
+// your choice (kafka-go, sarama, confluent-kafka-go) in the Producer interface.
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tradermade/Go-SDK/candle"
+	"github.com/tradermade/Go-SDK/sinks"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Message is a single record to be produced
+type Message struct {
+	Topic string
+	Key   []byte
+	Value []byte
+	Time  time.Time
+}
+
+// Producer sends a batch of messages to Kafka
+type Producer interface {
+	Produce(ctx context.Context, msgs []Message) error
+}
+
+// ProducerFunc adapts a plain function to the Producer interface
+type ProducerFunc func(ctx context.Context, msgs []Message) error
+
+// Produce calls f(ctx, msgs)
+func (f ProducerFunc) Produce(ctx context.Context, msgs []Message) error {
+	return f(ctx, msgs)
+}
+
+// Encoder serializes a quote into a message value (JSON, Avro, Protobuf, ...)
+type Encoder func(tradermadews.QuoteMessage) ([]byte, error)
+
+// JSONEncoder encodes quotes as JSON objects
+func JSONEncoder(quote tradermadews.QuoteMessage) ([]byte, error) {
+	return json.Marshal(quote)
+}
+
+// CandleEncoder serializes a candle into a message value
+type CandleEncoder func(candle.Candle) ([]byte, error)
+
+// JSONCandleEncoder encodes candles as JSON objects
+func JSONCandleEncoder(c candle.Candle) ([]byte, error) {
+	return json.Marshal(c)
+}
+
+// Config holds the settings for the publisher
+type Config struct {
+	Topic         string        // Destination topic
+	Encoder       Encoder       // Value encoder (default JSONEncoder)
+	CandleTopic   string        // Destination topic for candles (default Topic)
+	CandleEncoder CandleEncoder // Value encoder for candles (default JSONCandleEncoder)
+	BatchSize     int           // Messages per Produce call (default 100)
+	FlushInterval time.Duration // Flush partially filled batches after this long (default 100ms, negative disables)
+	WriteTimeout  time.Duration // Timeout for each Produce call (default 10s)
+}
+
+// Publisher batches quotes and hands them to a Producer
+type Publisher struct {
+	Producer Producer
+	Config   Config
+
+	// DeliveryErrorHandler receives batches that could not be produced.
+	// Without a handler the error is printed and the batch is dropped.
+	DeliveryErrorHandler func(msgs []Message, err error)
+
+	mu     sync.Mutex
+	buf    []Message
+	stop   chan struct{}
+	done   chan struct{}
+	closed bool
+}
+
+var _ sinks.Sink = (*Publisher)(nil)
+
+// NewPublisher creates a publisher and starts the background flush loop
+func NewPublisher(producer Producer, cfg Config) *Publisher {
+	if cfg.Encoder == nil {
+		cfg.Encoder = JSONEncoder
+	}
+	if cfg.CandleTopic == "" {
+		cfg.CandleTopic = cfg.Topic
+	}
+	if cfg.CandleEncoder == nil {
+		cfg.CandleEncoder = JSONCandleEncoder
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.FlushInterval == 0 {
+		cfg.FlushInterval = 100 * time.Millisecond
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = 10 * time.Second
+	}
+
+	p := &Publisher{
+		Producer: producer,
+		Config:   cfg,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go p.flushLoop()
+	return p
+}
+
+// WriteQuote encodes and buffers a quote, keyed by its symbol
+func (p *Publisher) WriteQuote(quote tradermadews.QuoteMessage) error {
+	value, err := p.Config.Encoder(quote)
+	if err != nil {
+		return fmt.Errorf("failed to encode quote for %s: %w", quote.Symbol, err)
+	}
+	ts, err := quote.Timestamp()
+	if err != nil {
+		ts = time.Now()
+	}
+	return p.add(Message{
+		Topic: p.Config.Topic,
+		Key:   []byte(quote.Symbol),
+		Value: value,
+		Time:  ts,
+	})
+}
+
+// WriteCandle encodes and buffers a candle for the candle topic, keyed by its symbol
+func (p *Publisher) WriteCandle(c candle.Candle) error {
+	value, err := p.Config.CandleEncoder(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode candle for %s: %w", c.Symbol, err)
+	}
+	return p.add(Message{
+		Topic: p.Config.CandleTopic,
+		Key:   []byte(c.Symbol),
+		Value: value,
+		Time:  c.Time,
+	})
+}
+
+// add buffers a message and flushes when the batch is full
+func (p *Publisher) add(msg Message) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return fmt.Errorf("publisher is closed")
+	}
+	p.buf = append(p.buf, msg)
+	full := len(p.buf) >= p.Config.BatchSize
+	p.mu.Unlock()
+
+	if full {
+		return p.Flush()
+	}
+	return nil
+}
+
+// HandleQuote can be passed to WebSocketClient.SetMessageHandler directly
+func (p *Publisher) HandleQuote(quote tradermadews.QuoteMessage, _ string) {
+	if err := p.WriteQuote(quote); err != nil {
+		p.reportError(nil, err)
+	}
+}
+
+// Flush produces all buffered messages. Failed batches are passed to
+// DeliveryErrorHandler and the first error is returned.
+func (p *Publisher) Flush() error {
+	p.mu.Lock()
+	msgs := p.buf
+	p.buf = nil
+	p.mu.Unlock()
+
+	var firstErr error
+	for len(msgs) > 0 {
+		n := len(msgs)
+		if n > p.Config.BatchSize {
+			n = p.Config.BatchSize
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), p.Config.WriteTimeout)
+		err := p.Producer.Produce(ctx, msgs[:n])
+		cancel()
+		if err != nil {
+			p.reportError(msgs[:n], err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		msgs = msgs[n:]
+	}
+	return firstErr
+}
+
+// Close flushes the remaining messages and stops the background flush loop.
+// The underlying producer is not closed.
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	close(p.stop)
+	<-p.done
+	return p.Flush()
+}
+
+// flushLoop periodically flushes partially filled batches
+func (p *Publisher) flushLoop() {
+	defer close(p.done)
+	if p.Config.FlushInterval < 0 {
+		<-p.stop
+		return
+	}
+
+	ticker := time.NewTicker(p.Config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.Flush() // Errors are reported through DeliveryErrorHandler
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+func (p *Publisher) reportError(msgs []Message, err error) {
+	if p.DeliveryErrorHandler != nil {
+		p.DeliveryErrorHandler(msgs, err)
+		return
+	}
+	fmt.Printf("Kafka publish error (%d messages dropped): %v\n", len(msgs), err)
+}
@@ -0,0 +1,93 @@
+// Package mqtt bridges WebSocket quotes to an MQTT broker, one topic per
+// symbol, so dashboards can subscribe to FX rates without a websocket client.
+//
+// The SDK does not depend on an MQTT library. Wrap your client (for example
+// paho.mqtt.golang, waiting on the returned token) in the Client interface.
+package mqtt
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Client publishes a single MQTT message
+type Client interface {
+	Publish(topic string, qos byte, retained bool, payload []byte) error
+}
+
+// ClientFunc adapts a plain function to the Client interface
+type ClientFunc func(topic string, qos byte, retained bool, payload []byte) error
+
+// Publish calls f(topic, qos, retained, payload)
+func (f ClientFunc) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	return f(topic, qos, retained, payload)
+}
+
+// Encoder serializes a quote into an MQTT payload
+type Encoder func(tradermadews.QuoteMessage) ([]byte, error)
+
+// Bridge publishes quotes to MQTT topics
+type Bridge struct {
+	Client       Client
+	TopicPrefix  string      // Topic prefix (default "tradermade/quotes")
+	QoS          byte        // Quality of service level 0, 1 or 2 (default 0)
+	Retained     bool        // Publish as retained so new subscribers get the last value (default true)
+	Encoder      Encoder     // Payload encoder (default JSON)
+	ErrorHandler func(error) // Receives publish errors from HandleQuote
+}
+
+// NewBridge creates a bridge publishing retained QoS 0 messages
+func NewBridge(client Client) *Bridge {
+	return &Bridge{
+		Client:      client,
+		TopicPrefix: "tradermade/quotes",
+		Retained:    true,
+	}
+}
+
+// Topic returns the topic a symbol is published on
+func (b *Bridge) Topic(symbol string) string {
+	prefix := strings.TrimSuffix(b.TopicPrefix, "/")
+	if prefix == "" {
+		return symbol
+	}
+	return prefix + "/" + symbol
+}
+
+// WriteQuote publishes a quote on its symbol topic
+func (b *Bridge) WriteQuote(quote tradermadews.QuoteMessage) error {
+	if b.QoS > 2 {
+		return fmt.Errorf("invalid QoS level: %d", b.QoS)
+	}
+
+	var payload []byte
+	var err error
+	if b.Encoder != nil {
+		payload, err = b.Encoder(quote)
+	} else {
+		payload, err = json.Marshal(quote)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode quote for %s: %w", quote.Symbol, err)
+	}
+
+	topic := b.Topic(quote.Symbol)
+	if err := b.Client.Publish(topic, b.QoS, b.Retained, payload); err != nil {
+		return fmt.Errorf("failed to publish to topic %s: %w", topic, err)
+	}
+	return nil
+}
+
+// HandleQuote can be passed to WebSocketClient.SetMessageHandler directly
+func (b *Bridge) HandleQuote(quote tradermadews.QuoteMessage, _ string) {
+	if err := b.WriteQuote(quote); err != nil {
+		if b.ErrorHandler != nil {
+			b.ErrorHandler(err)
+			return
+		}
+		fmt.Printf("MQTT publish error: %v\n", err)
+	}
+}
@@ -0,0 +1,123 @@
+// Package nats forwards WebSocket quotes onto NATS subjects such as
+// fx.quotes.EURUSD, optionally through JetStream for persistence.
+//
+// The SDK does not depend on nats.go. *nats.Conn satisfies Conn as is;
+// wrap a JetStream context in the JetStream interface to enable persistence.
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Conn publishes core NATS messages (satisfied by *nats.Conn)
+type Conn interface {
+	Publish(subject string, data []byte) error
+}
+
+// JetStream publishes a message to a stream and waits for the ack.
+// msgID should be set as the Nats-Msg-Id header so duplicates are discarded.
+type JetStream interface {
+	Publish(ctx context.Context, subject string, data []byte, msgID string) error
+}
+
+// Encoder serializes a quote into a message payload
+type Encoder func(tradermadews.QuoteMessage) ([]byte, error)
+
+// Publisher forwards quotes to NATS, one subject per symbol
+type Publisher struct {
+	Conn          Conn          // Core NATS connection, used when JetStream is nil
+	JetStream     JetStream     // Optional JetStream publisher for persistence
+	SubjectPrefix string        // Subject prefix (default "fx.quotes")
+	Encoder       Encoder       // Payload encoder (default JSON)
+	AckTimeout    time.Duration // Timeout for JetStream acks (default 5s)
+	ErrorHandler  func(error)   // Receives publish errors from HandleQuote
+}
+
+// NewPublisher creates a publisher using core NATS
+func NewPublisher(conn Conn) *Publisher {
+	return &Publisher{
+		Conn:          conn,
+		SubjectPrefix: "fx.quotes",
+		AckTimeout:    5 * time.Second,
+	}
+}
+
+// NewJetStreamPublisher creates a publisher that persists quotes through JetStream
+func NewJetStreamPublisher(js JetStream) *Publisher {
+	return &Publisher{
+		JetStream:     js,
+		SubjectPrefix: "fx.quotes",
+		AckTimeout:    5 * time.Second,
+	}
+}
+
+// Subject returns the subject a symbol is published on
+func (p *Publisher) Subject(symbol string) string {
+	prefix := strings.TrimSuffix(p.SubjectPrefix, ".")
+	if prefix == "" {
+		return symbol
+	}
+	return prefix + "." + symbol
+}
+
+// WriteQuote publishes a quote on its symbol subject
+func (p *Publisher) WriteQuote(quote tradermadews.QuoteMessage) error {
+	var data []byte
+	var err error
+	if p.Encoder != nil {
+		data, err = p.Encoder(quote)
+	} else {
+		data, err = json.Marshal(quote)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode quote for %s: %w", quote.Symbol, err)
+	}
+
+	subject := p.Subject(quote.Symbol)
+	if p.JetStream != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), p.AckTimeout)
+		defer cancel()
+		if err := p.JetStream.Publish(ctx, subject, data, MsgID(quote)); err != nil {
+			return fmt.Errorf("failed to publish to JetStream subject %s: %w", subject, err)
+		}
+		return nil
+	}
+
+	if p.Conn == nil {
+		return fmt.Errorf("no NATS connection configured")
+	}
+	if err := p.Conn.Publish(subject, data); err != nil {
+		return fmt.Errorf("failed to publish to subject %s: %w", subject, err)
+	}
+	return nil
+}
+
+// MsgID returns the JetStream message ID for a quote. It includes the prices
+// as well as the symbol and timestamp, so only redelivered copies of a quote
+// are discarded, not distinct ticks that share a timestamp.
+func MsgID(quote tradermadews.QuoteMessage) string {
+	return strings.Join([]string{
+		quote.Symbol,
+		quote.Ts,
+		strconv.FormatFloat(quote.Bid, 'g', -1, 64),
+		strconv.FormatFloat(quote.Ask, 'g', -1, 64),
+	}, "-")
+}
+
+// HandleQuote can be passed to WebSocketClient.SetMessageHandler directly
+func (p *Publisher) HandleQuote(quote tradermadews.QuoteMessage, _ string) {
+	if err := p.WriteQuote(quote); err != nil {
+		if p.ErrorHandler != nil {
+			p.ErrorHandler(err)
+			return
+		}
+		fmt.Printf("NATS publish error: %v\n", err)
+	}
+}
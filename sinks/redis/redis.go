@@ -0,0 +1,167 @@
+// Package redis maintains the latest quote per symbol in Redis so a fleet of
+// stateless services can read fresh rates without holding a WebSocket each.
+//
+// Every quote is written to a hash (prefix:EURUSD) and announced on a pub/sub
+// channel. The SDK does not depend on a Redis library; wrap go-redis or redigo
+// in the Client interface.
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tradermade/Go-SDK/candle"
+	"github.com/tradermade/Go-SDK/sinks"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Client is the subset of Redis commands used by the cache
+type Client interface {
+	HSet(ctx context.Context, key string, fields map[string]string) error
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+	Publish(ctx context.Context, channel string, message []byte) error
+}
+
+// Cache writes the last value per symbol to Redis
+type Cache struct {
+	Client       Client
+	KeyPrefix    string        // Hash key prefix (default "tradermade:quote")
+	CandlePrefix string        // Hash key prefix for candles (default "tradermade:candle")
+	Channel      string        // Pub/sub channel, empty disables publishing (default "tradermade:quotes")
+	TTL          time.Duration // Expiry for each hash so dead symbols age out, zero disables
+	Timeout      time.Duration // Timeout for each Redis round trip (default 2s)
+	ErrorHandler func(error)   // Receives errors from HandleQuote
+}
+
+var _ sinks.Sink = (*Cache)(nil)
+
+// NewCache creates a cache with the default key prefix and channel
+func NewCache(client Client) *Cache {
+	return &Cache{
+		Client:       client,
+		KeyPrefix:    "tradermade:quote",
+		CandlePrefix: "tradermade:candle",
+		Channel:      "tradermade:quotes",
+		Timeout:      2 * time.Second,
+	}
+}
+
+// Key returns the hash key used for a symbol
+func (c *Cache) Key(symbol string) string {
+	return c.KeyPrefix + ":" + symbol
+}
+
+// CandleKey returns the hash key used for a symbol's latest candle of an
+// interval, e.g. tradermade:candle:EURUSD:1m0s
+func (c *Cache) CandleKey(symbol string, interval time.Duration) string {
+	key := c.CandlePrefix + ":" + symbol
+	if interval > 0 {
+		key += ":" + interval.String()
+	}
+	return key
+}
+
+// WriteQuote stores a quote as the latest value for its symbol and publishes it
+func (c *Cache) WriteQuote(quote tradermadews.QuoteMessage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	key := c.Key(quote.Symbol)
+	fields := map[string]string{
+		"symbol": quote.Symbol,
+		"bid":    strconv.FormatFloat(quote.Bid, 'f', -1, 64),
+		"ask":    strconv.FormatFloat(quote.Ask, 'f', -1, 64),
+		"mid":    strconv.FormatFloat(quote.Mid, 'f', -1, 64),
+		"ts":     quote.Ts,
+	}
+	if err := c.Client.HSet(ctx, key, fields); err != nil {
+		return fmt.Errorf("failed to store quote for %s: %w", quote.Symbol, err)
+	}
+	if c.TTL > 0 {
+		if err := c.Client.Expire(ctx, key, c.TTL); err != nil {
+			return fmt.Errorf("failed to set expiry on %s: %w", key, err)
+		}
+	}
+
+	if c.Channel != "" {
+		message, err := json.Marshal(quote)
+		if err != nil {
+			return fmt.Errorf("failed to encode quote for %s: %w", quote.Symbol, err)
+		}
+		if err := c.Client.Publish(ctx, c.Channel, message); err != nil {
+			return fmt.Errorf("failed to publish quote for %s: %w", quote.Symbol, err)
+		}
+	}
+	return nil
+}
+
+// WriteCandle stores a candle as the latest bar for its symbol and interval
+func (c *Cache) WriteCandle(bar candle.Candle) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	key := c.CandleKey(bar.Symbol, bar.Interval)
+	fields := map[string]string{
+		"symbol": bar.Symbol,
+		"time":   strconv.FormatInt(bar.Time.UnixMilli(), 10),
+		"open":   strconv.FormatFloat(bar.Open, 'f', -1, 64),
+		"high":   strconv.FormatFloat(bar.High, 'f', -1, 64),
+		"low":    strconv.FormatFloat(bar.Low, 'f', -1, 64),
+		"close":  strconv.FormatFloat(bar.Close, 'f', -1, 64),
+		"ticks":  strconv.Itoa(bar.Ticks),
+	}
+	if err := c.Client.HSet(ctx, key, fields); err != nil {
+		return fmt.Errorf("failed to store candle for %s: %w", bar.Symbol, err)
+	}
+	if c.TTL > 0 {
+		if err := c.Client.Expire(ctx, key, c.TTL); err != nil {
+			return fmt.Errorf("failed to set expiry on %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// Flush does nothing; every write reaches Redis immediately
+func (c *Cache) Flush() error {
+	return nil
+}
+
+// Close does nothing; the Redis client is left open
+func (c *Cache) Close() error {
+	return nil
+}
+
+// HandleQuote can be passed to WebSocketClient.SetMessageHandler directly
+func (c *Cache) HandleQuote(quote tradermadews.QuoteMessage, _ string) {
+	if err := c.WriteQuote(quote); err != nil {
+		if c.ErrorHandler != nil {
+			c.ErrorHandler(err)
+			return
+		}
+		fmt.Printf("Redis cache error: %v\n", err)
+	}
+}
+
+// GetQuote reads the latest quote for a symbol. The boolean is false when
+// nothing has been stored for the symbol (or it has expired).
+func (c *Cache) GetQuote(ctx context.Context, symbol string) (tradermadews.QuoteMessage, bool, error) {
+	fields, err := c.Client.HGetAll(ctx, c.Key(symbol))
+	if err != nil {
+		return tradermadews.QuoteMessage{}, false, fmt.Errorf("failed to read quote for %s: %w", symbol, err)
+	}
+	if len(fields) == 0 {
+		return tradermadews.QuoteMessage{}, false, nil
+	}
+
+	quote := tradermadews.QuoteMessage{Symbol: fields["symbol"], Ts: fields["ts"]}
+	for name, dst := range map[string]*float64{"bid": &quote.Bid, "ask": &quote.Ask, "mid": &quote.Mid} {
+		if *dst, err = strconv.ParseFloat(fields[name], 64); err != nil {
+			return tradermadews.QuoteMessage{}, false, fmt.Errorf("invalid %s for %s: %w", name, symbol, err)
+		}
+	}
+	return quote, true, nil
+}
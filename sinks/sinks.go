@@ -0,0 +1,137 @@
+// Package sinks defines the interface shared by the quote and candle
+// destinations in its subpackages, and a Registry that writes to several of
+// them at once. See feed.Pipe for wiring a feed into sinks.
+package sinks
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/tradermade/Go-SDK/candle"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Sink is a destination for quotes and candles. Writes may be buffered until
+// Flush; Close flushes and releases the sink's own resources, leaving the
+// underlying client or connection open.
+type Sink interface {
+	WriteQuote(tradermadews.QuoteMessage) error
+	WriteCandle(candle.Candle) error
+	Flush() error
+	Close() error
+}
+
+// QuoteWriter is implemented by destinations that only accept quotes, such
+// as the MQTT and NATS bridges
+type QuoteWriter interface {
+	WriteQuote(tradermadews.QuoteMessage) error
+}
+
+// QuotesOnly adapts a QuoteWriter to Sink. Candles are ignored, and Flush
+// and Close do nothing.
+func QuotesOnly(w QuoteWriter) Sink {
+	return quotesOnly{w}
+}
+
+type quotesOnly struct {
+	QuoteWriter
+}
+
+func (quotesOnly) WriteCandle(candle.Candle) error { return nil }
+func (quotesOnly) Flush() error                    { return nil }
+func (quotesOnly) Close() error                    { return nil }
+
+// Registry is a named set of sinks. It is itself a Sink that writes to every
+// registered sink in registration order, so one write fans out to all.
+type Registry struct {
+	mu    sync.RWMutex
+	names []string
+	sinks map[string]Sink
+}
+
+var _ Sink = (*Registry)(nil)
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{sinks: make(map[string]Sink)}
+}
+
+// Register adds a sink under name. Names must be unique.
+func (r *Registry) Register(name string, s Sink) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.sinks[name]; ok {
+		return fmt.Errorf("sink %q is already registered", name)
+	}
+	r.sinks[name] = s
+	r.names = append(r.names, name)
+	return nil
+}
+
+// Unregister removes a sink without closing it. The boolean is false if no
+// sink was registered under name.
+func (r *Registry) Unregister(name string) (Sink, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.sinks[name]
+	if !ok {
+		return nil, false
+	}
+	delete(r.sinks, name)
+	for i, n := range r.names {
+		if n == name {
+			r.names = append(r.names[:i], r.names[i+1:]...)
+			break
+		}
+	}
+	return s, true
+}
+
+// Get returns the sink registered under name
+func (r *Registry) Get(name string) (Sink, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.sinks[name]
+	return s, ok
+}
+
+// Names returns the registered names in registration order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string(nil), r.names...)
+}
+
+// WriteQuote writes a quote to every sink. A failing sink does not stop the
+// others; their errors are joined, each prefixed with the sink's name.
+func (r *Registry) WriteQuote(quote tradermadews.QuoteMessage) error {
+	return r.each(func(s Sink) error { return s.WriteQuote(quote) })
+}
+
+// WriteCandle writes a candle to every sink
+func (r *Registry) WriteCandle(c candle.Candle) error {
+	return r.each(func(s Sink) error { return s.WriteCandle(c) })
+}
+
+// Flush flushes every sink
+func (r *Registry) Flush() error {
+	return r.each(Sink.Flush)
+}
+
+// Close closes every sink
+func (r *Registry) Close() error {
+	return r.each(Sink.Close)
+}
+
+func (r *Registry) each(fn func(Sink) error) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var errs []error
+	for _, name := range r.names {
+		if err := fn(r.sinks[name]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
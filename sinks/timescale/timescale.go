@@ -0,0 +1,456 @@
+// Package timescale archives WebSocket quotes and candles into PostgreSQL/TimescaleDB
+// using database/sql. Any PostgreSQL driver (lib/pq, pgx/stdlib) can be used.
+package timescale
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tradermade/Go-SDK/candle"
+	"github.com/tradermade/Go-SDK/sinks"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// ConflictPolicy controls what happens when a row with the same symbol and time already exists
+type ConflictPolicy int
+
+const (
+	ConflictIgnore ConflictPolicy = iota // ON CONFLICT DO NOTHING
+	ConflictUpdate                       // ON CONFLICT DO UPDATE with the new bid/ask/mid
+	ConflictError                        // No ON CONFLICT clause, duplicates fail the batch
+)
+
+// Config holds the settings for the sink
+type Config struct {
+	Table         string           // Table name (default "quotes")
+	CandleTable   string           // Table name for candles (default "candles")
+	BatchSize     int              // Rows per INSERT statement (default 500)
+	FlushInterval time.Duration    // Flush partially filled batches after this long (default 1s, negative disables)
+	OnConflict    ConflictPolicy   // Conflict handling for duplicate (symbol, time) rows
+	MaxBuffered   int              // Quotes and candles each kept while the database is unavailable; the oldest are dropped beyond it (default 100000)
+	Retryable     func(error) bool // Whether a failed batch is kept for the next flush (default IsRetryable); other failed batches are dropped
+}
+
+// DroppedError reports a batch dropped after a permanent failure, such as a
+// constraint violation, with its rows so they can be dead-lettered
+type DroppedError struct {
+	Quotes  []tradermadews.QuoteMessage
+	Candles []candle.Candle
+	Err     error
+}
+
+func (e *DroppedError) Error() string {
+	return fmt.Sprintf("dropped %d quotes and %d candles: %v", len(e.Quotes), len(e.Candles), e.Err)
+}
+
+func (e *DroppedError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryable reports whether err is likely temporary: a lost connection, a
+// timeout, or a PostgreSQL error in the connection, transaction rollback,
+// resource or operator intervention classes. It recognises any driver error
+// with a SQLState method, as lib/pq and pgx provide.
+func IsRetryable(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var pgErr interface{ SQLState() string }
+	if errors.As(err, &pgErr) {
+		switch state := pgErr.SQLState(); {
+		case len(state) < 2:
+		case strings.HasPrefix(state, "08"), strings.HasPrefix(state, "40"), strings.HasPrefix(state, "53"),
+			strings.HasPrefix(state, "57"), strings.HasPrefix(state, "58"):
+			return true
+		}
+	}
+	return false
+}
+
+// Sink buffers quotes and writes them to the database in batches
+type Sink struct {
+	DB           *sql.DB
+	Config       Config
+	ErrorHandler func(error) // Receives errors from background flushes and HandleQuote
+
+	mu      sync.Mutex
+	buf     []tradermadews.QuoteMessage
+	bars    []candle.Candle
+	failing bool   // The last flush failed with a retryable error; writes leave retries to the flush loop
+	dropped uint64 // Rows dropped by MaxBuffered
+	stop    chan struct{}
+	done    chan struct{}
+	closed  bool
+}
+
+var _ sinks.Sink = (*Sink)(nil)
+
+// NewSink creates a sink writing to db and starts the background flush loop
+func NewSink(db *sql.DB, cfg Config) *Sink {
+	if cfg.Table == "" {
+		cfg.Table = "quotes"
+	}
+	if cfg.CandleTable == "" {
+		cfg.CandleTable = "candles"
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	if cfg.FlushInterval == 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.MaxBuffered <= 0 {
+		cfg.MaxBuffered = 100000
+	}
+	if cfg.Retryable == nil {
+		cfg.Retryable = IsRetryable
+	}
+
+	s := &Sink{
+		DB:     db,
+		Config: cfg,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go s.flushLoop()
+	return s
+}
+
+// CreateSchema creates the quotes and candles tables and converts them to
+// hypertables. The create_hypertable calls are skipped on plain PostgreSQL
+// without the extension.
+func (s *Sink) CreateSchema(ctx context.Context) error {
+	quotes := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	time   TIMESTAMPTZ      NOT NULL,
+	symbol TEXT             NOT NULL,
+	bid    DOUBLE PRECISION NOT NULL,
+	ask    DOUBLE PRECISION NOT NULL,
+	mid    DOUBLE PRECISION NOT NULL,
+	PRIMARY KEY (symbol, time)
+)`, s.Config.Table)
+	candles := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	time        TIMESTAMPTZ      NOT NULL,
+	symbol      TEXT             NOT NULL,
+	interval_ms BIGINT           NOT NULL,
+	open        DOUBLE PRECISION NOT NULL,
+	high        DOUBLE PRECISION NOT NULL,
+	low         DOUBLE PRECISION NOT NULL,
+	close       DOUBLE PRECISION NOT NULL,
+	ticks       INTEGER          NOT NULL,
+	PRIMARY KEY (symbol, interval_ms, time)
+)`, s.Config.CandleTable)
+	if _, err := s.DB.ExecContext(ctx, quotes); err != nil {
+		return fmt.Errorf("failed to create table %s: %w", s.Config.Table, err)
+	}
+	if _, err := s.DB.ExecContext(ctx, candles); err != nil {
+		return fmt.Errorf("failed to create table %s: %w", s.Config.CandleTable, err)
+	}
+
+	var hasTimescale bool
+	row := s.DB.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'timescaledb')`)
+	if err := row.Scan(&hasTimescale); err != nil {
+		return fmt.Errorf("failed to check for timescaledb extension: %w", err)
+	}
+	if !hasTimescale {
+		return nil
+	}
+
+	for _, table := range []string{s.Config.Table, s.Config.CandleTable} {
+		hypertable := fmt.Sprintf(`SELECT create_hypertable('%s', 'time', if_not_exists => TRUE)`, table)
+		if _, err := s.DB.ExecContext(ctx, hypertable); err != nil {
+			return fmt.Errorf("failed to create hypertable %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// WriteQuote buffers a quote and flushes when the batch is full
+func (s *Sink) WriteQuote(quote tradermadews.QuoteMessage) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return fmt.Errorf("sink is closed")
+	}
+	s.buf = append(s.buf, quote)
+	s.trim()
+	full := len(s.buf) >= s.Config.BatchSize && !s.failing
+	s.mu.Unlock()
+
+	if full {
+		return s.Flush()
+	}
+	return nil
+}
+
+// WriteCandle buffers a candle and flushes when the batch is full
+func (s *Sink) WriteCandle(c candle.Candle) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return fmt.Errorf("sink is closed")
+	}
+	s.bars = append(s.bars, c)
+	s.trim()
+	full := len(s.bars) >= s.Config.BatchSize && !s.failing
+	s.mu.Unlock()
+
+	if full {
+		return s.Flush()
+	}
+	return nil
+}
+
+// HandleQuote can be passed to WebSocketClient.SetMessageHandler directly
+func (s *Sink) HandleQuote(quote tradermadews.QuoteMessage, _ string) {
+	if err := s.WriteQuote(quote); err != nil {
+		s.reportError(err)
+	}
+}
+
+// Flush writes all buffered quotes and candles to the database. After a
+// retryable failure the failed batch and the rest of the buffer are kept for
+// the next flush, and full batches no longer trigger a flush until one
+// succeeds. A batch failing permanently is dropped and returned as a
+// *DroppedError, and the remaining batches are still written.
+func (s *Sink) Flush() error {
+	s.mu.Lock()
+	batch, bars := s.buf, s.bars
+	s.buf, s.bars = nil, nil
+	s.mu.Unlock()
+
+	var errs []error
+	retry := false
+	for len(batch) > 0 && !retry {
+		n := min(len(batch), s.Config.BatchSize)
+		if err := s.insert(batch[:n]); err != nil {
+			if retry = s.Config.Retryable(err); retry {
+				errs = append(errs, err)
+				break
+			}
+			errs = append(errs, &DroppedError{Quotes: batch[:n:n], Err: err})
+		}
+		batch = batch[n:]
+	}
+	for len(bars) > 0 && !retry {
+		n := min(len(bars), s.Config.BatchSize)
+		if err := s.insertCandles(bars[:n]); err != nil {
+			if retry = s.Config.Retryable(err); retry {
+				errs = append(errs, err)
+				break
+			}
+			errs = append(errs, &DroppedError{Candles: bars[:n:n], Err: err})
+		}
+		bars = bars[n:]
+	}
+
+	s.mu.Lock()
+	s.failing = retry
+	if retry {
+		s.buf = append(batch, s.buf...)
+		s.bars = append(bars, s.bars...)
+		s.trim()
+	}
+	s.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// trim drops the oldest rows beyond MaxBuffered. Must be called with mu held.
+func (s *Sink) trim() {
+	if n := len(s.buf) - s.Config.MaxBuffered; n > 0 {
+		s.buf = append(s.buf[:0:0], s.buf[n:]...)
+		s.dropped += uint64(n)
+	}
+	if n := len(s.bars) - s.Config.MaxBuffered; n > 0 {
+		s.bars = append(s.bars[:0:0], s.bars[n:]...)
+		s.dropped += uint64(n)
+	}
+}
+
+// Dropped returns the number of quotes and candles dropped because the
+// buffer reached MaxBuffered while the database was unavailable
+func (s *Sink) Dropped() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// Close flushes the remaining quotes and stops the background flush loop.
+// The database handle is left open.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	close(s.stop)
+	<-s.done
+	err := s.Flush()
+	s.mu.Lock()
+	lost := len(s.buf) + len(s.bars)
+	s.buf, s.bars = nil, nil
+	s.mu.Unlock()
+	if lost > 0 {
+		return fmt.Errorf("%d rows not written: %w", lost, err)
+	}
+	return err
+}
+
+// insert writes one batch using a single multi-row INSERT
+func (s *Sink) insert(batch []tradermadews.QuoteMessage) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "INSERT INTO %s (time, symbol, bid, ask, mid) VALUES ", s.Config.Table)
+
+	type quoteRow struct {
+		time  time.Time
+		quote tradermadews.QuoteMessage
+	}
+	type rowKey struct {
+		symbol string
+		time   int64
+	}
+	rows := make([]quoteRow, 0, len(batch))
+	index := make(map[rowKey]int) // Row of each symbol and time, for ConflictUpdate
+	for _, quote := range batch {
+		ts, err := quote.Timestamp()
+		if err != nil {
+			s.reportError(fmt.Errorf("skipping quote for %s with invalid timestamp %q: %w", quote.Symbol, quote.Ts, err))
+			continue
+		}
+		row := quoteRow{time: ts.UTC(), quote: quote}
+		// Postgres cannot update the same row twice in one statement, so the last quote wins
+		if s.Config.OnConflict == ConflictUpdate {
+			key := rowKey{quote.Symbol, row.time.UnixNano()}
+			if i, ok := index[key]; ok {
+				rows[i] = row
+				continue
+			}
+			index[key] = len(rows)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, 0, len(rows)*5)
+	for i, row := range rows {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		n := len(args)
+		fmt.Fprintf(&sb, "($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5)
+		args = append(args, row.time, row.quote.Symbol, row.quote.Bid, row.quote.Ask, row.quote.Mid)
+	}
+
+	switch s.Config.OnConflict {
+	case ConflictIgnore:
+		sb.WriteString(" ON CONFLICT (symbol, time) DO NOTHING")
+	case ConflictUpdate:
+		sb.WriteString(" ON CONFLICT (symbol, time) DO UPDATE SET bid = EXCLUDED.bid, ask = EXCLUDED.ask, mid = EXCLUDED.mid")
+	}
+
+	if _, err := s.DB.Exec(sb.String(), args...); err != nil {
+		return fmt.Errorf("failed to insert %d quotes: %w", len(rows), err)
+	}
+	return nil
+}
+
+// insertCandles writes one batch of candles using a single multi-row INSERT
+func (s *Sink) insertCandles(bars []candle.Candle) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "INSERT INTO %s (time, symbol, interval_ms, open, high, low, close, ticks) VALUES ", s.Config.CandleTable)
+
+	if s.Config.OnConflict == ConflictUpdate {
+		bars = dedupeCandles(bars)
+	}
+	args := make([]interface{}, 0, len(bars)*8)
+	for i, c := range bars {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		n := len(args)
+		fmt.Fprintf(&sb, "($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8)
+		args = append(args, c.Time.UTC(), c.Symbol, c.Interval.Milliseconds(), c.Open, c.High, c.Low, c.Close, c.Ticks)
+	}
+
+	switch s.Config.OnConflict {
+	case ConflictIgnore:
+		sb.WriteString(" ON CONFLICT (symbol, interval_ms, time) DO NOTHING")
+	case ConflictUpdate:
+		sb.WriteString(" ON CONFLICT (symbol, interval_ms, time) DO UPDATE SET open = EXCLUDED.open, high = EXCLUDED.high, low = EXCLUDED.low, close = EXCLUDED.close, ticks = EXCLUDED.ticks")
+	}
+
+	if _, err := s.DB.Exec(sb.String(), args...); err != nil {
+		return fmt.Errorf("failed to insert %d candles: %w", len(bars), err)
+	}
+	return nil
+}
+
+// dedupeCandles keeps the last candle of each symbol, interval and time, in
+// the position of the first, as Postgres cannot update the same row twice in
+// one statement
+func dedupeCandles(bars []candle.Candle) []candle.Candle {
+	type key struct {
+		symbol   string
+		interval time.Duration
+		time     int64
+	}
+	index := make(map[key]int, len(bars))
+	out := make([]candle.Candle, 0, len(bars))
+	for _, c := range bars {
+		k := key{c.Symbol, c.Interval, c.Time.UnixNano()}
+		if i, ok := index[k]; ok {
+			out[i] = c
+			continue
+		}
+		index[k] = len(out)
+		out = append(out, c)
+	}
+	return out
+}
+
+// flushLoop periodically flushes partially filled batches
+func (s *Sink) flushLoop() {
+	defer close(s.done)
+	if s.Config.FlushInterval < 0 {
+		<-s.stop
+		return
+	}
+
+	ticker := time.NewTicker(s.Config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Flush(); err != nil {
+				s.reportError(err)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *Sink) reportError(err error) {
+	if s.ErrorHandler != nil {
+		s.ErrorHandler(err)
+		return
+	}
+	fmt.Printf("Timescale sink error: %v\n", err)
+}
@@ -0,0 +1,244 @@
+// Package webhook POSTs alert events and quote snapshots to webhook URLs, so
+// Slack, Discord, Telegram and similar services can be notified with a body
+// template instead of a dedicated integration.
+//
+// Requests are signed with HMAC-SHA256 when a secret is set. The signature of
+// the raw body is sent hex encoded in the X-Signature-256 header as
+// "sha256=<hex>", the same scheme GitHub webhooks use.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/tradermade/Go-SDK/alerts"
+)
+
+// Notifier posts payloads to every configured URL
+type Notifier struct {
+	URLs          []string
+	Secret        string             // HMAC-SHA256 signing key, empty disables signing
+	Template      *template.Template // Renders the body, nil sends the payload as JSON
+	ContentType   string             // Body content type (default application/json)
+	Headers       map[string]string  // Extra request headers
+	HTTPClient    *http.Client       // Client for requests (default 10s timeout)
+	MaxRetries    int                // Attempts per URL (default 3)
+	RetryInterval time.Duration      // Initial delay between attempts, doubled each retry (default 1s)
+	ErrorHandler  func(error)        // Receives delivery errors of queued notifications
+
+	queue  chan any
+	mu     sync.Mutex
+	closed bool
+	done   chan struct{}
+}
+
+// NewNotifier creates a notifier and starts the worker delivering queued notifications
+func NewNotifier(urls ...string) *Notifier {
+	n := &Notifier{
+		URLs:          urls,
+		ContentType:   "application/json",
+		HTTPClient:    &http.Client{Timeout: 10 * time.Second},
+		MaxRetries:    3,
+		RetryInterval: time.Second,
+		queue:         make(chan any, 64),
+		done:          make(chan struct{}),
+	}
+	go n.worker()
+	return n
+}
+
+// ParseTemplate parses a body template. Besides the payload's fields, it can
+// use the json function to embed values safely in JSON bodies, e.g. a Slack
+// message:
+//
+//	{"text": {{json (printf "%s triggered for %s at %.5f" .Rule .Symbol .Value)}}}
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("webhook").Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(text)
+}
+
+// SetTemplate parses text with ParseTemplate and uses it for request bodies
+func (n *Notifier) SetTemplate(text string) error {
+	tmpl, err := ParseTemplate(text)
+	if err != nil {
+		return fmt.Errorf("failed to parse webhook template: %w", err)
+	}
+	n.Template = tmpl
+	return nil
+}
+
+// SetErrorHandler sets the callback function to handle delivery errors
+func (n *Notifier) SetErrorHandler(handler func(error)) {
+	n.ErrorHandler = handler
+}
+
+// Notify renders payload and posts it to every URL, retrying failed
+// deliveries. It returns the errors of URLs that failed every attempt.
+func (n *Notifier) Notify(ctx context.Context, payload any) error {
+	body, err := n.render(payload)
+	if err != nil {
+		return err
+	}
+
+	var failed []error
+	for _, url := range n.URLs {
+		if err := n.post(ctx, url, body); err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) == 1 {
+		return failed[0]
+	}
+	if len(failed) > 1 {
+		return fmt.Errorf("%d webhooks failed, first: %w", len(failed), failed[0])
+	}
+	return nil
+}
+
+// HandleAlert queues an alert event for delivery. It can be passed to
+// alerts.Engine.SetHandler. Events are dropped with an error when the queue is full.
+func (n *Notifier) HandleAlert(event alerts.Event) {
+	n.enqueue(event)
+}
+
+// Enqueue queues any payload, e.g. a client.Snapshot(), for delivery in the background
+func (n *Notifier) Enqueue(payload any) {
+	n.enqueue(payload)
+}
+
+// Close delivers the queued notifications and stops the worker
+func (n *Notifier) Close() error {
+	n.mu.Lock()
+	if n.closed {
+		n.mu.Unlock()
+		return nil
+	}
+	n.closed = true
+	close(n.queue)
+	n.mu.Unlock()
+
+	<-n.done
+	return nil
+}
+
+func (n *Notifier) enqueue(payload any) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		n.reportError(fmt.Errorf("webhook notifier is closed"))
+		return
+	}
+	select {
+	case n.queue <- payload:
+	default:
+		n.reportError(fmt.Errorf("webhook queue full, dropping notification"))
+	}
+}
+
+func (n *Notifier) worker() {
+	defer close(n.done)
+	for payload := range n.queue {
+		if err := n.Notify(context.Background(), payload); err != nil {
+			n.reportError(err)
+		}
+	}
+}
+
+// render produces the request body for payload
+func (n *Notifier) render(payload any) ([]byte, error) {
+	if n.Template == nil {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode webhook payload: %w", err)
+		}
+		return body, nil
+	}
+
+	var buf bytes.Buffer
+	if err := n.Template.Execute(&buf, payload); err != nil {
+		return nil, fmt.Errorf("failed to render webhook template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// post delivers body to url with retries
+func (n *Notifier) post(ctx context.Context, url string, body []byte) error {
+	delay := n.RetryInterval
+	attempts := n.MaxRetries
+	if attempts <= 0 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = n.send(ctx, url, body); err == nil {
+			return nil
+		}
+		if attempt < attempts {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return fmt.Errorf("failed to deliver webhook to %s: %w", url, ctx.Err())
+			}
+			delay *= 2
+		}
+	}
+	return fmt.Errorf("failed to deliver webhook to %s after %d attempts: %w", url, attempts, err)
+}
+
+// send makes one delivery attempt
+func (n *Notifier) send(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", n.ContentType)
+	for name, value := range n.Headers {
+		req.Header.Set(name, value)
+	}
+	if n.Secret != "" {
+		req.Header.Set("X-Signature-256", "sha256="+Sign(n.Secret, body))
+	}
+
+	resp, err := n.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of body, for verifying requests on
+// the receiving side
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (n *Notifier) reportError(err error) {
+	if n.ErrorHandler != nil {
+		n.ErrorHandler(err)
+		return
+	}
+	fmt.Printf("Webhook error: %v\n", err)
+}
@@ -0,0 +1,182 @@
+// Package snapshot turns a quote stream into periodic, consistent views of
+// the latest prices of many symbols, for valuation loops that need every
+// price from the same instant:
+//
+//	snap := snapshot.New(500*time.Millisecond, "EURUSD", "GBPUSD", "USDJPY")
+//	client.SetMessageHandler(snap.HandleQuote)
+//	snap.Start()
+//	for s := range snap.Snapshots() {
+//		revalue(s.Quotes)
+//	}
+package snapshot
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tradermade/Go-SDK/clock"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Snapshot is the latest quote of every symbol at one instant
+type Snapshot struct {
+	Time     time.Time                            // When the snapshot was taken
+	Quotes   map[string]tradermadews.QuoteMessage // Latest quote by symbol
+	Complete bool                                 // Whether every expected symbol has a quote
+	Changed  []string                             // Symbols quoted since the previous snapshot
+}
+
+// Mid returns the latest mid price of a symbol
+func (s Snapshot) Mid(symbol string) (float64, bool) {
+	q, ok := s.Quotes[symbol]
+	return q.Mid, ok
+}
+
+// Snapshotter keeps the latest quote per symbol and emits a snapshot of all
+// of them every Interval
+type Snapshotter struct {
+	Interval      time.Duration  // Time between snapshots (default 1s)
+	Symbols       []string       // Symbols expected in every snapshot, empty means every symbol seen
+	OnSnapshot    func(Snapshot) // Called with every snapshot
+	EmitUnchanged bool           // Emit snapshots when no quote arrived since the previous one
+	WaitComplete  bool           // Hold snapshots back until every expected symbol has a quote
+	Clock         clock.Clock    // Times snapshots, nil uses the system clock
+
+	mu        sync.Mutex
+	latest    map[string]tradermadews.QuoteMessage
+	changed   map[string]bool
+	snapshots chan Snapshot
+	stop      chan struct{}
+}
+
+// New creates a snapshotter emitting every interval. Symbols, if given, are
+// the symbols each snapshot is expected to contain.
+func New(interval time.Duration, symbols ...string) *Snapshotter {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return &Snapshotter{
+		Interval:  interval,
+		Symbols:   symbols,
+		latest:    make(map[string]tradermadews.QuoteMessage),
+		changed:   make(map[string]bool),
+		snapshots: make(chan Snapshot, 16),
+	}
+}
+
+// SetSnapshotHandler sets the callback function for snapshots
+func (s *Snapshotter) SetSnapshotHandler(handler func(Snapshot)) {
+	s.OnSnapshot = handler
+}
+
+// HandleQuote matches the WebSocket client's message handler signature
+func (s *Snapshotter) HandleQuote(quote tradermadews.QuoteMessage, _ string) {
+	s.AddQuote(quote)
+}
+
+// AddQuote records a quote as its symbol's latest
+func (s *Snapshotter) AddQuote(quote tradermadews.QuoteMessage) {
+	s.mu.Lock()
+	s.latest[quote.Symbol] = quote
+	s.changed[quote.Symbol] = true
+	s.mu.Unlock()
+}
+
+// Snapshot returns the latest quotes now, without waiting for the interval
+func (s *Snapshotter) Snapshot() Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.take(clock.OrDefault(s.Clock).Now())
+}
+
+// take copies the latest quotes. Must be called with mu held.
+func (s *Snapshotter) take(now time.Time) Snapshot {
+	snap := Snapshot{Time: now, Complete: true}
+	if len(s.Symbols) > 0 {
+		snap.Quotes = make(map[string]tradermadews.QuoteMessage, len(s.Symbols))
+		for _, symbol := range s.Symbols {
+			if q, ok := s.latest[symbol]; ok {
+				snap.Quotes[symbol] = q
+			} else {
+				snap.Complete = false
+			}
+		}
+	} else {
+		snap.Quotes = make(map[string]tradermadews.QuoteMessage, len(s.latest))
+		for symbol, q := range s.latest {
+			snap.Quotes[symbol] = q
+		}
+	}
+	for symbol := range s.changed {
+		if _, ok := snap.Quotes[symbol]; ok {
+			snap.Changed = append(snap.Changed, symbol)
+		}
+	}
+	sort.Strings(snap.Changed)
+	return snap
+}
+
+// Snapshots returns a channel receiving every snapshot. Snapshots are dropped
+// when the channel's buffer is full.
+func (s *Snapshotter) Snapshots() <-chan Snapshot {
+	return s.snapshots
+}
+
+// Start emits snapshots every Interval in the background until Stop
+func (s *Snapshotter) Start() {
+	s.mu.Lock()
+	if s.stop != nil {
+		s.mu.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	s.stop = stop
+	s.mu.Unlock()
+
+	go func() {
+		ticker := clock.OrDefault(s.Clock).NewTicker(s.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C():
+				s.tick(now)
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops emitting snapshots
+func (s *Snapshotter) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+func (s *Snapshotter) tick(now time.Time) {
+	s.mu.Lock()
+	if len(s.changed) == 0 && !s.EmitUnchanged {
+		s.mu.Unlock()
+		return
+	}
+	snap := s.take(now)
+	if !snap.Complete && s.WaitComplete {
+		s.mu.Unlock()
+		return // Keep the changes for the first complete snapshot
+	}
+	s.changed = make(map[string]bool)
+	s.mu.Unlock()
+
+	if s.OnSnapshot != nil {
+		s.OnSnapshot(snap)
+	}
+	select {
+	case s.snapshots <- snap:
+	default:
+	}
+}
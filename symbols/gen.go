@@ -0,0 +1,188 @@
+//go:build ignore
+
+// gen.go writes symbols_gen.go. With TRADERMADE_API_KEY set it reads the
+// API's currency, crypto and CFD lists; without it, it falls back to the
+// currency package's built-in ISO 4217 table, crypto and instrument names.
+//
+//	TRADERMADE_API_KEY=... go generate ./symbols
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tradermade/Go-SDK/currency"
+	"github.com/tradermade/Go-SDK/internal/redact"
+	tradermade "github.com/tradermade/Go-SDK/rest"
+)
+
+// priority orders currencies by market convention: a pair's base is the
+// currency listed first. Unlisted currencies are quoted against USD.
+var priority = []string{"XAU", "XAG", "XPT", "XPD", "EUR", "GBP", "AUD", "NZD", "USD", "CAD", "CHF", "NOK", "SEK", "JPY"}
+
+// crosses are the currencies quoted against each other, not just against USD
+var crosses = map[string]bool{"EUR": true, "GBP": true, "AUD": true, "NZD": true, "USD": true, "CAD": true, "CHF": true, "NOK": true, "SEK": true, "JPY": true}
+
+type symbol struct {
+	name, code, comment string
+}
+
+func main() {
+	key := os.Getenv("TRADERMADE_API_KEY")
+	source := "the built-in ISO 4217 table"
+
+	currencies := map[string]string{}
+	for _, c := range currency.All() {
+		currencies[c.Code] = c.Name
+	}
+	instruments := currency.Instruments
+	crypto := currency.Cryptos
+
+	if key != "" {
+		source = "the TraderMade list endpoints"
+		var err error
+		if currencies, err = fetchList("live_currencies_list", key); err != nil {
+			log.Fatal(err)
+		}
+		if crypto, err = fetchList("live_crypto_list", key); err != nil {
+			log.Fatal(err)
+		}
+		if instruments, err = fetchList("cfd_list", key); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var fx, metals, cryptos, cfds []symbol
+	for base := range currencies {
+		for quote := range currencies {
+			if base == quote || !quotedAs(base, quote) {
+				continue
+			}
+			if !(crosses[base] && crosses[quote]) && base != "USD" && quote != "USD" {
+				continue
+			}
+			s := symbol{base + quote, base + quote, currencies[base] + " / " + currencies[quote]}
+			if strings.HasPrefix(base, "X") && rank(base) < 4 {
+				metals = append(metals, s)
+			} else {
+				fx = append(fx, s)
+			}
+		}
+	}
+	for code, name := range crypto {
+		cryptos = append(cryptos, symbol{identifier(code) + "USD", code + "USD", name + " / US Dollar"})
+	}
+	for code, name := range instruments {
+		cfds = append(cfds, symbol{identifier(code), code, name})
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by gen.go from %s; DO NOT EDIT.\n\npackage symbols\n", source)
+	writeGroup(&buf, "FX pairs", fx)
+	writeGroup(&buf, "Metals", metals)
+	writeGroup(&buf, "Crypto", cryptos)
+	writeGroup(&buf, "CFDs", cfds)
+
+	var all []symbol
+	for _, group := range [][]symbol{fx, metals, cryptos, cfds} {
+		all = append(all, group...)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].code < all[j].code })
+	fmt.Fprint(&buf, "\n// all lists every generated symbol in order\nvar all = []string{\n")
+	for _, s := range all {
+		fmt.Fprintf(&buf, "\t%s,\n", s.name)
+	}
+	fmt.Fprint(&buf, "}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("failed to format generated code: %v", err)
+	}
+	if err := os.WriteFile("symbols_gen.go", src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// rank returns a currency's position in priority, or len(priority) if unlisted
+func rank(code string) int {
+	for i, c := range priority {
+		if c == code {
+			return i
+		}
+	}
+	return len(priority)
+}
+
+// quotedAs reports whether base/quote is the conventional order of the pair
+func quotedAs(base, quote string) bool {
+	rb, rq := rank(base), rank(quote)
+	if rb == rq {
+		return base < quote
+	}
+	return rb < rq
+}
+
+// identifier turns an instrument code into an exported Go identifier
+func identifier(code string) string {
+	var b strings.Builder
+	for _, r := range code {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	id := strings.ToUpper(b.String())
+	if id == "" || id[0] >= '0' && id[0] <= '9' {
+		id = "I" + id
+	}
+	return id
+}
+
+func writeGroup(buf *bytes.Buffer, title string, symbols []symbol) {
+	if len(symbols) == 0 {
+		return
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i].name < symbols[j].name })
+	fmt.Fprintf(buf, "\n// %s\nconst (\n", title)
+	for _, s := range symbols {
+		fmt.Fprintf(buf, "\t%s = %q // %s\n", s.name, s.code, s.comment)
+	}
+	fmt.Fprint(buf, ")\n")
+}
+
+// fetchList reads a list endpoint, returning the first object of names by code in its response
+func fetchList(endpoint, key string) (map[string]string, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(fmt.Sprintf("%s/%s?api_key=%s", tradermade.DefaultBaseURL, endpoint, key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", endpoint, redact.Error(err))
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", endpoint, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status code %d: %s", endpoint, resp.StatusCode, body)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", endpoint, err)
+	}
+	for _, raw := range fields {
+		var names map[string]string
+		if json.Unmarshal(raw, &names) == nil && len(names) > 0 {
+			return names, nil
+		}
+	}
+	return nil, fmt.Errorf("no list found in %s response", endpoint)
+}
@@ -0,0 +1,15 @@
+package symbols
+
+// Pair returns the symbol quoting base against quote. When only the reverse
+// pair exists, as for USD/EUR, it returns that symbol with inverted set, and
+// the rate for base in quote is 1 divided by its price. Unknown combinations
+// are returned as base+quote, which the API quotes as a cross.
+func Pair(base, quote string) (symbol string, inverted bool) {
+	if known[base+quote] {
+		return base + quote, false
+	}
+	if known[quote+base] {
+		return quote + base, true
+	}
+	return base + quote, false
+}
@@ -0,0 +1,135 @@
+package symbols
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tradermade/Go-SDK/currency"
+	"github.com/tradermade/Go-SDK/internal/fuzzy"
+)
+
+// ErrUnknownSymbol is wrapped by the error Resolve returns for input it cannot resolve
+var ErrUnknownSymbol = errors.New("unknown symbol")
+
+// SymbolError reports input that could not be resolved, with close matches
+type SymbolError struct {
+	Input       string
+	Suggestions []string
+}
+
+func (e *SymbolError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("%v: %q", ErrUnknownSymbol, e.Input)
+	}
+	return fmt.Sprintf("%v: %q, did you mean %s?", ErrUnknownSymbol, e.Input, strings.Join(e.Suggestions, ", "))
+}
+
+func (e *SymbolError) Unwrap() error {
+	return ErrUnknownSymbol
+}
+
+// Aliases maps common names to symbols. Keys are upper case without
+// separators, and it can be extended. Aliases to symbols that are neither
+// generated nor a pair of ISO 4217 codes are ignored.
+var Aliases = map[string]string{
+	"GOLD":      "XAUUSD",
+	"SILVER":    "XAGUSD",
+	"PLATINUM":  "XPTUSD",
+	"PALLADIUM": "XPDUSD",
+	"CABLE":     "GBPUSD",
+	"FIBER":     "EURUSD",
+	"FIBRE":     "EURUSD",
+	"AUSSIE":    "AUDUSD",
+	"KIWI":      "NZDUSD",
+	"LOONIE":    "USDCAD",
+	"SWISSIE":   "USDCHF",
+	"BITCOIN":   "BTCUSD",
+	"ETHEREUM":  "ETHUSD",
+	"WTI":       "USOIL",
+	"OIL":       "USOIL",
+	"BRENT":     "UKOIL",
+	"FTSE":      "UK100",
+	"DAX":       "GER30",
+	"NIKKEI":    "JPN225",
+}
+
+// known holds every generated symbol
+var known = func() map[string]bool {
+	m := make(map[string]bool, len(all))
+	for _, s := range all {
+		m[s] = true
+	}
+	return m
+}()
+
+// All returns every generated symbol in order
+func All() []string {
+	return append([]string(nil), all...)
+}
+
+// Resolve turns loosely written input such as "eur/usd", "EUR-USD" or "gold"
+// into a TraderMade symbol. Pairs of two ISO 4217 codes resolve even if
+// they have no constant. Otherwise it returns a *SymbolError listing close
+// matches, including the pair in the conventional order.
+func Resolve(input string) (string, error) {
+	normalized := strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '-', '_', '.', ' ', '\t':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(input))
+
+	if symbol, ok := Aliases[normalized]; ok && resolvable(symbol) {
+		return symbol, nil
+	}
+	if known[normalized] {
+		return normalized, nil
+	}
+	if _, _, ok := currency.SplitPair(normalized); ok && !known[normalized[3:]+normalized[:3]] {
+		return normalized, nil
+	}
+	return "", &SymbolError{Input: input, Suggestions: suggest(normalized)}
+}
+
+// resolvable reports whether symbol is generated or a pair of ISO 4217 codes
+func resolvable(symbol string) bool {
+	_, _, ok := currency.SplitPair(symbol)
+	return known[symbol] || ok
+}
+
+// suggest returns up to five known symbols close to normalized input
+func suggest(normalized string) []string {
+	type match struct {
+		symbol   string
+		distance int
+	}
+	var matches []match
+	if len(normalized) == 6 {
+		if reversed := normalized[3:] + normalized[:3]; known[reversed] {
+			matches = append(matches, match{reversed, 0})
+		}
+	}
+	for _, s := range all {
+		if d := fuzzy.Distance(normalized, s); d <= 2 {
+			matches = append(matches, match{s, d})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].distance < matches[j].distance })
+
+	var suggestions []string
+	seen := make(map[string]bool)
+	for _, m := range matches {
+		// Drop matches much further away than the best one
+		if len(suggestions) == 5 || m.distance > matches[0].distance+1 {
+			break
+		}
+		if !seen[m.symbol] {
+			seen[m.symbol] = true
+			suggestions = append(suggestions, m.symbol)
+		}
+	}
+	return suggestions
+}
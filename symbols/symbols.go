@@ -0,0 +1,13 @@
+// Package symbols has constants for the instruments TraderMade quotes, so
+// subscriptions and requests are checked by the compiler:
+//
+//	client.Subscribe(symbols.EURUSD, symbols.XAUUSD)
+//
+// FX pairs are listed in market convention order, with crosses between the
+// major currencies and every other currency against USD. Regenerate the
+// constants from the API's list endpoints with:
+//
+//	TRADERMADE_API_KEY=... go generate ./symbols
+package symbols
+
+//go:generate go run gen.go
@@ -0,0 +1,251 @@
+// Code generated by gen.go from the built-in ISO 4217 table; DO NOT EDIT.
+
+package symbols
+
+// FX pairs
+const (
+	AUDCAD = "AUDCAD" // Australian Dollar / Canadian Dollar
+	AUDCHF = "AUDCHF" // Australian Dollar / Swiss Franc
+	AUDJPY = "AUDJPY" // Australian Dollar / Japanese Yen
+	AUDNOK = "AUDNOK" // Australian Dollar / Norwegian Krone
+	AUDNZD = "AUDNZD" // Australian Dollar / New Zealand Dollar
+	AUDSEK = "AUDSEK" // Australian Dollar / Swedish Krona
+	AUDUSD = "AUDUSD" // Australian Dollar / US Dollar
+	CADCHF = "CADCHF" // Canadian Dollar / Swiss Franc
+	CADJPY = "CADJPY" // Canadian Dollar / Japanese Yen
+	CADNOK = "CADNOK" // Canadian Dollar / Norwegian Krone
+	CADSEK = "CADSEK" // Canadian Dollar / Swedish Krona
+	CHFJPY = "CHFJPY" // Swiss Franc / Japanese Yen
+	CHFNOK = "CHFNOK" // Swiss Franc / Norwegian Krone
+	CHFSEK = "CHFSEK" // Swiss Franc / Swedish Krona
+	EURAUD = "EURAUD" // Euro / Australian Dollar
+	EURCAD = "EURCAD" // Euro / Canadian Dollar
+	EURCHF = "EURCHF" // Euro / Swiss Franc
+	EURGBP = "EURGBP" // Euro / British Pound
+	EURJPY = "EURJPY" // Euro / Japanese Yen
+	EURNOK = "EURNOK" // Euro / Norwegian Krone
+	EURNZD = "EURNZD" // Euro / New Zealand Dollar
+	EURSEK = "EURSEK" // Euro / Swedish Krona
+	EURUSD = "EURUSD" // Euro / US Dollar
+	GBPAUD = "GBPAUD" // British Pound / Australian Dollar
+	GBPCAD = "GBPCAD" // British Pound / Canadian Dollar
+	GBPCHF = "GBPCHF" // British Pound / Swiss Franc
+	GBPJPY = "GBPJPY" // British Pound / Japanese Yen
+	GBPNOK = "GBPNOK" // British Pound / Norwegian Krone
+	GBPNZD = "GBPNZD" // British Pound / New Zealand Dollar
+	GBPSEK = "GBPSEK" // British Pound / Swedish Krona
+	GBPUSD = "GBPUSD" // British Pound / US Dollar
+	NOKJPY = "NOKJPY" // Norwegian Krone / Japanese Yen
+	NOKSEK = "NOKSEK" // Norwegian Krone / Swedish Krona
+	NZDCAD = "NZDCAD" // New Zealand Dollar / Canadian Dollar
+	NZDCHF = "NZDCHF" // New Zealand Dollar / Swiss Franc
+	NZDJPY = "NZDJPY" // New Zealand Dollar / Japanese Yen
+	NZDNOK = "NZDNOK" // New Zealand Dollar / Norwegian Krone
+	NZDSEK = "NZDSEK" // New Zealand Dollar / Swedish Krona
+	NZDUSD = "NZDUSD" // New Zealand Dollar / US Dollar
+	SEKJPY = "SEKJPY" // Swedish Krona / Japanese Yen
+	USDAED = "USDAED" // US Dollar / UAE Dirham
+	USDARS = "USDARS" // US Dollar / Argentine Peso
+	USDBGN = "USDBGN" // US Dollar / Bulgarian Lev
+	USDBHD = "USDBHD" // US Dollar / Bahraini Dinar
+	USDBRL = "USDBRL" // US Dollar / Brazilian Real
+	USDCAD = "USDCAD" // US Dollar / Canadian Dollar
+	USDCHF = "USDCHF" // US Dollar / Swiss Franc
+	USDCLP = "USDCLP" // US Dollar / Chilean Peso
+	USDCNH = "USDCNH" // US Dollar / Chinese Yuan (Offshore)
+	USDCNY = "USDCNY" // US Dollar / Chinese Yuan
+	USDCOP = "USDCOP" // US Dollar / Colombian Peso
+	USDCZK = "USDCZK" // US Dollar / Czech Koruna
+	USDDKK = "USDDKK" // US Dollar / Danish Krone
+	USDEGP = "USDEGP" // US Dollar / Egyptian Pound
+	USDHKD = "USDHKD" // US Dollar / Hong Kong Dollar
+	USDHUF = "USDHUF" // US Dollar / Hungarian Forint
+	USDIDR = "USDIDR" // US Dollar / Indonesian Rupiah
+	USDILS = "USDILS" // US Dollar / Israeli New Shekel
+	USDINR = "USDINR" // US Dollar / Indian Rupee
+	USDISK = "USDISK" // US Dollar / Icelandic Krona
+	USDJOD = "USDJOD" // US Dollar / Jordanian Dinar
+	USDJPY = "USDJPY" // US Dollar / Japanese Yen
+	USDKES = "USDKES" // US Dollar / Kenyan Shilling
+	USDKRW = "USDKRW" // US Dollar / South Korean Won
+	USDKWD = "USDKWD" // US Dollar / Kuwaiti Dinar
+	USDMAD = "USDMAD" // US Dollar / Moroccan Dirham
+	USDMXN = "USDMXN" // US Dollar / Mexican Peso
+	USDMYR = "USDMYR" // US Dollar / Malaysian Ringgit
+	USDNGN = "USDNGN" // US Dollar / Nigerian Naira
+	USDNOK = "USDNOK" // US Dollar / Norwegian Krone
+	USDOMR = "USDOMR" // US Dollar / Omani Rial
+	USDPEN = "USDPEN" // US Dollar / Peruvian Sol
+	USDPHP = "USDPHP" // US Dollar / Philippine Peso
+	USDPKR = "USDPKR" // US Dollar / Pakistani Rupee
+	USDPLN = "USDPLN" // US Dollar / Polish Zloty
+	USDQAR = "USDQAR" // US Dollar / Qatari Riyal
+	USDRON = "USDRON" // US Dollar / Romanian Leu
+	USDRUB = "USDRUB" // US Dollar / Russian Ruble
+	USDSAR = "USDSAR" // US Dollar / Saudi Riyal
+	USDSEK = "USDSEK" // US Dollar / Swedish Krona
+	USDSGD = "USDSGD" // US Dollar / Singapore Dollar
+	USDTHB = "USDTHB" // US Dollar / Thai Baht
+	USDTND = "USDTND" // US Dollar / Tunisian Dinar
+	USDTRY = "USDTRY" // US Dollar / Turkish Lira
+	USDTWD = "USDTWD" // US Dollar / New Taiwan Dollar
+	USDUAH = "USDUAH" // US Dollar / Ukrainian Hryvnia
+	USDVND = "USDVND" // US Dollar / Vietnamese Dong
+	USDZAR = "USDZAR" // US Dollar / South African Rand
+)
+
+// Metals
+const (
+	XAGUSD = "XAGUSD" // Silver / US Dollar
+	XAUUSD = "XAUUSD" // Gold / US Dollar
+	XPDUSD = "XPDUSD" // Palladium / US Dollar
+	XPTUSD = "XPTUSD" // Platinum / US Dollar
+)
+
+// Crypto
+const (
+	ADAUSD = "ADAUSD" // Cardano / US Dollar
+	BCHUSD = "BCHUSD" // Bitcoin Cash / US Dollar
+	BTCUSD = "BTCUSD" // Bitcoin / US Dollar
+	DOTUSD = "DOTUSD" // Polkadot / US Dollar
+	EOSUSD = "EOSUSD" // EOS / US Dollar
+	ETHUSD = "ETHUSD" // Ethereum / US Dollar
+	LTCUSD = "LTCUSD" // Litecoin / US Dollar
+	SOLUSD = "SOLUSD" // Solana / US Dollar
+	XLMUSD = "XLMUSD" // Stellar / US Dollar
+	XRPUSD = "XRPUSD" // Ripple / US Dollar
+)
+
+// CFDs
+const (
+	AUS200 = "AUS200" // ASX 200
+	FRA40  = "FRA40"  // CAC 40
+	GER30  = "GER30"  // DAX 30
+	HKG33  = "HKG33"  // Hang Seng
+	JPN225 = "JPN225" // Nikkei 225
+	NAS100 = "NAS100" // Nasdaq 100
+	NATGAS = "NATGAS" // Natural Gas
+	SPX500 = "SPX500" // S&P 500
+	UK100  = "UK100"  // FTSE 100
+	UKOIL  = "UKOIL"  // Brent Crude Oil
+	US30   = "US30"   // Dow Jones 30
+	USOIL  = "USOIL"  // WTI Crude Oil
+)
+
+// all lists every generated symbol in order
+var all = []string{
+	ADAUSD,
+	AUDCAD,
+	AUDCHF,
+	AUDJPY,
+	AUDNOK,
+	AUDNZD,
+	AUDSEK,
+	AUDUSD,
+	AUS200,
+	BCHUSD,
+	BTCUSD,
+	CADCHF,
+	CADJPY,
+	CADNOK,
+	CADSEK,
+	CHFJPY,
+	CHFNOK,
+	CHFSEK,
+	DOTUSD,
+	EOSUSD,
+	ETHUSD,
+	EURAUD,
+	EURCAD,
+	EURCHF,
+	EURGBP,
+	EURJPY,
+	EURNOK,
+	EURNZD,
+	EURSEK,
+	EURUSD,
+	FRA40,
+	GBPAUD,
+	GBPCAD,
+	GBPCHF,
+	GBPJPY,
+	GBPNOK,
+	GBPNZD,
+	GBPSEK,
+	GBPUSD,
+	GER30,
+	HKG33,
+	JPN225,
+	LTCUSD,
+	NAS100,
+	NATGAS,
+	NOKJPY,
+	NOKSEK,
+	NZDCAD,
+	NZDCHF,
+	NZDJPY,
+	NZDNOK,
+	NZDSEK,
+	NZDUSD,
+	SEKJPY,
+	SOLUSD,
+	SPX500,
+	UK100,
+	UKOIL,
+	US30,
+	USDAED,
+	USDARS,
+	USDBGN,
+	USDBHD,
+	USDBRL,
+	USDCAD,
+	USDCHF,
+	USDCLP,
+	USDCNH,
+	USDCNY,
+	USDCOP,
+	USDCZK,
+	USDDKK,
+	USDEGP,
+	USDHKD,
+	USDHUF,
+	USDIDR,
+	USDILS,
+	USDINR,
+	USDISK,
+	USDJOD,
+	USDJPY,
+	USDKES,
+	USDKRW,
+	USDKWD,
+	USDMAD,
+	USDMXN,
+	USDMYR,
+	USDNGN,
+	USDNOK,
+	USDOMR,
+	USDPEN,
+	USDPHP,
+	USDPKR,
+	USDPLN,
+	USDQAR,
+	USDRON,
+	USDRUB,
+	USDSAR,
+	USDSEK,
+	USDSGD,
+	USDTHB,
+	USDTND,
+	USDTRY,
+	USDTWD,
+	USDUAH,
+	USDVND,
+	USDZAR,
+	USOIL,
+	XAGUSD,
+	XAUUSD,
+	XLMUSD,
+	XPDUSD,
+	XPTUSD,
+	XRPUSD,
+}
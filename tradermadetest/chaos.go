@@ -0,0 +1,134 @@
+package tradermadetest
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/tradermade/Go-SDK/clock"
+)
+
+// Chaos injects faults into the feed at random. Rates are probabilities
+// between 0 and 1, checked per generated tick or per connection attempt.
+type Chaos struct {
+	DropRate      float64       // Per tick: drop the connection without a close frame
+	MalformedRate float64       // Per tick: send a malformed frame before the quotes
+	StallRate     float64       // Per tick: stop writing for StallDuration, as a slow network would
+	StallDuration time.Duration // Length of a stall (default 1s)
+	AuthFailRate  float64       // Per connection: reject the credentials with "User Key Wrong"
+	RefuseRate    float64       // Per connection attempt: answer 503 instead of upgrading
+	Seed          int64         // Seed for choosing faults, zero uses 1
+}
+
+// Faults counts the faults injected by Chaos
+type Faults struct {
+	Drops        int
+	Malformed    int
+	Stalls       int
+	AuthFailures int
+	Refusals     int
+}
+
+// Total returns the number of injected faults
+func (f Faults) Total() int {
+	return f.Drops + f.Malformed + f.Stalls + f.AuthFailures + f.Refusals
+}
+
+// malformedFrames are sent by MalformedRate in turn
+var malformedFrames = []string{
+	`{"symbol":"EURUSD","bid":1.08`,
+	`{"symbol":123,"bid":"x","ask":null}`,
+	`not json`,
+	`[]`,
+	"\x00\xff\xfe",
+}
+
+// SetChaos sets the faults injected into the feed and resets the fault
+// counters. A zero Chaos turns injection off.
+func (s *Server) SetChaos(c Chaos) {
+	if c.StallDuration <= 0 {
+		c.StallDuration = time.Second
+	}
+	seed := c.Seed
+	if seed == 0 {
+		seed = 1
+	}
+	s.mu.Lock()
+	s.chaos = c
+	s.chaosRand = rand.New(rand.NewSource(seed))
+	s.faults = Faults{}
+	s.mu.Unlock()
+}
+
+// Faults returns the faults injected since the last SetChaos
+func (s *Server) Faults() Faults {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.faults
+}
+
+// faultKind is one kind of fault Chaos injects
+type faultKind int
+
+const (
+	faultDrop faultKind = iota
+	faultMalformed
+	faultStall
+	faultAuth
+	faultRefuse
+)
+
+// fault reports whether to inject a fault of the kind, counting it
+func (s *Server) fault(kind faultKind) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.chaosRand == nil {
+		return false
+	}
+	var rate float64
+	var count *int
+	switch kind {
+	case faultDrop:
+		rate, count = s.chaos.DropRate, &s.faults.Drops
+	case faultMalformed:
+		rate, count = s.chaos.MalformedRate, &s.faults.Malformed
+	case faultStall:
+		rate, count = s.chaos.StallRate, &s.faults.Stalls
+	case faultAuth:
+		rate, count = s.chaos.AuthFailRate, &s.faults.AuthFailures
+	case faultRefuse:
+		rate, count = s.chaos.RefuseRate, &s.faults.Refusals
+	}
+	if rate <= 0 || s.chaosRand.Float64() >= rate {
+		return false
+	}
+	*count++
+	return true
+}
+
+// injectTickFaults applies the per tick faults to a connection before its
+// quotes are written, reporting false when the connection was dropped
+func (s *Server) injectTickFaults(fc *feedConn) bool {
+	if s.fault(faultDrop) {
+		fc.close()
+		return false
+	}
+	if s.fault(faultStall) {
+		s.mu.Lock()
+		stall := s.chaos.StallDuration
+		s.mu.Unlock()
+		select {
+		case <-clock.OrDefault(s.Clock).After(stall):
+		case <-fc.closed:
+			return false
+		}
+	}
+	if s.fault(faultMalformed) {
+		s.mu.Lock()
+		frame := malformedFrames[(s.faults.Malformed-1)%len(malformedFrames)]
+		s.mu.Unlock()
+		if !fc.write([]byte(frame)) {
+			return false
+		}
+	}
+	return true
+}
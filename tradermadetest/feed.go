@@ -0,0 +1,256 @@
+package tradermadetest
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tradermade/Go-SDK/clock"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// feedConn is one connected feed client
+type feedConn struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	symbols map[string]bool // Subscribed symbols, set by the client's credentials
+	ticks   int             // Ticks left before a scripted disconnect, zero means none
+	closed  chan struct{}
+	once    sync.Once
+}
+
+// Connections returns the number of connected feed clients
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.conns)
+}
+
+// WaitForConnections waits until at least n feed clients are connected,
+// reporting false if the timeout passes first
+func (s *Server) WaitForConnections(n int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for s.Connections() < n {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return true
+}
+
+// RefuseConnections makes the next n feed connection attempts fail with
+// 503 Service Unavailable, e.g. to test reconnection backoff
+func (s *Server) RefuseConnections(n int) {
+	s.mu.Lock()
+	s.refuse = n
+	s.mu.Unlock()
+}
+
+// Disconnect drops every feed connection without a close frame, as a network
+// failure would
+func (s *Server) Disconnect() {
+	for _, fc := range s.feedConns() {
+		fc.close()
+	}
+}
+
+// DisconnectAfter drops every current feed connection after it has sent n
+// more generated ticks
+func (s *Server) DisconnectAfter(n int) {
+	for _, fc := range s.feedConns() {
+		fc.mu.Lock()
+		fc.ticks = n
+		fc.mu.Unlock()
+	}
+}
+
+// Send writes a raw text frame to every feed connection, e.g. "heartbeat",
+// "User Key Wrong" or malformed JSON
+func (s *Server) Send(frame string) {
+	for _, fc := range s.feedConns() {
+		fc.write([]byte(frame))
+	}
+}
+
+// Publish sends a quote to the feed connections subscribed to its symbol and
+// makes its mid the symbol's price. A zero Ts is stamped with the server's clock.
+func (s *Server) Publish(quote tradermadews.QuoteMessage) {
+	if quote.Ts == "" {
+		quote.Ts = strconv.FormatInt(clock.OrDefault(s.Clock).Now().UnixMilli(), 10)
+	}
+	s.SetPrice(quote.Symbol, quote.Mid)
+	payload, _ := json.Marshal(quote)
+	for _, fc := range s.feedConns() {
+		if fc.wants(quote.Symbol) {
+			fc.write(payload)
+		}
+	}
+}
+
+func (s *Server) feedConns() []*feedConn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conns := make([]*feedConn, 0, len(s.conns))
+	for fc := range s.conns {
+		conns = append(conns, fc)
+	}
+	return conns
+}
+
+// serveFeed streams generated quotes to one client. The client authenticates
+// and subscribes by sending {"userKey":"...","symbol":"EURUSD,GBPUSD"}, and
+// may send it again to change its symbols.
+func (s *Server) serveFeed(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	refused := s.refuse > 0
+	if refused {
+		s.refuse--
+	}
+	s.mu.Unlock()
+	if refused || s.fault(faultRefuse) {
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // Upgrade has already written the error response
+	}
+	fc := &feedConn{conn: conn, symbols: make(map[string]bool), closed: make(chan struct{})}
+	s.mu.Lock()
+	s.conns[fc] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, fc)
+		s.mu.Unlock()
+		fc.close()
+	}()
+
+	// Reader: credentials and subscription changes
+	go func() {
+		defer fc.close()
+		for {
+			var msg struct {
+				UserKey string `json:"userKey"`
+				Symbol  string `json:"symbol"`
+			}
+			if err := conn.ReadJSON(&msg); err != nil {
+				if _, ok := err.(*json.SyntaxError); ok {
+					continue
+				}
+				return
+			}
+			fc.mu.Lock()
+			first := len(fc.symbols) == 0
+			fc.mu.Unlock()
+			if (s.APIKey != "" && msg.UserKey != s.APIKey) || (first && s.fault(faultAuth)) {
+				fc.write([]byte("User Key Wrong"))
+				return
+			}
+			fc.mu.Lock()
+			fc.symbols = make(map[string]bool)
+			for _, symbol := range strings.Split(msg.Symbol, ",") {
+				if symbol = strings.TrimSpace(symbol); symbol != "" {
+					fc.symbols[symbol] = true
+				}
+			}
+			fc.mu.Unlock()
+			if first {
+				fc.write([]byte(`{"status":"connected","message":"Connected"}`))
+			}
+		}
+	}()
+
+	interval := s.TickInterval
+	if interval <= 0 {
+		interval = 100 * time.Millisecond
+	}
+	ticker := clock.OrDefault(s.Clock).NewTicker(interval)
+	defer ticker.Stop()
+	random := rand.New(rand.NewSource(1))
+	for {
+		select {
+		case <-ticker.C():
+			if !s.tick(fc, random) {
+				return
+			}
+		case <-fc.closed:
+			return
+		}
+	}
+}
+
+// tick sends one generated quote per subscribed symbol, reporting false when
+// a scripted disconnect is due
+func (s *Server) tick(fc *feedConn, random *rand.Rand) bool {
+	fc.mu.Lock()
+	symbols := make([]string, 0, len(fc.symbols))
+	for symbol := range fc.symbols {
+		symbols = append(symbols, symbol)
+	}
+	fc.mu.Unlock()
+
+	if !s.injectTickFaults(fc) {
+		return false
+	}
+	now := clock.OrDefault(s.Clock).Now()
+	for _, symbol := range symbols {
+		s.mu.Lock()
+		mid := s.price(symbol) * (1 + 0.0001*(random.Float64()-0.5))
+		s.prices[symbol] = mid
+		spread := s.Spread
+		s.mu.Unlock()
+
+		payload, _ := json.Marshal(tradermadews.QuoteMessage{
+			Symbol: symbol,
+			Bid:    mid - spread/2,
+			Ask:    mid + spread/2,
+			Mid:    mid,
+			Ts:     strconv.FormatInt(now.UnixMilli(), 10),
+		})
+		if !fc.write(payload) {
+			return false
+		}
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	if fc.ticks > 0 {
+		if fc.ticks--; fc.ticks == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (fc *feedConn) wants(symbol string) bool {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.symbols[symbol]
+}
+
+// write sends a text frame, reporting false if the connection failed
+func (fc *feedConn) write(payload []byte) bool {
+	fc.writeMu.Lock()
+	defer fc.writeMu.Unlock()
+	if err := fc.conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+		fc.close()
+		return false
+	}
+	return true
+}
+
+func (fc *feedConn) close() {
+	fc.once.Do(func() {
+		close(fc.closed)
+		fc.conn.Close()
+	})
+}
@@ -0,0 +1,378 @@
+// Package tradermadetest provides a mock TraderMade server for tests. One
+// Server answers the REST endpoints and streams a generated WebSocket feed,
+// with scripted errors and disconnects:
+//
+//	srv := tradermadetest.NewServer()
+//	defer srv.Close()
+//
+//	client := srv.RESTClient()       // REST client pointed at the server
+//	ws := srv.WebSocketClient("EURUSD") // WebSocket client pointed at the feed
+package tradermadetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tradermade/Go-SDK/clock"
+	"github.com/tradermade/Go-SDK/currency"
+	tradermade "github.com/tradermade/Go-SDK/rest"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// APIKey is the key accepted by a new server
+const APIKey = "test-key"
+
+// Server is a mock TraderMade REST API and streaming feed
+type Server struct {
+	*httptest.Server
+
+	APIKey       string        // Accepted key for REST requests and the feed, empty accepts any key
+	Spread       float64       // Ask minus bid of generated quotes (default 0.0002)
+	TickInterval time.Duration // Time between generated feed ticks (default 100ms)
+	Clock        clock.Clock   // Times generated ticks and stamps quotes, nil uses the system clock
+
+	mu        sync.Mutex
+	prices    map[string]float64 // Current mid by symbol
+	failures  []failure          // Scripted REST failures, served in order
+	requests  map[string]int     // REST requests by endpoint
+	conns     map[*feedConn]struct{}
+	refuse    int        // Feed connection attempts left to refuse
+	chaos     Chaos      // Faults injected into the feed, see SetChaos
+	chaosRand *rand.Rand // Chooses faults, nil until SetChaos
+	faults    Faults     // Faults injected since SetChaos
+	upgrader  websocket.Upgrader
+}
+
+// failure is a scripted REST response
+type failure struct {
+	status int
+	body   string
+}
+
+// NewServer starts a server accepting APIKey
+func NewServer() *Server {
+	s := &Server{
+		APIKey:       APIKey,
+		Spread:       0.0002,
+		TickInterval: 100 * time.Millisecond,
+		prices:       make(map[string]float64),
+		requests:     make(map[string]int),
+		conns:        make(map[*feedConn]struct{}),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Close disconnects every feed connection and shuts the server down
+func (s *Server) Close() {
+	s.Disconnect()
+	s.Server.Close()
+}
+
+// WebSocketURL returns the URL of the streaming feed
+func (s *Server) WebSocketURL() string {
+	return "ws" + strings.TrimPrefix(s.URL, "http") + "/feedadv"
+}
+
+// RESTClient returns a client for the server's REST API, with retries disabled
+// so scripted failures surface immediately
+func (s *Server) RESTClient() *tradermade.RESTClient {
+	client := tradermade.NewRESTClient(s.APIKey)
+	client.SetBaseURL(s.URL)
+	client.SetRetryPolicy(0, 0)
+	return client
+}
+
+// WebSocketClient returns a client for the server's feed
+func (s *Server) WebSocketClient(symbols ...string) *tradermadews.WebSocketClient {
+	client := tradermadews.NewWebSocketClient(s.APIKey, strings.Join(symbols, ","))
+	client.SetURL(s.WebSocketURL())
+	return client
+}
+
+// SetPrice sets the mid price of a symbol. Symbols without a price start at 1.
+func (s *Server) SetPrice(symbol string, mid float64) {
+	s.mu.Lock()
+	s.prices[symbol] = mid
+	s.mu.Unlock()
+}
+
+// Price returns the current mid price of a symbol
+func (s *Server) Price(symbol string) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.price(symbol)
+}
+
+// price returns the mid of a symbol. Must be called with mu held.
+func (s *Server) price(symbol string) float64 {
+	if p, ok := s.prices[symbol]; ok {
+		return p
+	}
+	s.prices[symbol] = 1
+	return 1
+}
+
+// FailNext makes the next REST request fail with the status and body. Calls
+// queue up, so FailNext(503, "") twice fails the next two requests. Status 200
+// with a body such as {"error":204,"message":"..."} scripts an error reported
+// in a successful response.
+func (s *Server) FailNext(status int, body string) {
+	s.mu.Lock()
+	s.failures = append(s.failures, failure{status, body})
+	s.mu.Unlock()
+}
+
+// Requests returns the number of REST requests received for an endpoint,
+// e.g. "live", including failed ones
+func (s *Server) Requests(endpoint string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[endpoint]
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	endpoint := path.Base(r.URL.Path)
+	if endpoint == "feedadv" {
+		s.serveFeed(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests[endpoint]++
+	var scripted *failure
+	if len(s.failures) > 0 {
+		scripted = &s.failures[0]
+		s.failures = s.failures[1:]
+	}
+	s.mu.Unlock()
+
+	if scripted != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(scripted.status)
+		fmt.Fprint(w, scripted.body)
+		return
+	}
+	query := r.URL.Query()
+	if s.APIKey != "" && query.Get("api_key") != s.APIKey {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"message": "Invalid API Key"})
+		return
+	}
+
+	var resp interface{}
+	var err error
+	switch endpoint {
+	case "live":
+		resp, err = s.live(query.Get("currency"))
+	case "historical", "hour_historical", "minute_historical":
+		resp, err = s.historical(endpoint, query)
+	case "timeseries":
+		resp, err = s.timeseries(query)
+	case "convert":
+		resp, err = s.convert(query)
+	case "live_currencies_list":
+		resp = map[string]interface{}{
+			"endpoint":             "live_currencies_list",
+			"available_currencies": currencyNames(),
+		}
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"message": "Not Found"})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"message": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) live(currencies string) (interface{}, error) {
+	if currencies == "" {
+		return nil, fmt.Errorf("currency is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	quotes := []tradermade.Quote{}
+	for _, symbol := range strings.Split(currencies, ",") {
+		mid := s.price(symbol)
+		quote := tradermade.Quote{Bid: mid - s.Spread/2, Ask: mid + s.Spread/2, Mid: mid}
+		if len(symbol) == 6 {
+			quote.BaseCurrency, quote.QuoteCurrency = symbol[:3], symbol[3:]
+		} else {
+			quote.Instrument = symbol
+		}
+		quotes = append(quotes, quote)
+	}
+	now := clock.OrDefault(s.Clock).Now().UTC()
+	return tradermade.LiveRate{
+		Endpoint:      "live",
+		Quotes:        quotes,
+		RequestedTime: now.Format(time.RFC1123),
+		Timestamp:     now.Unix(),
+	}, nil
+}
+
+func (s *Server) historical(endpoint string, query map[string][]string) (interface{}, error) {
+	get := func(name string) string {
+		if v := query[name]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	currency := get("currency")
+	if currency == "" {
+		return nil, fmt.Errorf("currency is required")
+	}
+	if endpoint != "historical" {
+		t, err := tradermade.ParseDate(get("date_time"))
+		if err != nil {
+			return nil, err
+		}
+		o, h, l, c := s.bar(currency, t)
+		return tradermade.HistoricalData{
+			Endpoint: endpoint, Currency: currency, DateTime: get("date_time"),
+			Open: o, High: h, Low: l, Close: c,
+		}, nil
+	}
+
+	t, err := tradermade.ParseDate(get("date"))
+	if err != nil {
+		return nil, err
+	}
+	var quotes []tradermade.HistoricalQuote
+	for _, symbol := range strings.Split(currency, ",") {
+		o, h, l, c := s.bar(symbol, t)
+		quote := tradermade.HistoricalQuote{Open: o, High: h, Low: l, Close: c}
+		if len(symbol) == 6 {
+			quote.BaseCurrency, quote.QuoteCurrency = symbol[:3], symbol[3:]
+		}
+		quotes = append(quotes, quote)
+	}
+	return tradermade.HistoricalRate{Date: get("date"), Endpoint: "historical", Quotes: quotes}, nil
+}
+
+func (s *Server) timeseries(query map[string][]string) (interface{}, error) {
+	get := func(name string) string {
+		if v := query[name]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	currency := get("currency")
+	start, err := tradermade.ParseDate(get("start_date"))
+	if err != nil {
+		return nil, err
+	}
+	end, err := tradermade.ParseDate(get("end_date"))
+	if err != nil {
+		return nil, err
+	}
+
+	step, layout := 24*time.Hour, "2006-01-02"
+	period, _ := strconv.Atoi(get("period"))
+	switch get("interval") {
+	case "hourly":
+		step, layout = time.Duration(max(period, 1))*time.Hour, "2006-01-02 15:04"
+	case "minute":
+		step, layout = time.Duration(max(period, 1))*time.Minute, "2006-01-02 15:04"
+	}
+
+	quotes := []tradermade.TimeSeriesQuote{}
+	for t := start; !t.After(end); t = t.Add(step) {
+		o, h, l, c := s.bar(currency, t)
+		quotes = append(quotes, tradermade.TimeSeriesQuote{Date: t.Format(layout), Open: o, High: h, Low: l, Close: c})
+	}
+	rate := tradermade.TimeSeriesRate{
+		StartDate: get("start_date"), EndDate: get("end_date"),
+		Endpoint: "timeseries", Quotes: quotes,
+	}
+	if len(currency) == 6 {
+		rate.BaseCurrency, rate.QuoteCurrency = currency[:3], currency[3:]
+	}
+	if fields := get("fields"); fields != "" {
+		return selectFields(rate, strings.Split(fields, ","))
+	}
+	return rate, nil
+}
+
+// selectFields re-encodes a timeseries response keeping only the date and the
+// named price fields of each quote, as the fields parameter does
+func selectFields(rate tradermade.TimeSeriesRate, fields []string) (interface{}, error) {
+	var response map[string]interface{}
+	data, _ := json.Marshal(rate)
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+	keep := map[string]bool{"date": true}
+	for _, f := range fields {
+		keep[strings.TrimSpace(f)] = true
+	}
+	for _, q := range response["quotes"].([]interface{}) {
+		for name := range q.(map[string]interface{}) {
+			if !keep[name] {
+				delete(q.(map[string]interface{}), name)
+			}
+		}
+	}
+	return response, nil
+}
+
+func (s *Server) convert(query map[string][]string) (interface{}, error) {
+	from, to := "", ""
+	if v := query["from"]; len(v) > 0 {
+		from = v[0]
+	}
+	if v := query["to"]; len(v) > 0 {
+		to = v[0]
+	}
+	var amount float64
+	if v := query["amount"]; len(v) > 0 {
+		amount, _ = strconv.ParseFloat(v[0], 64)
+	}
+	if from == "" || to == "" {
+		return nil, fmt.Errorf("from and to are required")
+	}
+	rate := s.Price(from + to)
+	now := clock.OrDefault(s.Clock).Now().UTC()
+	return tradermade.ConvertResponse{
+		BaseCurrency: from, QuoteCurrency: to, Quote: rate, Total: rate * amount,
+		RequestedTime: now.Format(time.RFC1123), Timestamp: now.Unix(),
+	}, nil
+}
+
+// bar returns a deterministic OHLC bar around the symbol's price, so repeated
+// requests for the same time return the same data
+func (s *Server) bar(symbol string, t time.Time) (open, high, low, close float64) {
+	mid := s.Price(symbol)
+	wave := func(x float64) float64 { return mid * (1 + 0.001*math.Sin(x)) }
+	x := float64(t.Unix()) / 3600
+	open, close = wave(x), wave(x+0.5)
+	high = math.Max(open, close) * 1.0002
+	low = math.Min(open, close) * 0.9998
+	return open, high, low, close
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// currencyNames returns the live currencies list served by the mock
+func currencyNames() map[string]string {
+	names := make(map[string]string)
+	for _, c := range currency.All() {
+		names[c.Code] = c.Name
+	}
+	return names
+}
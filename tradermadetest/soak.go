@@ -0,0 +1,227 @@
+package tradermadetest
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Soak runs a WebSocket client against the server's feed while Chaos injects
+// faults, then turns the faults off and checks that quotes flow again:
+//
+//	soak := &tradermadetest.Soak{
+//		Server:   srv,
+//		Duration: time.Minute,
+//		Chaos:    tradermadetest.Chaos{DropRate: 0.01, MalformedRate: 0.05},
+//	}
+//	report, err := soak.Run(ctx)
+type Soak struct {
+	Server         *Server
+	Client         *tradermadews.WebSocketClient // Client under test, nil creates one reconnecting quickly and forever
+	Symbols        []string                      // Symbols of a created client (default EURUSD)
+	Chaos          Chaos                         // Faults injected while the soak runs
+	Duration       time.Duration                 // Length of the faulty period (default 10s)
+	SlowReadRate   float64                       // Chance per quote that the handler blocks for SlowReadDelay, as a slow consumer would
+	SlowReadDelay  time.Duration                 // Length of a slow read (default 50ms)
+	RecoverTimeout time.Duration                 // How long to wait for a quote once the faults stop (default 10s)
+	Seed           int64                         // Seed for choosing slow reads, zero uses 1
+}
+
+// SoakReport describes how the client coped with a soak
+type SoakReport struct {
+	Duration     time.Duration // Length of the faulty period
+	Quotes       int           // Quotes delivered to the message handler
+	Connects     int           // Connections confirmed by the server
+	Disconnects  int           // Connections lost
+	Reconnects   int           // Reconnection attempts made by the client
+	Restarts     int           // Times the soak reconnected a client that shut down, e.g. after an auth failure
+	Errors       int           // Errors reported by the client
+	Dropped      uint64        // Messages dropped by the client's buffer
+	SlowReads    int           // Quotes whose handling was slowed down
+	Faults       Faults        // Faults injected by the server
+	LongestGap   time.Duration // Longest time between delivered quotes
+	Recovered    bool          // A quote arrived after the faults stopped
+	RecoveryTime time.Duration // Time from the faults stopping to the first quote
+}
+
+// String summarizes the report
+func (r SoakReport) String() string {
+	status := "did not recover"
+	if r.Recovered {
+		status = fmt.Sprintf("recovered in %s", r.RecoveryTime.Round(time.Millisecond))
+	}
+	return fmt.Sprintf("%s soak, %s: %d quotes, %d connects, %d disconnects, %d reconnects, %d restarts, %d errors, %d dropped, %d slow reads, longest gap %s; faults: %d drops, %d malformed, %d stalls, %d auth failures, %d refusals",
+		r.Duration, status, r.Quotes, r.Connects, r.Disconnects, r.Reconnects, r.Restarts, r.Errors, r.Dropped, r.SlowReads,
+		r.LongestGap.Round(time.Millisecond), r.Faults.Drops, r.Faults.Malformed, r.Faults.Stalls, r.Faults.AuthFailures, r.Faults.Refusals)
+}
+
+// Run connects the client, injects faults for Duration and waits for it to
+// recover. It wraps the client's message handler, reads its Events and Errors
+// channels, and disconnects it before returning. A client shut down by a
+// rejected key or exhausted retries is connected again, as an application
+// supervising it would.
+func (s *Soak) Run(ctx context.Context) (SoakReport, error) {
+	if s.Server == nil {
+		return SoakReport{}, fmt.Errorf("soak needs a server")
+	}
+	duration := s.Duration
+	if duration <= 0 {
+		duration = 10 * time.Second
+	}
+	recoverTimeout := s.RecoverTimeout
+	if recoverTimeout <= 0 {
+		recoverTimeout = 10 * time.Second
+	}
+	slowDelay := s.SlowReadDelay
+	if slowDelay <= 0 {
+		slowDelay = 50 * time.Millisecond
+	}
+	seed := s.Seed
+	if seed == 0 {
+		seed = 1
+	}
+
+	client := s.Client
+	if client == nil {
+		symbols := s.Symbols
+		if len(symbols) == 0 {
+			symbols = []string{"EURUSD"}
+		}
+		client = s.Server.WebSocketClient(symbols...)
+		client.MaxRetries = 0
+		client.RetryInterval = 50 * time.Millisecond
+		client.MaxRetryInterval = time.Second
+	}
+
+	var (
+		mu       sync.Mutex
+		report   = SoakReport{Duration: duration}
+		last     time.Time
+		quoted   = make(chan struct{}, 1)
+		random   = rand.New(rand.NewSource(seed))
+		previous = client.MessageHandler
+	)
+	client.SetMessageHandler(func(quote tradermadews.QuoteMessage, ts string) {
+		now := time.Now()
+		mu.Lock()
+		report.Quotes++
+		if !last.IsZero() && now.Sub(last) > report.LongestGap {
+			report.LongestGap = now.Sub(last)
+		}
+		last = now
+		slow := s.SlowReadRate > 0 && random.Float64() < s.SlowReadRate
+		if slow {
+			report.SlowReads++
+		}
+		mu.Unlock()
+		select {
+		case quoted <- struct{}{}:
+		default:
+		}
+		if slow {
+			time.Sleep(slowDelay)
+		}
+		if previous != nil {
+			previous(quote, ts)
+		}
+	})
+	defer client.SetMessageHandler(previous)
+
+	watch := make(chan struct{})
+	defer close(watch)
+	go func() {
+		events, errs := client.Events(), client.Errors()
+		for {
+			select {
+			case e := <-events:
+				mu.Lock()
+				switch e.Type {
+				case tradermadews.EventConnected:
+					report.Connects++
+				case tradermadews.EventDisconnected:
+					report.Disconnects++
+				case tradermadews.EventReconnecting:
+					report.Reconnects++
+				}
+				mu.Unlock()
+			case <-errs:
+				mu.Lock()
+				report.Errors++
+				mu.Unlock()
+			case <-watch:
+				return
+			}
+		}
+	}()
+
+	// Connect cleanly, so the soak measures recovery rather than the first dial
+	s.Server.SetChaos(Chaos{})
+	if err := client.Connect(); err != nil {
+		return SoakReport{}, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer client.Disconnect()
+
+	s.Server.SetChaos(s.Chaos)
+	err := s.supervise(ctx, client, time.Now().Add(duration), nil, &mu, &report)
+	faults := s.Server.Faults()
+	s.Server.SetChaos(Chaos{})
+
+	stopped := time.Now()
+	drain(quoted)
+	if err == nil {
+		err = s.supervise(ctx, client, stopped.Add(recoverTimeout), quoted, &mu, &report)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	report.Faults = faults
+	report.Dropped = client.Dropped()
+	if last.After(stopped) {
+		report.Recovered = true
+		report.RecoveryTime = last.Sub(stopped)
+	}
+	return report, err
+}
+
+// supervise keeps the client connected until the deadline or, if quoted is
+// given, until a quote arrives
+func (s *Soak) supervise(ctx context.Context, client *tradermadews.WebSocketClient, deadline time.Time, quoted <-chan struct{}, mu *sync.Mutex, report *SoakReport) error {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return nil
+		case <-quoted:
+			return nil
+		case <-client.Done():
+			mu.Lock()
+			report.Restarts++
+			mu.Unlock()
+			// Connect fails while the server refuses connections; retry shortly
+			for client.Connect() != nil {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-timer.C:
+					return nil
+				case <-time.After(50 * time.Millisecond):
+				}
+			}
+		}
+	}
+}
+
+// drain empties a signal channel
+func drain(ch chan struct{}) {
+	select {
+	case <-ch:
+	default:
+	}
+}
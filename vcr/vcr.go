@@ -0,0 +1,277 @@
+// Package vcr records REST API interactions to fixture files and replays
+// them, so integration tests run without network access or an API key.
+// API keys are redacted before anything is written.
+//
+// Record once against the live API, then commit the fixture:
+//
+//	rec, err := vcr.New("testdata/live.json", vcr.ModeAuto)
+//	client := tradermade.NewRESTClient(os.Getenv("TRADERMADE_API_KEY"))
+//	client.HTTPClient.Transport = rec
+//	...
+//	err = rec.Save()
+package vcr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mode selects whether a Recorder records or replays
+type Mode int
+
+const (
+	ModeAuto   Mode = iota // Replay if the fixture file exists, record otherwise
+	ModeRecord             // Send requests upstream and record them, replacing the fixture
+	ModeReplay             // Serve recorded responses only, failing unknown requests
+)
+
+// Redacted replaces secret values in fixtures
+const Redacted = "REDACTED"
+
+// DefaultSecretParams are the query parameters redacted by default
+var DefaultSecretParams = []string{"api_key", "apikey", "userKey"}
+
+// DefaultSecretHeaders are the headers redacted by default
+var DefaultSecretHeaders = []string{"Authorization", "X-Api-Key"}
+
+// Fixture is the file format of a recording
+type Fixture struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded request and its response
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request with secrets redacted
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+// Response is a recorded response
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// Recorder is an http.RoundTripper that records or replays interactions
+type Recorder struct {
+	Transport     http.RoundTripper // Upstream transport when recording, nil uses http.DefaultTransport
+	SecretParams  []string          // Query parameters redacted in fixtures (default DefaultSecretParams)
+	SecretHeaders []string          // Headers redacted in fixtures (default DefaultSecretHeaders)
+
+	path    string
+	mode    Mode
+	mu      sync.Mutex
+	fixture Fixture
+	served  map[string]int // Replays per request key, so repeated requests get successive responses
+	secrets map[string]bool
+}
+
+// New creates a recorder for the fixture at path. In replay mode, or in auto
+// mode when the file exists, the fixture is loaded now.
+func New(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{
+		SecretParams:  DefaultSecretParams,
+		SecretHeaders: DefaultSecretHeaders,
+		path:          path,
+		mode:          mode,
+		served:        make(map[string]int),
+		secrets:       make(map[string]bool),
+	}
+	if mode == ModeAuto {
+		r.mode = ModeRecord
+		if _, err := os.Stat(path); err == nil {
+			r.mode = ModeReplay
+		}
+	}
+	if r.mode == ModeReplay {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture: %w", err)
+		}
+		if err := json.Unmarshal(data, &r.fixture); err != nil {
+			return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+		}
+	}
+	return r, nil
+}
+
+// Mode returns whether the recorder is recording or replaying
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Client returns an HTTP client using the recorder
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Interactions returns the recorded or loaded interactions
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.fixture.Interactions...)
+}
+
+// RoundTrip records or replays one request
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.mode == ModeReplay {
+		return r.replay(req)
+	}
+	return r.record(req)
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	key := r.redactRequest(req)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var matches []*Interaction
+	for i := range r.fixture.Interactions {
+		in := &r.fixture.Interactions[i]
+		if in.Request.Method == key.Method && in.Request.URL == key.URL {
+			matches = append(matches, in)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no recorded interaction for %s %s", key.Method, key.URL)
+	}
+
+	// Serve matches in recorded order, repeating the last one
+	n := r.served[key.Method+" "+key.URL]
+	r.served[key.Method+" "+key.URL] = n + 1
+	in := matches[min(n, len(matches)-1)]
+
+	header := in.Response.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+		StatusCode:    in.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(in.Response.Body)),
+		ContentLength: int64(len(in.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response to record: %w", err)
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	key := r.redactRequest(req)
+	header := resp.Header.Clone()
+	header.Del("Content-Length") // Redaction may change the body's length
+	for _, name := range r.SecretHeaders {
+		if header.Get(name) != "" {
+			header.Set(name, Redacted)
+		}
+	}
+
+	r.mu.Lock()
+	r.fixture.Interactions = append(r.fixture.Interactions, Interaction{
+		Request:  key,
+		Response: Response{StatusCode: resp.StatusCode, Header: header, Body: string(body)},
+	})
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// redactRequest returns the request as recorded, with secret parameters
+// replaced and query parameters sorted so matching ignores their order. The
+// secret values are remembered so Save can scrub them from response bodies.
+func (r *Recorder) redactRequest(req *http.Request) Request {
+	u := *req.URL
+	query := u.Query()
+	r.mu.Lock()
+	for _, name := range r.SecretParams {
+		for _, value := range query[name] {
+			if value != "" {
+				r.secrets[value] = true
+			}
+		}
+		if _, ok := query[name]; ok {
+			query.Set(name, Redacted)
+		}
+	}
+	for _, name := range r.SecretHeaders {
+		if value := req.Header.Get(name); value != "" {
+			r.secrets[value] = true
+		}
+	}
+	r.mu.Unlock()
+	u.RawQuery = encodeSorted(query)
+	u.User = nil
+	return Request{Method: req.Method, URL: u.String()}
+}
+
+// encodeSorted encodes a query with keys and values in a stable order
+func encodeSorted(query url.Values) string {
+	for _, values := range query {
+		sort.Strings(values)
+	}
+	return query.Encode() // Encode sorts by key
+}
+
+// Save writes the recorded interactions to the fixture file. It does nothing
+// when replaying.
+func (r *Recorder) Save() error {
+	if r.mode == ModeReplay {
+		return nil
+	}
+	r.mu.Lock()
+	fixture := Fixture{Interactions: append([]Interaction(nil), r.fixture.Interactions...)}
+	for i := range fixture.Interactions {
+		for secret := range r.secrets {
+			fixture.Interactions[i].Response.Body = strings.ReplaceAll(fixture.Interactions[i].Response.Body, secret, Redacted)
+		}
+	}
+	r.mu.Unlock()
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false) // Keep URLs readable in diffs
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(fixture); err != nil {
+		return fmt.Errorf("failed to encode fixture: %w", err)
+	}
+	if dir := filepath.Dir(r.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create fixture directory: %w", err)
+		}
+	}
+	if err := ioutil.WriteFile(r.path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package verify
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tradermade/Go-SDK/candle"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// Window is one pair's data between From (inclusive) and To (exclusive)
+type Window struct {
+	Pair string
+	From time.Time
+	To   time.Time
+}
+
+// Key identifies the window in a Manifest
+func (w Window) Key() string {
+	return w.Pair + "|" + w.From.UTC().Format(time.RFC3339) + "|" + w.To.UTC().Format(time.RFC3339)
+}
+
+// ParseWindow parses a key made by Window.Key
+func ParseWindow(key string) (Window, error) {
+	parts := strings.Split(key, "|")
+	if len(parts) != 3 {
+		return Window{}, fmt.Errorf("invalid window key %q", key)
+	}
+	from, err := time.Parse(time.RFC3339, parts[1])
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid window key %q: %w", key, err)
+	}
+	to, err := time.Parse(time.RFC3339, parts[2])
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid window key %q: %w", key, err)
+	}
+	return Window{Pair: parts[0], From: from, To: to}, nil
+}
+
+// contains reports whether t falls in the window
+func (w Window) contains(t time.Time) bool {
+	return !t.Before(w.From) && t.Before(w.To)
+}
+
+// HashCandles returns the hex SHA-256 of the candles' symbol, start time and
+// OHLC prices, in time order. Tick counts and intervals are not hashed, as
+// the REST API does not return them.
+func HashCandles(candles []candle.Candle) string {
+	sorted := append([]candle.Candle(nil), candles...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	h := sha256.New()
+	for _, c := range sorted {
+		fmt.Fprintf(h, "%s,%d,%s,%s,%s,%s\n", c.Symbol, c.Time.UnixMilli(),
+			formatFloat(c.Open), formatFloat(c.High), formatFloat(c.Low), formatFloat(c.Close))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// HashQuotes returns the hex SHA-256 of the quotes' symbol, Ts, bid, ask and
+// mid, in the order given
+func HashQuotes(quotes []tradermadews.QuoteMessage) string {
+	h := sha256.New()
+	for _, q := range quotes {
+		fmt.Fprintf(h, "%s,%s,%s,%s,%s\n", q.Symbol, q.Ts, formatFloat(q.Bid), formatFloat(q.Ask), formatFloat(q.Mid))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// Manifest maps window keys to the hash of their data, recorded when the
+// data was archived. Stored alongside the archive, it lets later audits
+// detect data that changed after it was written.
+type Manifest map[string]string
+
+// AddCandles records the hash of a window's candles
+func (m Manifest) AddCandles(w Window, candles []candle.Candle) {
+	m[w.Key()] = HashCandles(candles)
+}
+
+// AddQuotes records the hash of a window's quotes
+func (m Manifest) AddQuotes(w Window, quotes []tradermadews.QuoteMessage) {
+	m[w.Key()] = HashQuotes(quotes)
+}
@@ -0,0 +1,310 @@
+// Package verify audits archived market data. A Verifier re-fetches random
+// windows of stored candles from the API and compares them with the archive,
+// and manifests of window hashes recorded at archive time detect candles or
+// ticks that changed after they were written.
+package verify
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tradermade/Go-SDK/candle"
+	tradermade "github.com/tradermade/Go-SDK/rest"
+	tradermadews "github.com/tradermade/Go-SDK/websocket"
+)
+
+// CandleArchive reads stored candles of a pair between from and to, inclusive
+type CandleArchive interface {
+	Candles(ctx context.Context, pair string, from, to time.Time) ([]candle.Candle, error)
+}
+
+// QuoteArchive reads stored ticks of a pair between from and to, inclusive,
+// in the order they were written
+type QuoteArchive interface {
+	Quotes(ctx context.Context, pair string, from, to time.Time) ([]tradermadews.QuoteMessage, error)
+}
+
+// Difference is one disagreement between the archive and the reference
+type Difference struct {
+	Time      time.Time
+	Field     string  // "open", "high", "low", "close", "missing" (only in the reference) or "extra" (only in the archive)
+	Archived  float64 // Archived value, zero for missing candles
+	Reference float64 // Re-fetched value, zero for extra candles
+}
+
+// WindowResult is the outcome of checking one window
+type WindowResult struct {
+	Window
+	ArchiveHash   string
+	ReferenceHash string       // Hash of the re-fetched data, or the manifest's recorded hash
+	Differences   []Difference // Candle level differences; manifest checks only compare hashes
+	Err           error        // The window could not be checked
+}
+
+// OK reports whether the window matched
+func (r WindowResult) OK() bool {
+	return r.Err == nil && len(r.Differences) == 0 && r.ArchiveHash == r.ReferenceHash
+}
+
+// Report summarizes a verification run
+type Report struct {
+	Windows    []WindowResult
+	Checked    int // Windows compared
+	Mismatched int // Windows with differences
+	Failed     int // Windows that could not be checked
+}
+
+// OK reports whether every window matched
+func (r *Report) OK() bool {
+	return r.Mismatched == 0 && r.Failed == 0
+}
+
+func (r *Report) add(w WindowResult) {
+	r.Windows = append(r.Windows, w)
+	switch {
+	case w.Err != nil:
+		r.Failed++
+	case !w.OK():
+		r.Checked++
+		r.Mismatched++
+	default:
+		r.Checked++
+	}
+}
+
+// Verifier compares random windows of archived candles with the API
+type Verifier struct {
+	Client    *tradermade.RESTClient
+	Archive   CandleArchive
+	Interval  string        // "daily", "hourly" or "minute"
+	Period    int           // Bar length in hours or minutes for hourly and minute data (default 1)
+	Window    time.Duration // Length of each sampled window (default one day)
+	Samples   int           // Windows sampled per pair (default 5)
+	Tolerance float64       // Price differences up to this are not reported; hashes must still match exactly unless set
+	Rand      *rand.Rand    // Source for sampling, nil uses the global source
+}
+
+// Verify samples windows of each pair between from and to and compares them
+func (v *Verifier) Verify(ctx context.Context, pairs []string, from, to time.Time) (*Report, error) {
+	if v.Client == nil || v.Archive == nil {
+		return nil, fmt.Errorf("verifier needs a client and an archive")
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("invalid range: %s is not after %s", to.Format(time.RFC3339), from.Format(time.RFC3339))
+	}
+
+	report := &Report{}
+	for _, pair := range pairs {
+		for _, w := range v.sample(pair, from, to) {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			report.add(v.VerifyWindow(ctx, w))
+		}
+	}
+	return report, nil
+}
+
+// sample picks up to Samples distinct windows of the range, in time order
+func (v *Verifier) sample(pair string, from, to time.Time) []Window {
+	length := v.Window
+	if length <= 0 {
+		length = 24 * time.Hour
+	}
+	samples := v.Samples
+	if samples <= 0 {
+		samples = 5
+	}
+	count := int(math.Ceil(float64(to.Sub(from)) / float64(length)))
+	perm := rand.Perm
+	if v.Rand != nil {
+		perm = v.Rand.Perm
+	}
+	picked := perm(count)
+	if len(picked) > samples {
+		picked = picked[:samples]
+	}
+	sort.Ints(picked)
+
+	windows := make([]Window, 0, len(picked))
+	for _, i := range picked {
+		start := from.Add(time.Duration(i) * length)
+		end := start.Add(length)
+		if end.After(to) {
+			end = to
+		}
+		windows = append(windows, Window{Pair: pair, From: start, To: end})
+	}
+	return windows
+}
+
+// VerifyWindow re-fetches one window and compares it with the archive
+func (v *Verifier) VerifyWindow(ctx context.Context, w Window) WindowResult {
+	result := WindowResult{Window: w}
+	archived, err := v.Archive.Candles(ctx, w.Pair, w.From, w.To)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to read archive: %w", err)
+		return result
+	}
+	series, err := v.Client.GetTimeSeriesDataRange(w.Pair, w.From, w.To, v.Interval, max(v.Period, 1))
+	if err != nil {
+		result.Err = fmt.Errorf("failed to fetch reference data: %w", err)
+		return result
+	}
+	reference, err := series.Candles(0)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	for i := range reference {
+		reference[i].Symbol = w.Pair
+	}
+
+	archived, reference = inWindow(w, archived), inWindow(w, reference)
+	result.ArchiveHash = HashCandles(archived)
+	result.ReferenceHash = HashCandles(reference)
+	result.Differences = compare(archived, reference, v.Tolerance)
+	if v.Tolerance > 0 && len(result.Differences) == 0 {
+		result.ReferenceHash = result.ArchiveHash // Equal within tolerance
+	}
+	return result
+}
+
+// inWindow returns the candles starting within the window
+func inWindow(w Window, candles []candle.Candle) []candle.Candle {
+	out := make([]candle.Candle, 0, len(candles))
+	for _, c := range candles {
+		if w.contains(c.Time) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// compare matches candles by start time and lists every disagreement
+func compare(archived, reference []candle.Candle, tolerance float64) []Difference {
+	byTime := make(map[int64]candle.Candle, len(archived))
+	for _, c := range archived {
+		byTime[c.Time.UnixMilli()] = c
+	}
+
+	var diffs []Difference
+	for _, ref := range reference {
+		key := ref.Time.UnixMilli()
+		got, ok := byTime[key]
+		if !ok {
+			diffs = append(diffs, Difference{Time: ref.Time, Field: "missing", Reference: ref.Close})
+			continue
+		}
+		delete(byTime, key)
+		fields := []struct {
+			name     string
+			got, ref float64
+		}{
+			{"open", got.Open, ref.Open},
+			{"high", got.High, ref.High},
+			{"low", got.Low, ref.Low},
+			{"close", got.Close, ref.Close},
+		}
+		for _, f := range fields {
+			if math.Abs(f.got-f.ref) > tolerance {
+				diffs = append(diffs, Difference{Time: ref.Time, Field: f.name, Archived: f.got, Reference: f.ref})
+			}
+		}
+	}
+	for _, c := range byTime {
+		diffs = append(diffs, Difference{Time: c.Time, Field: "extra", Archived: c.Close})
+	}
+	sort.SliceStable(diffs, func(i, j int) bool { return diffs[i].Time.Before(diffs[j].Time) })
+	return diffs
+}
+
+// CheckCandles recomputes the hash of every window in the manifest from the
+// archive and reports windows whose data changed
+func CheckCandles(ctx context.Context, archive CandleArchive, m Manifest) (*Report, error) {
+	return check(ctx, m, func(w Window) (string, error) {
+		candles, err := archive.Candles(ctx, w.Pair, w.From, w.To)
+		if err != nil {
+			return "", err
+		}
+		return HashCandles(inWindow(w, candles)), nil
+	})
+}
+
+// CheckQuotes recomputes the hash of every window in the manifest from the
+// tick archive and reports windows whose data changed
+func CheckQuotes(ctx context.Context, archive QuoteArchive, m Manifest) (*Report, error) {
+	return check(ctx, m, func(w Window) (string, error) {
+		quotes, err := archive.Quotes(ctx, w.Pair, w.From, w.To)
+		if err != nil {
+			return "", err
+		}
+		in := quotes[:0:0]
+		for _, q := range quotes {
+			t := q.Time
+			if t.IsZero() {
+				t, _ = q.Timestamp()
+			}
+			if w.contains(t) {
+				in = append(in, q)
+			}
+		}
+		return HashQuotes(in), nil
+	})
+}
+
+func check(ctx context.Context, m Manifest, hash func(Window) (string, error)) (*Report, error) {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	report := &Report{}
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		w, err := ParseWindow(key)
+		if err != nil {
+			return report, err
+		}
+		result := WindowResult{Window: w, ReferenceHash: m[key]}
+		if result.ArchiveHash, err = hash(w); err != nil {
+			result.Err = fmt.Errorf("failed to read archive: %w", err)
+		}
+		report.add(result)
+	}
+	return report, nil
+}
+
+// String summarizes the report, listing the first differences of each
+// mismatched window
+func (r *Report) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d windows checked, %d mismatched, %d failed\n", r.Checked, r.Mismatched, r.Failed)
+	for _, w := range r.Windows {
+		if w.OK() {
+			continue
+		}
+		fmt.Fprintf(&sb, "%s %s to %s:", w.Pair, w.From.Format(time.RFC3339), w.To.Format(time.RFC3339))
+		if w.Err != nil {
+			fmt.Fprintf(&sb, " %v\n", w.Err)
+			continue
+		}
+		fmt.Fprintf(&sb, " archive %.12s reference %.12s\n", w.ArchiveHash, w.ReferenceHash)
+		for i, d := range w.Differences {
+			if i == 5 {
+				fmt.Fprintf(&sb, "  ... %d more\n", len(w.Differences)-i)
+				break
+			}
+			fmt.Fprintf(&sb, "  %s %s archived %v reference %v\n", d.Time.Format(time.RFC3339), d.Field, d.Archived, d.Reference)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,165 @@
+package tradermadews
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Broker fans one client's quotes out to independent in-process subscribers,
+// each with its own symbol filter and buffer. Symbols are reference counted:
+// the first subscriber to a symbol adds it to the client's subscription and
+// the last one to leave removes it again.
+type Broker struct {
+	client   *WebSocketClient
+	upstream sync.Mutex // Held across a reference count change and the client call it causes
+	mu       sync.RWMutex
+	subs     map[*QuoteSubscription]struct{}
+	refs     map[string]int  // Subscribers per symbol
+	pinned   map[string]bool // Symbols subscribed outside the broker, never removed by it
+}
+
+// QuoteSubscription is one subscriber of a Broker
+type QuoteSubscription struct {
+	broker  *Broker
+	symbols map[string]bool // Empty receives every symbol
+	ch      chan QuoteMessage
+	dropped atomic.Uint64
+	closed  bool // Guarded by the broker's mu
+}
+
+// Broker returns the client's broker, creating it on first use. The broker
+// receives every delivered quote alongside the client's own handlers.
+func (client *WebSocketClient) Broker() *Broker {
+	client.ConnMutex.Lock()
+	defer client.ConnMutex.Unlock()
+	if broker := client.broker.Load(); broker != nil {
+		return broker
+	}
+
+	pinned := make(map[string]bool)
+	for _, s := range splitSymbols(client.Symbol) {
+		pinned[s] = true
+	}
+	broker := &Broker{
+		client: client,
+		subs:   make(map[*QuoteSubscription]struct{}),
+		refs:   make(map[string]int),
+		pinned: pinned,
+	}
+	client.broker.Store(broker)
+	return broker
+}
+
+// Subscribe adds a subscriber receiving quotes for the given symbols through a
+// channel of bufferSize quotes, or every symbol when none are given. Quotes
+// are dropped when the subscriber's buffer is full, without affecting other
+// subscribers.
+func (b *Broker) Subscribe(bufferSize int, symbols ...string) (*QuoteSubscription, error) {
+	symbols, err := b.client.resolveSymbols(symbols)
+	if err != nil {
+		return nil, err
+	}
+	if bufferSize <= 0 {
+		bufferSize = 256
+	}
+	sub := &QuoteSubscription{
+		broker:  b,
+		symbols: make(map[string]bool, len(symbols)),
+		ch:      make(chan QuoteMessage, bufferSize),
+	}
+	for _, s := range symbols {
+		sub.symbols[s] = true
+	}
+
+	b.upstream.Lock()
+	defer b.upstream.Unlock()
+	b.mu.Lock()
+	var added []string
+	for s := range sub.symbols {
+		if b.refs[s]++; b.refs[s] == 1 && !b.pinned[s] {
+			added = append(added, s)
+		}
+	}
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+
+	if len(added) > 0 {
+		if err := b.client.Subscribe(added...); err != nil {
+			b.release(sub) // Only the symbols that just failed can drop to zero
+			return nil, fmt.Errorf("failed to subscribe to %v: %w", added, err)
+		}
+	}
+	return sub, nil
+}
+
+// Subscribers returns the number of open subscriptions
+func (b *Broker) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs)
+}
+
+// publish passes a quote to every subscriber wanting its symbol
+func (b *Broker) publish(quote QuoteMessage) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.subs {
+		if len(sub.symbols) > 0 && !sub.symbols[quote.Symbol] {
+			continue
+		}
+		select {
+		case sub.ch <- quote:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// Quotes returns the channel receiving the subscription's quotes. It is
+// closed by Close.
+func (s *QuoteSubscription) Quotes() <-chan QuoteMessage {
+	return s.ch
+}
+
+// Dropped returns the number of quotes dropped because the buffer was full
+func (s *QuoteSubscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close ends the subscription, closing its channel and removing symbols no
+// other subscriber wants from the client's subscription. It is safe to call
+// more than once.
+func (s *QuoteSubscription) Close() error {
+	b := s.broker
+	b.upstream.Lock()
+	defer b.upstream.Unlock()
+	if removed := b.release(s); len(removed) > 0 {
+		return b.client.Unsubscribe(removed...)
+	}
+	return nil
+}
+
+// release closes a subscription and returns the symbols no subscriber wants
+// any more. The caller must hold upstream until the client is updated, so a
+// concurrent Subscribe cannot re-add a symbol before it is removed.
+func (b *Broker) release(s *QuoteSubscription) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	delete(b.subs, s)
+	close(s.ch)
+	var removed []string
+	for symbol := range s.symbols {
+		if b.refs[symbol]--; b.refs[symbol] <= 0 {
+			delete(b.refs, symbol)
+			if !b.pinned[symbol] {
+				removed = append(removed, symbol)
+			}
+		}
+	}
+	return removed
+}
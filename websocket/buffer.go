@@ -0,0 +1,78 @@
+package tradermadews
+
+// OverflowPolicy decides what happens when the message buffer is full
+type OverflowPolicy int
+
+const (
+	DropOldest OverflowPolicy = iota // Discard the oldest buffered message to make room
+	DropNewest                       // Discard the message just received
+	Block                            // Stop reading from the socket until there is room
+)
+
+// SetBuffer queues received messages in a buffer of size messages so a slow
+// handler doesn't stall the socket. Handlers then run on their own goroutine.
+// policy decides what happens when the buffer is full. Zero size disables the
+// buffer and runs handlers on the read loop. Takes effect on the next connection.
+func (client *WebSocketClient) SetBuffer(size int, policy OverflowPolicy) {
+	client.BufferSize = size
+	client.OverflowPolicy = policy
+}
+
+// SetOverflowHandler sets the callback function called with the total number
+// of dropped messages every time the buffer overflows
+func (client *WebSocketClient) SetOverflowHandler(handler func(dropped uint64)) {
+	client.OverflowHandler = handler
+}
+
+// Dropped returns the number of messages dropped by buffer overflows
+func (client *WebSocketClient) Dropped() uint64 {
+	return client.dropped.Load()
+}
+
+// startDispatcher returns the queue feeding the handlers for one connection,
+// or nil when buffering is disabled. Closing the queue stops the dispatcher
+// once the remaining messages are handled, after which drained is closed.
+func (client *WebSocketClient) startDispatcher() (queue chan parsedMessage, drained chan struct{}) {
+	if client.BufferSize <= 0 {
+		return nil, nil
+	}
+	queue = make(chan parsedMessage, client.BufferSize)
+	drained = make(chan struct{})
+	go func() {
+		defer close(drained)
+		for msg := range queue {
+			client.dispatch(msg)
+		}
+	}()
+	return queue, drained
+}
+
+// enqueue adds msg to the queue according to the overflow policy. It is only
+// called from the read loop, so there is a single producer.
+func (client *WebSocketClient) enqueue(queue chan parsedMessage, msg parsedMessage) {
+	if client.OverflowPolicy == Block {
+		queue <- msg
+		return
+	}
+
+	select {
+	case queue <- msg:
+		return
+	default:
+	}
+
+	if client.OverflowPolicy == DropOldest {
+		select {
+		case <-queue:
+		default:
+		}
+		select {
+		case queue <- msg:
+		default:
+		}
+	}
+	dropped := client.dropped.Add(1)
+	if client.OverflowHandler != nil {
+		client.callHandler("overflow handler", func() { client.OverflowHandler(dropped) })
+	}
+}
@@ -0,0 +1,41 @@
+package tradermadews
+
+import "sync"
+
+// quoteCache holds the latest quote received for each symbol
+type quoteCache struct {
+	mu     sync.RWMutex
+	quotes map[string]QuoteMessage
+}
+
+// set stores quote and returns the symbol's previous quote, if any
+func (c *quoteCache) set(quote QuoteMessage) (QuoteMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.quotes == nil {
+		c.quotes = make(map[string]QuoteMessage)
+	}
+	prev, ok := c.quotes[quote.Symbol]
+	c.quotes[quote.Symbol] = quote
+	return prev, ok
+}
+
+// GetLastQuote returns the latest quote received for a symbol. The boolean
+// is false if no quote has been received for it yet.
+func (client *WebSocketClient) GetLastQuote(symbol string) (QuoteMessage, bool) {
+	client.lastQuotes.mu.RLock()
+	defer client.lastQuotes.mu.RUnlock()
+	quote, ok := client.lastQuotes.quotes[symbol]
+	return quote, ok
+}
+
+// Snapshot returns a copy of the latest quote for every symbol received so far
+func (client *WebSocketClient) Snapshot() map[string]QuoteMessage {
+	client.lastQuotes.mu.RLock()
+	defer client.lastQuotes.mu.RUnlock()
+	snapshot := make(map[string]QuoteMessage, len(client.lastQuotes.quotes))
+	for symbol, quote := range client.lastQuotes.quotes {
+		snapshot[symbol] = quote
+	}
+	return snapshot
+}
@@ -0,0 +1,106 @@
+package tradermadews
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tradermade/Go-SDK/clock"
+)
+
+// conflator holds back quotes that arrive faster than the conflation interval,
+// keeping only the latest per symbol
+type conflator struct {
+	mu       sync.Mutex
+	lastSent map[string]time.Time
+	pending  map[string]parsedMessage
+	running  bool // Whether the flush goroutine is active
+}
+
+// SetConflation limits delivery to at most one quote per symbol per interval.
+// Quotes arriving within the interval replace each other and the latest is
+// delivered when the interval ends. Zero disables conflation.
+func (client *WebSocketClient) SetConflation(interval time.Duration) {
+	client.ConflationInterval = interval
+}
+
+// conflate delivers msg now if its symbol is outside the interval, otherwise
+// keeps it as the symbol's pending quote. Returns true if msg was held back.
+func (client *WebSocketClient) conflate(msg parsedMessage) bool {
+	interval := client.ConflationInterval
+	if interval <= 0 {
+		return false
+	}
+
+	c := &client.conflation
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lastSent == nil {
+		c.lastSent = make(map[string]time.Time)
+		c.pending = make(map[string]parsedMessage)
+	}
+
+	symbol := msg.quote.Symbol
+	now := clock.OrDefault(client.Clock).Now()
+	if _, waiting := c.pending[symbol]; !waiting && now.Sub(c.lastSent[symbol]) >= interval {
+		c.lastSent[symbol] = now
+		return false
+	}
+
+	c.pending[symbol] = msg
+	if !c.running {
+		c.running = true
+		go client.flushConflated(interval)
+	}
+	return true
+}
+
+// flushConflated delivers pending quotes as their intervals end, exiting once nothing is pending
+func (client *WebSocketClient) flushConflated(interval time.Duration) {
+	tick := interval / 4
+	if tick < time.Millisecond {
+		tick = time.Millisecond
+	}
+	ticker := clock.OrDefault(client.Clock).NewTicker(tick)
+	defer ticker.Stop()
+
+	c := &client.conflation
+	for now := range ticker.C() {
+		var due []parsedMessage
+		c.mu.Lock()
+		for symbol, msg := range c.pending {
+			if now.Sub(c.lastSent[symbol]) >= interval {
+				due = append(due, msg)
+				c.lastSent[symbol] = now
+				delete(c.pending, symbol)
+			}
+		}
+		idle := len(c.pending) == 0
+		if idle {
+			c.running = false
+		}
+		c.mu.Unlock()
+
+		for _, msg := range due {
+			client.deliverQuote(msg)
+		}
+		if idle {
+			return
+		}
+	}
+}
+
+// flushPendingConflated delivers every pending quote now, on shutdown
+func (client *WebSocketClient) flushPendingConflated() {
+	c := &client.conflation
+	c.mu.Lock()
+	due := make([]parsedMessage, 0, len(c.pending))
+	for symbol, msg := range c.pending {
+		due = append(due, msg)
+		delete(c.pending, symbol)
+	}
+	c.mu.Unlock()
+
+	for _, msg := range due {
+		client.deliverQuote(msg)
+	}
+}
@@ -0,0 +1,56 @@
+package tradermadews
+
+import "sync"
+
+// dedupeWindow is the number of recent quotes per symbol checked for duplicates
+const dedupeWindow = 16
+
+// tickKey identifies a quote for duplicate detection
+type tickKey struct {
+	ts       string
+	bid, ask float64
+}
+
+// deduper remembers the latest quotes per symbol so messages resent around
+// reconnects are delivered once
+type deduper struct {
+	mu         sync.Mutex
+	recent     map[string]*[dedupeWindow]tickKey
+	next       map[string]int
+	duplicates uint64
+}
+
+// isDuplicate reports whether quote matches one of its symbol's recent quotes
+// on Ts, Bid and Ask, remembering it otherwise
+func (d *deduper) isDuplicate(quote *QuoteMessage) bool {
+	key := tickKey{ts: quote.Ts, bid: quote.Bid, ask: quote.Ask}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.recent == nil {
+		d.recent = make(map[string]*[dedupeWindow]tickKey)
+		d.next = make(map[string]int)
+	}
+	ring := d.recent[quote.Symbol]
+	if ring == nil {
+		ring = new([dedupeWindow]tickKey)
+		d.recent[quote.Symbol] = ring
+	}
+	for _, k := range ring {
+		if k == key {
+			d.duplicates++
+			return true
+		}
+	}
+	i := d.next[quote.Symbol]
+	ring[i] = key
+	d.next[quote.Symbol] = (i + 1) % dedupeWindow
+	return false
+}
+
+// count returns the number of duplicates dropped
+func (d *deduper) count() uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.duplicates
+}
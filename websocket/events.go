@@ -0,0 +1,83 @@
+package tradermadews
+
+import (
+	"fmt"
+	"time"
+)
+
+// EventType identifies a connection lifecycle event
+type EventType int
+
+const (
+	EventConnected       EventType = iota // Server confirmed the connection
+	EventDisconnected                     // Connection dropped or was closed
+	EventReconnecting                     // A reconnection attempt is starting
+	EventReconnectFailed                  // Reconnection gave up after MaxRetries
+	EventSymbolStale                      // A symbol has not quoted for StaleAfter
+	EventOutlier                          // The outlier filter flagged or dropped a quote
+)
+
+// String returns the event type name
+func (t EventType) String() string {
+	switch t {
+	case EventConnected:
+		return "connected"
+	case EventDisconnected:
+		return "disconnected"
+	case EventReconnecting:
+		return "reconnecting"
+	case EventReconnectFailed:
+		return "reconnect_failed"
+	case EventSymbolStale:
+		return "symbol_stale"
+	case EventOutlier:
+		return "outlier"
+	default:
+		return "unknown"
+	}
+}
+
+// ConnectionEvent describes a change in the connection's lifecycle
+type ConnectionEvent struct {
+	Type    EventType
+	Time    time.Time
+	Attempt int    // Reconnection attempt number for EventReconnecting
+	Message string // Server message for EventConnected, details for EventOutlier
+	Symbol  string // Symbol for EventSymbolStale and EventOutlier
+	Err     error  // Cause for EventDisconnected and EventReconnectFailed
+}
+
+// Errors returns a channel receiving read, parse and write errors. Errors are
+// dropped when the channel's buffer is full.
+func (client *WebSocketClient) Errors() <-chan error {
+	return client.errors
+}
+
+// Events returns a channel receiving connection lifecycle events. Events are
+// dropped when the channel's buffer is full.
+func (client *WebSocketClient) Events() <-chan ConnectionEvent {
+	return client.events
+}
+
+// reportError passes an error to the error handler, or prints it when no
+// handler is set, and publishes it on the Errors channel
+func (client *WebSocketClient) reportError(err error) {
+	if client.ErrorHandler != nil {
+		client.callErrorHandler(err)
+	} else {
+		fmt.Printf("%v\n", err)
+	}
+	select {
+	case client.errors <- err:
+	default:
+	}
+}
+
+// emitEvent publishes a lifecycle event on the Events channel
+func (client *WebSocketClient) emitEvent(event ConnectionEvent) {
+	event.Time = time.Now()
+	select {
+	case client.events <- event:
+	default:
+	}
+}
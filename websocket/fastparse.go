@@ -0,0 +1,179 @@
+package tradermadews
+
+import (
+	"bytes"
+	"strconv"
+	"sync"
+	"unsafe"
+
+	"github.com/gorilla/websocket"
+)
+
+// maxFrameBuffer is the largest read buffer kept between frames. A larger
+// frame is read into a buffer that is dropped afterwards.
+const maxFrameBuffer = 64 << 10
+
+// readFrame reads the next message into buf, reusing its storage. The
+// returned slice is only valid until the next call.
+func readFrame(conn *websocket.Conn, buf *bytes.Buffer) ([]byte, error) {
+	_, r, err := conn.NextReader()
+	if err != nil {
+		return nil, err
+	}
+	if buf.Cap() > maxFrameBuffer {
+		*buf = bytes.Buffer{}
+	}
+	buf.Reset()
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// parseQuoteFast decodes the flat quote object sent by the feed, e.g.
+// {"symbol":"EURUSD","ts":"1700000000000","bid":1.1,"ask":1.2,"mid":1.15},
+// without reflection. It returns false for anything else, including escaped
+// strings, unknown keys and "Connected" messages, and the caller falls back
+// to encoding/json.
+func parseQuoteFast(b []byte, quote *QuoteMessage) bool {
+	i := skipSpace(b, 0)
+	if i == len(b) || b[i] != '{' {
+		return false
+	}
+	i = skipSpace(b, i+1)
+
+	for {
+		key, next, ok := scanString(b, i)
+		if !ok {
+			return false
+		}
+		i = skipSpace(b, next)
+		if i == len(b) || b[i] != ':' {
+			return false
+		}
+		i = skipSpace(b, i+1)
+		if i == len(b) {
+			return false
+		}
+
+		quoted := b[i] == '"'
+		var value []byte
+		if quoted {
+			value, next, ok = scanString(b, i)
+		} else {
+			value, next, ok = scanLiteral(b, i)
+		}
+		if !ok {
+			return false
+		}
+		i = skipSpace(b, next)
+
+		switch string(key) {
+		case "symbol":
+			if !quoted {
+				return false
+			}
+			quote.Symbol = internSymbol(value)
+		case "ts":
+			if !quoted {
+				return false
+			}
+			quote.Ts = string(value)
+		case "bid", "ask", "mid":
+			if quoted {
+				return false
+			}
+			// The string only lives for the call; on error we fall back and drop it
+			f, err := strconv.ParseFloat(unsafe.String(unsafe.SliceData(value), len(value)), 64)
+			if err != nil {
+				return false
+			}
+			switch key[0] {
+			case 'b':
+				quote.Bid = f
+			case 'a':
+				quote.Ask = f
+			default:
+				quote.Mid = f
+			}
+		default:
+			return false
+		}
+
+		if i == len(b) {
+			return false
+		}
+		switch b[i] {
+		case ',':
+			i = skipSpace(b, i+1)
+		case '}':
+			return skipSpace(b, i+1) == len(b)
+		default:
+			return false
+		}
+	}
+}
+
+// skipSpace returns the index of the first non-whitespace byte at or after i
+func skipSpace(b []byte, i int) int {
+	for i < len(b) && (b[i] == ' ' || b[i] == '\t' || b[i] == '\n' || b[i] == '\r') {
+		i++
+	}
+	return i
+}
+
+// scanString returns the contents of the unescaped string starting at b[i]
+// and the index after its closing quote
+func scanString(b []byte, i int) ([]byte, int, bool) {
+	if i == len(b) || b[i] != '"' {
+		return nil, i, false
+	}
+	for j := i + 1; j < len(b); j++ {
+		switch b[j] {
+		case '"':
+			return b[i+1 : j], j + 1, true
+		case '\\':
+			return nil, i, false
+		}
+	}
+	return nil, i, false
+}
+
+// scanLiteral returns the number or literal starting at b[i] and the index after it
+func scanLiteral(b []byte, i int) ([]byte, int, bool) {
+	j := i
+	for j < len(b) && b[j] != ',' && b[j] != '}' && b[j] != ' ' && b[j] != '\t' && b[j] != '\n' && b[j] != '\r' {
+		if b[j] == '{' || b[j] == '[' || b[j] == '"' {
+			return nil, i, false
+		}
+		j++
+	}
+	return b[i:j], j, j > i
+}
+
+// maxInternedSymbols bounds the symbol table against unexpected input
+const maxInternedSymbols = 4096
+
+var symbolTable = struct {
+	sync.RWMutex
+	m map[string]string
+}{m: make(map[string]string)}
+
+// internSymbol returns a shared string for a symbol so repeated quotes don't
+// allocate a new one each time
+func internSymbol(b []byte) string {
+	symbolTable.RLock()
+	s, ok := symbolTable.m[string(b)]
+	symbolTable.RUnlock()
+	if ok {
+		return s
+	}
+
+	s = string(b)
+	symbolTable.Lock()
+	if len(symbolTable.m) < maxInternedSymbols {
+		symbolTable.m[s] = s
+	}
+	symbolTable.Unlock()
+	return s
+}
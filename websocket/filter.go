@@ -0,0 +1,136 @@
+package tradermadews
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Filter inspects a quote before delivery. It returns the quote to deliver,
+// possibly modified, and false to drop it. Filters built by this package are
+// safe for concurrent use and keep their own state, so give each client or
+// poller its own instances.
+type Filter func(QuoteMessage) (QuoteMessage, bool)
+
+// Chain combines filters into one that applies them in order, stopping at
+// the first that drops the quote
+func Chain(filters ...Filter) Filter {
+	return func(quote QuoteMessage) (QuoteMessage, bool) {
+		return ApplyFilters(filters, quote)
+	}
+}
+
+// ApplyFilters runs quote through filters in order, stopping at the first
+// that drops it. Nil filters are skipped.
+func ApplyFilters(filters []Filter, quote QuoteMessage) (QuoteMessage, bool) {
+	for _, f := range filters {
+		if f == nil {
+			continue
+		}
+		var ok bool
+		if quote, ok = f(quote); !ok {
+			return quote, false
+		}
+	}
+	return quote, true
+}
+
+// AddFilter appends filters to the chain applied to every quote before it is
+// cached and delivered, after the client's built-in duplicate and outlier
+// checks. Add filters before connecting.
+func (client *WebSocketClient) AddFilter(filters ...Filter) {
+	client.Filters = append(client.Filters, filters...)
+}
+
+// DedupeFilter drops quotes repeating one of the symbol's recent quotes on
+// Ts, Bid and Ask
+func DedupeFilter() Filter {
+	d := &deduper{}
+	return func(quote QuoteMessage) (QuoteMessage, bool) {
+		return quote, !d.isDuplicate(&quote)
+	}
+}
+
+// OutlierFilter checks quotes against the median mid of the symbol's last
+// window quotes. A quote deviating by more than percent is dropped, or passed
+// on with Outlier set if drop is false.
+func OutlierFilter(percent float64, window int, drop bool) Filter {
+	if window < minOutlierSamples {
+		window = 21
+	}
+	f := &outlierFilter{}
+	return func(quote QuoteMessage) (QuoteMessage, bool) {
+		if math.Abs(f.deviation(&quote, window)) <= percent {
+			return quote, true
+		}
+		quote.Outlier = true
+		return quote, !drop
+	}
+}
+
+// ConflationFilter passes at most one quote per symbol per interval of quote
+// time, dropping the rest. Unlike SetConflation it never holds quotes back,
+// so the last quote of a burst may be lost.
+func ConflationFilter(interval time.Duration) Filter {
+	var mu sync.Mutex
+	lastSent := make(map[string]time.Time)
+	return func(quote QuoteMessage) (QuoteMessage, bool) {
+		t := quote.Time
+		if t.IsZero() {
+			t, _ = quote.Timestamp()
+		}
+		if t.IsZero() {
+			t = time.Now()
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if last, ok := lastSent[quote.Symbol]; ok && t.Sub(last) < interval {
+			return quote, false
+		}
+		lastSent[quote.Symbol] = t
+		return quote, true
+	}
+}
+
+// OnlyOnChangeFilter drops quotes whose bid and ask equal the symbol's
+// previous quote
+func OnlyOnChangeFilter() Filter {
+	var mu sync.Mutex
+	last := make(map[string][2]float64)
+	return func(quote QuoteMessage) (QuoteMessage, bool) {
+		prices := [2]float64{quote.Bid, quote.Ask}
+		mu.Lock()
+		defer mu.Unlock()
+		prev, seen := last[quote.Symbol]
+		last[quote.Symbol] = prices
+		return quote, !seen || prev != prices
+	}
+}
+
+// SymbolFilter passes only quotes for the given symbols
+func SymbolFilter(symbols ...string) Filter {
+	allowed := make(map[string]bool, len(symbols))
+	for _, s := range symbols {
+		allowed[strings.TrimSpace(s)] = true
+	}
+	return func(quote QuoteMessage) (QuoteMessage, bool) {
+		return quote, allowed[quote.Symbol]
+	}
+}
+
+// SymbolMapFilter renames symbols found in mapping, e.g. to internal
+// instrument codes, and passes other quotes unchanged
+func SymbolMapFilter(mapping map[string]string) Filter {
+	m := make(map[string]string, len(mapping))
+	for from, to := range mapping {
+		m[from] = to
+	}
+	return func(quote QuoteMessage) (QuoteMessage, bool) {
+		if to, ok := m[quote.Symbol]; ok {
+			quote.Symbol = to
+		}
+		return quote, true
+	}
+}
@@ -0,0 +1,44 @@
+package tradermadews
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// isHeartbeat reports whether a non-JSON status frame is a feed heartbeat
+func isHeartbeat(status string) bool {
+	return strings.EqualFold(strings.TrimSpace(status), "heartbeat")
+}
+
+// extendDeadline pushes the read deadline out by HeartbeatTimeout. Any frame,
+// heartbeat or pong proves the connection is alive.
+func (client *WebSocketClient) extendDeadline(conn *websocket.Conn) {
+	if client.HeartbeatTimeout <= 0 {
+		return
+	}
+	conn.SetReadDeadline(time.Now().Add(client.HeartbeatTimeout))
+}
+
+// keepAlive pings the server every PingInterval until stop is closed. A failed
+// ping closes the connection so the read pump notices and reconnects.
+func (client *WebSocketClient) keepAlive(conn *websocket.Conn, stop <-chan struct{}) {
+	if client.PingInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(client.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(client.PingInterval)); err != nil {
+				conn.Close()
+				return
+			}
+		case <-stop:
+			return
+		}
+	}
+}
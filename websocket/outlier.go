@@ -0,0 +1,91 @@
+package tradermadews
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// minOutlierSamples is the number of quotes a symbol needs before filtering starts
+const minOutlierSamples = 5
+
+// outlierFilter holds the recent mid prices per symbol, see SetOutlierFilter
+type outlierFilter struct {
+	mu      sync.Mutex
+	history map[string]*midRing
+	scratch []float64 // Reused for computing medians
+}
+
+// midRing is a fixed-size ring of recent mid prices
+type midRing struct {
+	mids []float64
+	next int
+}
+
+// SetOutlierFilter checks every quote against the median mid price of the
+// symbol's last window quotes. A quote deviating by more than percent is
+// dropped, or delivered with Outlier set if drop is false, and reported as an
+// EventOutlier. Zero percent disables the filter.
+func (client *WebSocketClient) SetOutlierFilter(percent float64, window int, drop bool) {
+	client.OutlierPercent = percent
+	client.OutlierWindow = window
+	client.OutlierDrop = drop
+}
+
+// checkOutlier flags quote if it deviates from the recent median and reports
+// whether it should be dropped. Every quote enters the history, so a genuine
+// jump in price becomes the median after half a window.
+func (client *WebSocketClient) checkOutlier(quote *QuoteMessage) bool {
+	if client.OutlierPercent <= 0 {
+		return false
+	}
+	window := client.OutlierWindow
+	if window < minOutlierSamples {
+		window = 21
+	}
+
+	deviation := client.outliers.deviation(quote, window)
+	if math.Abs(deviation) <= client.OutlierPercent {
+		return false
+	}
+	quote.Outlier = true
+	client.emitEvent(ConnectionEvent{
+		Type:    EventOutlier,
+		Symbol:  quote.Symbol,
+		Message: fmt.Sprintf("mid %v deviates %.2f%% from the recent median", quote.Mid, deviation),
+	})
+	return client.OutlierDrop
+}
+
+// deviation returns how far quote's mid is from the symbol's recent median in
+// percent, zero until minOutlierSamples quotes are known, and adds it to the
+// window
+func (f *outlierFilter) deviation(quote *QuoteMessage, window int) float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.history == nil {
+		f.history = make(map[string]*midRing)
+	}
+	ring := f.history[quote.Symbol]
+	if ring == nil {
+		ring = &midRing{}
+		f.history[quote.Symbol] = ring
+	}
+
+	var deviation float64
+	if len(ring.mids) >= minOutlierSamples {
+		f.scratch = append(f.scratch[:0], ring.mids...)
+		sort.Float64s(f.scratch)
+		if median := f.scratch[len(f.scratch)/2]; median != 0 {
+			deviation = (quote.Mid - median) / median * 100
+		}
+	}
+	if len(ring.mids) < window {
+		ring.mids = append(ring.mids, quote.Mid)
+	} else {
+		ring.mids[ring.next] = quote.Mid
+		ring.next = (ring.next + 1) % window
+	}
+	return deviation
+}
@@ -0,0 +1,45 @@
+package tradermadews
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tradermade/Go-SDK/codec"
+)
+
+var benchQuote = []byte(`{"symbol":"EURUSD","ts":"1700000000123","bid":1.08412,"ask":1.08415,"mid":1.084135}`)
+
+func BenchmarkParseMessage(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := parseMessage(benchQuote, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseMessageJSON(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := parseMessageJSON(benchQuote, codec.JSON{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDispatchQuote(b *testing.B) {
+	client := NewWebSocketClient("", "EURUSD")
+	client.SetMessageHandler(func(QuoteMessage, string) {})
+	client.KeepDuplicates = true // The same quote every iteration would otherwise only measure dedupe
+	msg, err := parseMessage(benchQuote, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	msg.received = time.Now()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		client.dispatch(msg)
+	}
+}
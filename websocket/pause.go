@@ -0,0 +1,39 @@
+package tradermadews
+
+// Pause stops delivering quotes to the handlers and the Quotes channel without
+// closing the connection. GetLastQuote keeps tracking the latest quotes unless
+// PauseUnsubscribes is set, in which case the upstream subscription is
+// dropped until Resume.
+func (client *WebSocketClient) Pause() error {
+	client.ConnMutex.Lock()
+	defer client.ConnMutex.Unlock()
+
+	if client.paused.Swap(true) || !client.PauseUnsubscribes || client.Conn == nil {
+		return nil
+	}
+	return client.sendCredentials()
+}
+
+// Resume restarts delivery after Pause, resubscribing upstream if needed
+func (client *WebSocketClient) Resume() error {
+	client.ConnMutex.Lock()
+	defer client.ConnMutex.Unlock()
+
+	if !client.paused.Swap(false) || !client.PauseUnsubscribes || client.Conn == nil {
+		return nil
+	}
+	return client.sendCredentials()
+}
+
+// Paused reports whether delivery is paused
+func (client *WebSocketClient) Paused() bool {
+	return client.paused.Load()
+}
+
+// subscription returns the symbol list to send upstream
+func (client *WebSocketClient) subscription() string {
+	if client.PauseUnsubscribes && client.paused.Load() {
+		return ""
+	}
+	return client.Symbol
+}
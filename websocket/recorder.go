@@ -0,0 +1,166 @@
+package tradermadews
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Recordings use a simple framed binary format. A file starts with the
+// 6 byte magic "TMREC1", followed by one frame per received message:
+//
+//	8 bytes  receive time as Unix nanoseconds, int64 big-endian
+//	4 bytes  payload length N, uint32 big-endian
+//	N bytes  payload, the WebSocket message exactly as received
+//
+// Every message is recorded, including connection status and non-JSON frames.
+const recordingMagic = "TMREC1"
+
+// maxFrameSize guards against reading corrupt length fields
+const maxFrameSize = 16 << 20
+
+// Recorder writes received messages in the recording format
+type Recorder struct {
+	mu           sync.Mutex
+	w            *bufio.Writer
+	wroteHeader  bool
+	err          error
+	FlushOnWrite bool // Flush after every frame so a crash loses nothing (default true)
+}
+
+// NewRecorder creates a recorder writing to w
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{
+		w:            bufio.NewWriter(w),
+		FlushOnWrite: true,
+	}
+}
+
+// WriteFrame records one message received at t
+func (r *Recorder) WriteFrame(t time.Time, payload []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+
+	if !r.wroteHeader {
+		if _, r.err = r.w.WriteString(recordingMagic); r.err != nil {
+			return r.err
+		}
+		r.wroteHeader = true
+	}
+
+	var header [12]byte
+	binary.BigEndian.PutUint64(header[0:8], uint64(t.UnixNano()))
+	binary.BigEndian.PutUint32(header[8:12], uint32(len(payload)))
+	if _, r.err = r.w.Write(header[:]); r.err != nil {
+		return r.err
+	}
+	if _, r.err = r.w.Write(payload); r.err != nil {
+		return r.err
+	}
+	if r.FlushOnWrite {
+		r.err = r.w.Flush()
+	}
+	return r.err
+}
+
+// Flush writes any buffered frames to the underlying writer
+func (r *Recorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	r.err = r.w.Flush()
+	return r.err
+}
+
+// FrameReader reads frames from a recording
+type FrameReader struct {
+	r          *bufio.Reader
+	readHeader bool
+}
+
+// NewFrameReader creates a reader for a recording
+func NewFrameReader(r io.Reader) *FrameReader {
+	return &FrameReader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next frame's receive time and payload. It returns io.EOF
+// at the end of the recording.
+func (fr *FrameReader) Next() (time.Time, []byte, error) {
+	if !fr.readHeader {
+		magic := make([]byte, len(recordingMagic))
+		if _, err := io.ReadFull(fr.r, magic); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				return time.Time{}, nil, fmt.Errorf("not a TraderMade recording")
+			}
+			return time.Time{}, nil, err
+		}
+		if string(magic) != recordingMagic {
+			return time.Time{}, nil, fmt.Errorf("not a TraderMade recording")
+		}
+		fr.readHeader = true
+	}
+
+	var header [12]byte
+	if _, err := io.ReadFull(fr.r, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return time.Time{}, nil, fmt.Errorf("truncated frame header")
+		}
+		return time.Time{}, nil, err
+	}
+	t := time.Unix(0, int64(binary.BigEndian.Uint64(header[0:8])))
+	size := binary.BigEndian.Uint32(header[8:12])
+	if size > maxFrameSize {
+		return time.Time{}, nil, fmt.Errorf("frame of %d bytes exceeds maximum size", size)
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(fr.r, payload); err != nil {
+		return time.Time{}, nil, fmt.Errorf("truncated frame payload: %w", err)
+	}
+	return t, payload, nil
+}
+
+// Record starts writing every received message to w in the recording format.
+// It replaces any active recording and returns the recorder so callers can Flush it.
+func (client *WebSocketClient) Record(w io.Writer) *Recorder {
+	recorder := NewRecorder(w)
+	client.recorderMu.Lock()
+	client.recorder = recorder
+	client.recorderMu.Unlock()
+	return recorder
+}
+
+// StopRecording detaches the active recorder, flushing it first
+func (client *WebSocketClient) StopRecording() error {
+	client.recorderMu.Lock()
+	recorder := client.recorder
+	client.recorder = nil
+	client.recorderMu.Unlock()
+
+	if recorder == nil {
+		return nil
+	}
+	return recorder.Flush()
+}
+
+// record passes a received message to the active recorder, if any
+func (client *WebSocketClient) record(t time.Time, message []byte) {
+	client.recorderMu.Lock()
+	recorder := client.recorder
+	client.recorderMu.Unlock()
+
+	if recorder == nil {
+		return
+	}
+	if err := recorder.WriteFrame(t, message); err != nil {
+		client.reportError(fmt.Errorf("Failed to record message: %w", err))
+	}
+}
@@ -0,0 +1,25 @@
+package tradermadews
+
+import "fmt"
+
+// callHandler runs a user callback, reporting a panic as an error so one bad
+// handler can't kill the read loop and silently stop the feed
+func (client *WebSocketClient) callHandler(name string, fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			client.reportError(fmt.Errorf("%s panicked: %v", name, r))
+		}
+	}()
+	fn()
+}
+
+// callErrorHandler runs the error handler, printing a panic since it can't
+// be reported to the handler that caused it
+func (client *WebSocketClient) callErrorHandler(err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("error handler panicked: %v (handling: %v)\n", r, err)
+		}
+	}()
+	client.ErrorHandler(err)
+}
@@ -0,0 +1,42 @@
+package tradermadews
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/tradermade/Go-SDK/internal/redact"
+)
+
+// String describes the client with its API key redacted, so printing the
+// client with %v or %+v does not leak the key
+func (client *WebSocketClient) String() string {
+	return fmt.Sprintf("tradermadews.WebSocketClient{URL: %q, APIKey: %q, State: %s}",
+		client.feedURL(), redact.Key(client.APIKey), client.GetState())
+}
+
+// GoString is String, covering %#v
+func (client *WebSocketClient) GoString() string {
+	return client.String()
+}
+
+// LogValue describes the client to log/slog with its API key redacted
+func (client *WebSocketClient) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("url", client.feedURL()),
+		slog.String("api_key", redact.Key(client.APIKey)),
+		slog.String("state", client.GetState().String()),
+	)
+}
+
+// String describes the client with its API key redacted
+func (s *ShardedClient) String() string {
+	s.mu.Lock()
+	shards := len(s.shards)
+	s.mu.Unlock()
+	return fmt.Sprintf("tradermadews.ShardedClient{APIKey: %q, Shards: %d}", redact.Key(s.apiKey), shards)
+}
+
+// GoString is String, covering %#v
+func (s *ShardedClient) GoString() string {
+	return s.String()
+}
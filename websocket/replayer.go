@@ -0,0 +1,285 @@
+package tradermadews
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Replayer re-emits a recording made with Record, honoring the original gaps
+// between messages. It has the same handler and channel API as the live client.
+type Replayer struct {
+	MessageHandler   func(QuoteMessage, string)    // Handles market data with a human-readable timestamp
+	QuoteHandler     func(QuoteMessage, time.Time) // Handles market data with the parsed timestamp
+	ConnectedHandler func(ConnectedMessage)        // Handles the recorded "Connected" messages
+
+	TimestampLayout   string         // Layout of the message handler's timestamp (default DefaultTimestampLayout)
+	TimestampLocation *time.Location // Time zone of the message handler's timestamp, nil means UTC
+
+	src     io.Reader
+	frames  *FrameReader
+	mu      sync.Mutex
+	speed   float64         // Playback speed multiplier, <= 0 replays as fast as possible
+	seekTo  time.Time       // Pending seek target
+	current time.Time       // Receive time of the last delivered frame
+	symbols map[string]bool // Subscribed symbols, empty replays everything
+	quotes  chan QuoteMessage
+	stop    chan struct{}
+	done    chan struct{}
+	started bool
+	err     error
+}
+
+// NewReplayer creates a replayer reading a recording from r at real-time speed.
+// Seeking backwards requires r to implement io.Seeker.
+func NewReplayer(r io.Reader) *Replayer {
+	return &Replayer{
+		src:     r,
+		frames:  NewFrameReader(r),
+		speed:   1,
+		symbols: make(map[string]bool),
+		quotes:  make(chan QuoteMessage, 256),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// SetMessageHandler sets the callback function to handle replayed quotes
+func (rp *Replayer) SetMessageHandler(handler func(QuoteMessage, string)) {
+	rp.MessageHandler = handler
+}
+
+// SetQuoteHandler sets the callback function to handle replayed quotes with
+// their timestamp as a time.Time
+func (rp *Replayer) SetQuoteHandler(handler func(QuoteMessage, time.Time)) {
+	rp.QuoteHandler = handler
+}
+
+// SetTimestampFormat sets the layout and time zone of the timestamp passed to
+// the message handler, see WebSocketClient.SetTimestampFormat
+func (rp *Replayer) SetTimestampFormat(layout string, loc *time.Location) {
+	rp.TimestampLayout = layout
+	rp.TimestampLocation = loc
+}
+
+// SetConnectedHandler sets the callback function to handle the "Connected" message
+func (rp *Replayer) SetConnectedHandler(handler func(ConnectedMessage)) {
+	rp.ConnectedHandler = handler
+}
+
+// SetSpeed sets the playback speed: 1 is real time, 10 is ten times faster,
+// and 0 replays as fast as possible. It can be changed during playback.
+func (rp *Replayer) SetSpeed(speed float64) {
+	rp.mu.Lock()
+	rp.speed = speed
+	rp.mu.Unlock()
+}
+
+// Seek moves playback to the first frame received at or after t. Frames
+// skipped over are not delivered.
+func (rp *Replayer) Seek(t time.Time) error {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	if t.Before(rp.current) {
+		if _, ok := rp.src.(io.Seeker); !ok {
+			return fmt.Errorf("cannot seek backwards: recording source is not seekable")
+		}
+	}
+	rp.seekTo = t
+	return nil
+}
+
+// Position returns the receive time of the last delivered frame
+func (rp *Replayer) Position() time.Time {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	return rp.current
+}
+
+// Subscribe restricts playback to the given symbols. Without a subscription
+// every recorded symbol is replayed.
+func (rp *Replayer) Subscribe(symbols ...string) error {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	for _, s := range symbols {
+		rp.symbols[s] = true
+	}
+	return nil
+}
+
+// Quotes returns a channel receiving every replayed quote, closed when
+// playback ends. At real-time speeds quotes are dropped when the channel's
+// buffer is full, as with the live client; at speed 0 playback waits for the
+// reader instead.
+func (rp *Replayer) Quotes() <-chan QuoteMessage {
+	return rp.quotes
+}
+
+// Connect starts playback in the background
+func (rp *Replayer) Connect() error {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	if rp.started {
+		return fmt.Errorf("replay already started")
+	}
+	rp.started = true
+	go rp.run()
+	return nil
+}
+
+// Done is closed when playback reaches the end of the recording or is closed
+func (rp *Replayer) Done() <-chan struct{} {
+	return rp.done
+}
+
+// Err returns the error that ended playback, or nil at the end of the recording
+func (rp *Replayer) Err() error {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	return rp.err
+}
+
+// Close stops playback
+func (rp *Replayer) Close() error {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	select {
+	case <-rp.stop:
+	default:
+		close(rp.stop)
+	}
+	if !rp.started {
+		rp.started = true
+		close(rp.quotes)
+		close(rp.done)
+	}
+	return nil
+}
+
+func (rp *Replayer) run() {
+	defer close(rp.done)
+	defer close(rp.quotes)
+
+	var prevFrame, prevWall time.Time
+	for {
+		if err := rp.applySeek(); err != nil {
+			rp.finish(err)
+			return
+		}
+
+		t, payload, err := rp.frames.Next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			rp.finish(err)
+			return
+		}
+
+		rp.mu.Lock()
+		seeking := !rp.seekTo.IsZero() && t.Before(rp.seekTo)
+		if !seeking {
+			rp.seekTo = time.Time{}
+		}
+		speed := rp.speed
+		rp.mu.Unlock()
+		if seeking {
+			prevFrame = time.Time{}
+			continue
+		}
+
+		// Wait for the original gap, scaled by speed, measured from the previous frame's delivery
+		if speed > 0 && !prevFrame.IsZero() {
+			gap := time.Duration(float64(t.Sub(prevFrame)) / speed)
+			if wait := gap - time.Since(prevWall); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+				case <-rp.stop:
+					timer.Stop()
+					return
+				}
+			}
+		}
+		prevFrame, prevWall = t, time.Now()
+
+		rp.mu.Lock()
+		rp.current = t
+		rp.mu.Unlock()
+		if !rp.deliver(payload, speed <= 0) {
+			return
+		}
+
+		select {
+		case <-rp.stop:
+			return
+		default:
+		}
+	}
+}
+
+// applySeek rewinds the recording when a backwards seek is pending
+func (rp *Replayer) applySeek() error {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	if rp.seekTo.IsZero() || !rp.seekTo.Before(rp.current) {
+		return nil
+	}
+	seeker := rp.src.(io.Seeker) // Checked by Seek
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind recording: %w", err)
+	}
+	rp.frames = NewFrameReader(rp.src)
+	rp.current = time.Time{}
+	return nil
+}
+
+// deliver passes one frame to the handlers and the quotes channel, waiting
+// for room in the channel if block is set. It returns false if playback was
+// stopped while waiting.
+func (rp *Replayer) deliver(payload []byte, block bool) bool {
+	msg, err := parseMessage(payload, nil)
+	if err != nil {
+		return true // Recorded as received; malformed frames are skipped just like the live client
+	}
+
+	switch msg.kind {
+	case kindConnected:
+		if rp.ConnectedHandler != nil {
+			rp.ConnectedHandler(msg.connected)
+		}
+	case kindQuote:
+		rp.mu.Lock()
+		wanted := len(rp.symbols) == 0 || rp.symbols[msg.quote.Symbol]
+		rp.mu.Unlock()
+		if !wanted {
+			return true
+		}
+		if rp.MessageHandler != nil {
+			rp.MessageHandler(msg.quote, formatTimestamp(msg.ts, rp.TimestampLayout, rp.TimestampLocation))
+		}
+		if rp.QuoteHandler != nil {
+			rp.QuoteHandler(msg.quote, msg.ts)
+		}
+		if block {
+			select {
+			case rp.quotes <- msg.quote:
+			case <-rp.stop:
+				return false
+			}
+			return true
+		}
+		select {
+		case rp.quotes <- msg.quote:
+		default:
+		}
+	}
+	return true
+}
+
+func (rp *Replayer) finish(err error) {
+	rp.mu.Lock()
+	rp.err = err
+	rp.mu.Unlock()
+}
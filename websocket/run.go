@@ -0,0 +1,62 @@
+package tradermadews
+
+import (
+	"context"
+	"fmt"
+)
+
+// Run connects and streams until ctx is cancelled, Disconnect is called, or
+// the connection is lost for good (reconnection disabled or retries
+// exhausted). It reconnects automatically in between and returns the
+// terminal error: ctx.Err() on cancellation, nil after Disconnect.
+//
+//	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+//	defer stop()
+//	if err := client.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+//		log.Fatal(err)
+//	}
+func (client *WebSocketClient) Run(ctx context.Context) error {
+	terminal := make(chan error, 1)
+	client.ConnMutex.Lock()
+	if client.terminal != nil {
+		client.ConnMutex.Unlock()
+		return fmt.Errorf("Run is already active on this client")
+	}
+	client.terminal = terminal
+	client.ConnMutex.Unlock()
+
+	defer func() {
+		client.ConnMutex.Lock()
+		client.terminal = nil
+		client.ConnMutex.Unlock()
+	}()
+
+	if err := client.Connect(); err != nil {
+		if !client.AutoReconnect {
+			return err
+		}
+		go client.reconnect()
+	}
+
+	select {
+	case <-ctx.Done():
+		client.Disconnect()
+		return ctx.Err()
+	case err := <-terminal:
+		return err
+	}
+}
+
+// terminate ends an active Run with err
+func (client *WebSocketClient) terminate(err error) {
+	client.ConnMutex.Lock()
+	terminal := client.terminal
+	client.ConnMutex.Unlock()
+
+	if terminal != nil {
+		select {
+		case terminal <- err:
+		default:
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package tradermadews
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ShardedClient spreads a large subscription across several upstream
+// connections and presents them as one client. Every shard is a
+// WebSocketClient with its own reconnect handling; handlers set on the
+// ShardedClient receive the events of all shards.
+type ShardedClient struct {
+	MessageHandler      func(QuoteMessage, string) // Handles market data from every shard
+	ConnectedHandler    func(ConnectedMessage)     // Handles each shard's "Connected" message
+	ReconnectionHandler func(int)                  // Handles reconnection attempts of any shard
+	ErrorHandler        func(error)                // Handles errors of any shard
+	DisconnectedHandler func(error)                // Handles connection drops of any shard
+
+	SymbolsPerConnection int // Maximum symbols on one connection (default 50)
+
+	apiKey    string
+	mu        sync.Mutex
+	shards    []*WebSocketClient
+	configure []func(*WebSocketClient)
+	pending   string // Symbols subscribed before Connect, sharded on Connect
+	connected bool
+	quotes    chan QuoteMessage
+	deliverMu sync.Mutex // Serializes handler calls across shards
+}
+
+// NewShardedClient creates a client for the comma separated symbol list,
+// opening one connection per SymbolsPerConnection symbols on Connect
+func NewShardedClient(apiKey, symbol string) *ShardedClient {
+	s := &ShardedClient{
+		SymbolsPerConnection: 50,
+		apiKey:               apiKey,
+		quotes:               make(chan QuoteMessage, 256),
+	}
+	s.pending = mergeSymbols("", splitSymbols(symbol))
+	return s
+}
+
+// SetMessageHandler sets the callback function to handle quotes from every shard
+func (s *ShardedClient) SetMessageHandler(handler func(QuoteMessage, string)) {
+	s.MessageHandler = handler
+}
+
+// SetConnectedHandler sets the callback function to handle each shard's "Connected" message
+func (s *ShardedClient) SetConnectedHandler(handler func(ConnectedMessage)) {
+	s.ConnectedHandler = handler
+}
+
+// SetReconnectionHandler sets the callback function to handle reconnection attempts of any shard
+func (s *ShardedClient) SetReconnectionHandler(handler func(int)) {
+	s.ReconnectionHandler = handler
+}
+
+// SetErrorHandler sets the callback function to handle errors of any shard
+func (s *ShardedClient) SetErrorHandler(handler func(error)) {
+	s.ErrorHandler = handler
+}
+
+// SetDisconnectedHandler sets the callback function to handle connection drops of any shard
+func (s *ShardedClient) SetDisconnectedHandler(handler func(error)) {
+	s.DisconnectedHandler = handler
+}
+
+// Configure applies fn to every shard, including shards opened later, to set
+// options such as MaxRetries or a custom Dialer
+func (s *ShardedClient) Configure(fn func(*WebSocketClient)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.configure = append(s.configure, fn)
+	for _, shard := range s.shards {
+		fn(shard)
+	}
+}
+
+// Shards returns the underlying clients, e.g. for their Stats or state
+func (s *ShardedClient) Shards() []*WebSocketClient {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*WebSocketClient(nil), s.shards...)
+}
+
+// Symbols returns the subscription across all shards
+func (s *ShardedClient) Symbols() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	symbols := splitSymbols(s.pending)
+	for _, shard := range s.shards {
+		symbols = append(symbols, shard.Symbols()...)
+	}
+	return symbols
+}
+
+// Quotes returns a channel receiving quotes from every shard. Quotes are
+// dropped when the channel's buffer is full.
+func (s *ShardedClient) Quotes() <-chan QuoteMessage {
+	return s.quotes
+}
+
+// Subscribe adds symbols to the shards with the most room, opening new
+// connections once every shard is full. Before Connect the symbols are only
+// recorded.
+func (s *ShardedClient) Subscribe(symbols ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.connected {
+		s.pending = mergeSymbols(s.pending, symbols)
+		return nil
+	}
+	return s.assign(symbols)
+}
+
+// assign places symbols on the shards with the most room, opening new shards
+// once every shard is full. Each affected shard is updated once. Must be
+// called with mu held.
+func (s *ShardedClient) assign(symbols []string) error {
+	counts := make(map[*WebSocketClient]int, len(s.shards))
+	existing := make(map[string]bool)
+	for _, shard := range s.shards {
+		current := shard.Symbols()
+		counts[shard] = len(current)
+		for _, symbol := range current {
+			existing[symbol] = true
+		}
+	}
+
+	added := make(map[*WebSocketClient][]string)
+	var created []*WebSocketClient
+	for _, symbol := range splitSymbols(mergeSymbols("", symbols)) {
+		if existing[symbol] {
+			continue
+		}
+		existing[symbol] = true
+
+		shard := s.shardWithRoom(counts)
+		if shard == nil {
+			shard = s.newShard()
+			created = append(created, shard)
+		}
+		counts[shard]++
+		added[shard] = append(added[shard], symbol)
+	}
+
+	var errs []error
+	for _, shard := range s.shards {
+		if symbols, ok := added[shard]; ok {
+			errs = append(errs, shard.Subscribe(symbols...))
+		}
+	}
+	for _, shard := range created {
+		errs = append(errs, shard.Connect())
+	}
+	return errors.Join(errs...)
+}
+
+// Unsubscribe removes symbols from whichever shards carry them
+func (s *ShardedClient) Unsubscribe(symbols ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending = removeSymbols(s.pending, symbols)
+	remove := make(map[string]bool)
+	for _, symbol := range splitSymbols(mergeSymbols("", symbols)) {
+		remove[symbol] = true
+	}
+
+	var errs []error
+	for _, shard := range s.shards {
+		var drop []string
+		for _, symbol := range shard.Symbols() {
+			if remove[symbol] {
+				drop = append(drop, symbol)
+			}
+		}
+		if len(drop) > 0 {
+			errs = append(errs, shard.Unsubscribe(drop...))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Connect shards the subscription and opens every shard's connection
+func (s *ShardedClient) Connect() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.connected = true
+	var errs []error
+	for _, shard := range s.shards {
+		errs = append(errs, shard.Connect())
+	}
+	pending := splitSymbols(s.pending)
+	s.pending = ""
+	errs = append(errs, s.assign(pending))
+	return errors.Join(errs...)
+}
+
+// Disconnect closes every shard's connection and stops reconnection attempts
+func (s *ShardedClient) Disconnect() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.connected = false
+	var errs []error
+	for _, shard := range s.shards {
+		errs = append(errs, shard.Disconnect())
+	}
+	return errors.Join(errs...)
+}
+
+// Close disconnects every shard, see Disconnect
+func (s *ShardedClient) Close() error {
+	return s.Disconnect()
+}
+
+// shardWithRoom returns the shard with the fewest symbols if it has room, or nil
+func (s *ShardedClient) shardWithRoom(counts map[*WebSocketClient]int) *WebSocketClient {
+	limit := s.SymbolsPerConnection
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var best *WebSocketClient
+	bestCount := limit
+	for _, shard := range s.shards {
+		if n := counts[shard]; n < bestCount {
+			best, bestCount = shard, n
+		}
+	}
+	return best
+}
+
+// newShard creates a client forwarding to the sharded client's handlers.
+// Must be called with mu held.
+func (s *ShardedClient) newShard() *WebSocketClient {
+	shard := NewWebSocketClient(s.apiKey, "")
+	shard.SetMessageHandler(s.handleQuote)
+	shard.SetConnectedHandler(func(msg ConnectedMessage) {
+		if s.ConnectedHandler != nil {
+			s.ConnectedHandler(msg)
+		}
+	})
+	shard.SetReconnectionHandler(func(attempt int) {
+		if s.ReconnectionHandler != nil {
+			s.ReconnectionHandler(attempt)
+		}
+	})
+	shard.SetErrorHandler(func(err error) {
+		if s.ErrorHandler != nil {
+			s.ErrorHandler(err)
+		} else {
+			fmt.Printf("%v\n", err) // As a plain client without a handler
+		}
+	})
+	shard.SetDisconnectedHandler(func(err error) {
+		if s.DisconnectedHandler != nil {
+			s.DisconnectedHandler(err)
+		}
+	})
+	for _, fn := range s.configure {
+		fn(shard)
+	}
+	s.shards = append(s.shards, shard)
+	return shard
+}
+
+// handleQuote delivers a quote from any shard
+func (s *ShardedClient) handleQuote(quote QuoteMessage, timestamp string) {
+	s.deliverMu.Lock()
+	defer s.deliverMu.Unlock()
+
+	if s.MessageHandler != nil {
+		s.MessageHandler(quote, timestamp)
+	}
+	select {
+	case s.quotes <- quote:
+	default:
+	}
+}
@@ -0,0 +1,61 @@
+package tradermadews
+
+import (
+	"context"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Shutdown closes the connection gracefully: it sends a close frame, stops
+// delivering newly received messages, waits until the server acknowledges
+// the close and every queued message has been handed to the handlers, then
+// closes the connection and stops reconnection attempts. Quotes held back by
+// conflation are delivered before it returns. If ctx ends first the
+// connection is closed immediately and ctx's error is returned.
+func (client *WebSocketClient) Shutdown(ctx context.Context) error {
+	client.ConnMutex.Lock()
+	if client.stopped {
+		client.ConnMutex.Unlock()
+		return nil
+	}
+	conn := client.Conn
+	finished := client.finished
+	client.closing.Store(true)
+	client.ConnMutex.Unlock()
+	defer client.closing.Store(false)
+
+	var err error
+	if conn != nil {
+		closeFrame := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+		if werr := conn.WriteControl(websocket.CloseMessage, closeFrame, time.Now().Add(writeWait)); werr == nil {
+			select {
+			case <-finished:
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+		}
+	}
+	client.flushPendingConflated()
+
+	client.ConnMutex.Lock()
+	client.shutdown() // Stop reconnect attempts
+	conn = client.Conn
+	client.Conn = nil
+	client.ConnMutex.Unlock()
+
+	client.terminate(nil)
+	if conn != nil {
+		if cerr := conn.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// isStopped reports whether the client has shut down
+func (client *WebSocketClient) isStopped() bool {
+	client.ConnMutex.Lock()
+	defer client.ConnMutex.Unlock()
+	return client.stopped
+}
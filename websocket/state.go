@@ -0,0 +1,79 @@
+package tradermadews
+
+import (
+	"sync"
+	"time"
+)
+
+// State is the connection state of the client
+type State int
+
+const (
+	StateDisconnected State = iota // Not connected and not trying to
+	StateConnecting                // Dialing the server
+	StateConnected                 // Connected and subscribed
+	StateReconnecting              // Connection lost, retrying
+	StateClosed                    // Shut down by Disconnect, a rejected key or exhausted retries
+)
+
+// String returns the state name
+func (s State) String() string {
+	switch s {
+	case StateDisconnected:
+		return "disconnected"
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateClosed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
+// StateChange describes a transition between two states
+type StateChange struct {
+	From State
+	To   State
+	Time time.Time
+}
+
+// connState holds the current state and the channel behind StateChanges()
+type connState struct {
+	mu      sync.Mutex
+	state   State
+	changes chan StateChange
+}
+
+// GetState returns the current connection state
+func (client *WebSocketClient) GetState() State {
+	client.connState.mu.Lock()
+	defer client.connState.mu.Unlock()
+	return client.connState.state
+}
+
+// StateChanges returns a channel receiving every state transition. Changes
+// are dropped when the channel's buffer is full.
+func (client *WebSocketClient) StateChanges() <-chan StateChange {
+	return client.connState.changes
+}
+
+// setState moves to state s and publishes the transition
+func (client *WebSocketClient) setState(s State) {
+	cs := &client.connState
+	cs.mu.Lock()
+	from := cs.state
+	cs.state = s
+	cs.mu.Unlock()
+
+	if from == s {
+		return
+	}
+	select {
+	case cs.changes <- StateChange{From: from, To: s, Time: time.Now()}:
+	default:
+	}
+}
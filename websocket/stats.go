@@ -0,0 +1,203 @@
+package tradermadews
+
+import (
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Stats is a snapshot of the client's counters
+type Stats struct {
+	MessagesReceived  uint64            // Frames read from the socket
+	BytesReceived     uint64            // Payload bytes read from the socket
+	QuotesReceived    map[string]uint64 // Quotes per symbol
+	LastMessage       time.Time         // Receive time of the latest frame
+	ReconnectAttempts uint64            // Reconnection attempts since the client was created
+	Dropped           uint64            // Messages dropped by buffer overflows
+	Duplicates        uint64            // Duplicate quotes dropped, see KeepDuplicates
+	Latency           Latency           // Feed latency over the most recent quotes
+}
+
+// Latency summarizes the delay between a quote's Ts and its local receive
+// time. The percentiles cover the most recent quotes; Count and Sum cover
+// every quote since the client was created. Negative values mean the local
+// clock is behind the server's.
+type Latency struct {
+	Samples int           // Quotes in the window, up to latencyWindow
+	Count   uint64        // Quotes measured since the client was created
+	Sum     time.Duration // Total latency of those quotes
+	P50     time.Duration
+	P90     time.Duration
+	P99     time.Duration
+	Max     time.Duration
+}
+
+// latencyWindow is the number of recent quotes the latency percentiles cover
+const latencyWindow = 1024
+
+// stats holds the live counters behind Stats()
+type stats struct {
+	mu                sync.Mutex
+	messages          uint64
+	bytes             uint64
+	quotes            map[string]uint64
+	lastMessage       time.Time
+	reconnectAttempts uint64
+	latency           []time.Duration // Ring buffer of the latest latencyWindow samples
+	latencyNext       int
+	latencyCount      uint64
+	latencySum        time.Duration
+}
+
+func (s *stats) frame(t time.Time, size int) {
+	s.mu.Lock()
+	s.messages++
+	s.bytes += uint64(size)
+	s.lastMessage = t
+	s.mu.Unlock()
+}
+
+func (s *stats) quote(symbol string, latency time.Duration) {
+	s.mu.Lock()
+	if s.quotes == nil {
+		s.quotes = make(map[string]uint64)
+	}
+	s.quotes[symbol]++
+	s.latencyCount++
+	s.latencySum += latency
+	if len(s.latency) < latencyWindow {
+		s.latency = append(s.latency, latency)
+	} else {
+		s.latency[s.latencyNext] = latency
+		s.latencyNext = (s.latencyNext + 1) % latencyWindow
+	}
+	s.mu.Unlock()
+}
+
+// latencySummary computes percentiles over the window. Must be called with mu held.
+func (s *stats) latencySummary() Latency {
+	if len(s.latency) == 0 {
+		return Latency{Count: s.latencyCount, Sum: s.latencySum}
+	}
+	sorted := append([]time.Duration(nil), s.latency...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	at := func(p float64) time.Duration {
+		return sorted[int(p*float64(len(sorted)-1))]
+	}
+	return Latency{
+		Samples: len(sorted),
+		Count:   s.latencyCount,
+		Sum:     s.latencySum,
+		P50:     at(0.50),
+		P90:     at(0.90),
+		P99:     at(0.99),
+		Max:     sorted[len(sorted)-1],
+	}
+}
+
+func (s *stats) reconnect() {
+	s.mu.Lock()
+	s.reconnectAttempts++
+	s.mu.Unlock()
+}
+
+// Stats returns a snapshot of the client's counters
+func (client *WebSocketClient) Stats() Stats {
+	s := &client.stats
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	quotes := make(map[string]uint64, len(s.quotes))
+	for symbol, n := range s.quotes {
+		quotes[symbol] = n
+	}
+	return Stats{
+		MessagesReceived:  s.messages,
+		BytesReceived:     s.bytes,
+		QuotesReceived:    quotes,
+		LastMessage:       s.lastMessage,
+		ReconnectAttempts: s.reconnectAttempts,
+		Dropped:           client.Dropped(),
+		Duplicates:        client.dedupe.count(),
+		Latency:           s.latencySummary(),
+	}
+}
+
+// PublishExpvar publishes the client's stats under name on /debug/vars. Like
+// expvar.Publish, it panics if name is already in use.
+func (client *WebSocketClient) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return client.Stats()
+	}))
+}
+
+// WritePrometheus writes the client's stats in the Prometheus text format
+func (client *WebSocketClient) WritePrometheus(w io.Writer) error {
+	st := client.Stats()
+
+	symbols := make([]string, 0, len(st.QuotesReceived))
+	for symbol := range st.QuotesReceived {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	var lastMessage float64
+	if !st.LastMessage.IsZero() {
+		lastMessage = float64(st.LastMessage.UnixNano()) / 1e9
+	}
+
+	metrics := []struct {
+		name, help, kind string
+		value            float64
+	}{
+		{"tradermade_ws_messages_received_total", "Frames read from the socket.", "counter", float64(st.MessagesReceived)},
+		{"tradermade_ws_bytes_received_total", "Payload bytes read from the socket.", "counter", float64(st.BytesReceived)},
+		{"tradermade_ws_reconnect_attempts_total", "Reconnection attempts.", "counter", float64(st.ReconnectAttempts)},
+		{"tradermade_ws_dropped_messages_total", "Messages dropped by buffer overflows.", "counter", float64(st.Dropped)},
+		{"tradermade_ws_duplicate_quotes_total", "Duplicate quotes dropped.", "counter", float64(st.Duplicates)},
+		{"tradermade_ws_last_message_timestamp_seconds", "Receive time of the latest frame.", "gauge", lastMessage},
+	}
+	for _, m := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", m.name, m.help, m.name, m.kind, m.name, m.value); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprint(w, "# HELP tradermade_ws_latency_seconds Delay between quote timestamps and receive time, quantiles over the latest quotes.\n# TYPE tradermade_ws_latency_seconds summary\n"); err != nil {
+		return err
+	}
+	for _, q := range []struct {
+		quantile string
+		value    time.Duration
+	}{{"0.5", st.Latency.P50}, {"0.9", st.Latency.P90}, {"0.99", st.Latency.P99}} {
+		if _, err := fmt.Fprintf(w, "tradermade_ws_latency_seconds{quantile=%q} %g\n", q.quantile, q.value.Seconds()); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "tradermade_ws_latency_seconds_sum %g\ntradermade_ws_latency_seconds_count %d\n", st.Latency.Sum.Seconds(), st.Latency.Count); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprint(w, "# HELP tradermade_ws_quotes_received_total Quotes received per symbol.\n# TYPE tradermade_ws_quotes_received_total counter\n"); err != nil {
+		return err
+	}
+	for _, symbol := range symbols {
+		if _, err := fmt.Fprintf(w, "tradermade_ws_quotes_received_total{symbol=%q} %d\n", symbol, st.QuotesReceived[symbol]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MetricsHandler returns an HTTP handler serving the client's stats in the
+// Prometheus text format, for scraping without the Prometheus client library
+func (client *WebSocketClient) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		client.WritePrometheus(w)
+	})
+}
@@ -0,0 +1,180 @@
+package tradermadews
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/tradermade/Go-SDK/symbols"
+)
+
+// syntheticPair is a cross derived from two legs against a common currency
+type syntheticPair struct {
+	baseLeg       string // Prices the cross's base in the common currency, e.g. EURUSD
+	quoteLeg      string // Prices the cross's quote currency in the common currency, e.g. GBPUSD
+	baseInverted  bool   // Whether baseLeg is quoted the other way round, e.g. USDJPY
+	quoteInverted bool
+}
+
+// synthetics holds the crosses derived by the client
+type synthetics struct {
+	mu    sync.RWMutex
+	pairs map[string]syntheticPair
+	added map[string]bool // Legs subscribed by AddSynthetic rather than the user
+}
+
+// AddSynthetic derives quotes for cross from its two legs through via, e.g.
+// EURGBP from EURUSD and GBPUSD when via is USD (the default when empty).
+// The legs are added to the subscription if needed. Every tick of either leg
+// delivers a new cross quote with Synthetic set, once both legs have a price.
+func (client *WebSocketClient) AddSynthetic(cross, via string) error {
+	cross, via = strings.ToUpper(strings.TrimSpace(cross)), strings.ToUpper(strings.TrimSpace(via))
+	if via == "" {
+		via = "USD"
+	}
+	if len(cross) != 6 {
+		return fmt.Errorf("synthetic pair %q is not a six letter currency pair", cross)
+	}
+	base, quote := cross[:3], cross[3:]
+	if base == via || quote == via {
+		return fmt.Errorf("synthetic pair %s already contains %s", cross, via)
+	}
+
+	var pair syntheticPair
+	pair.baseLeg, pair.baseInverted = symbols.Pair(base, via)
+	pair.quoteLeg, pair.quoteInverted = symbols.Pair(quote, via)
+
+	subscribed := make(map[string]bool)
+	for _, s := range client.Symbols() {
+		subscribed[s] = true
+	}
+	if subscribed[cross] {
+		return fmt.Errorf("synthetic pair %s is already subscribed directly", cross)
+	}
+
+	s := &client.synthetics
+	s.mu.Lock()
+	if s.pairs == nil {
+		s.pairs = make(map[string]syntheticPair)
+		s.added = make(map[string]bool)
+	}
+	var legs []string
+	for _, leg := range []string{pair.baseLeg, pair.quoteLeg} {
+		if !subscribed[leg] {
+			legs = append(legs, leg)
+			s.added[leg] = true
+		}
+	}
+	s.pairs[cross] = pair
+	s.mu.Unlock()
+
+	if len(legs) == 0 {
+		return nil
+	}
+	return client.Subscribe(legs...)
+}
+
+// RemoveSynthetic stops deriving quotes for cross. Legs added by AddSynthetic
+// are unsubscribed once no other synthetic pair uses them.
+func (client *WebSocketClient) RemoveSynthetic(cross string) error {
+	cross = strings.ToUpper(strings.TrimSpace(cross))
+
+	s := &client.synthetics
+	s.mu.Lock()
+	pair, ok := s.pairs[cross]
+	if !ok {
+		s.mu.Unlock()
+		return nil
+	}
+	delete(s.pairs, cross)
+	inUse := make(map[string]bool)
+	for _, p := range s.pairs {
+		inUse[p.baseLeg], inUse[p.quoteLeg] = true, true
+	}
+	var legs []string
+	for _, leg := range []string{pair.baseLeg, pair.quoteLeg} {
+		if s.added[leg] && !inUse[leg] {
+			legs = append(legs, leg)
+			delete(s.added, leg)
+		}
+	}
+	s.mu.Unlock()
+
+	if len(legs) == 0 {
+		return nil
+	}
+	return client.Unsubscribe(legs...)
+}
+
+// SyntheticPairs returns the crosses derived by the client
+func (client *WebSocketClient) SyntheticPairs() []string {
+	s := &client.synthetics
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := make([]string, 0, len(s.pairs))
+	for cross := range s.pairs {
+		list = append(list, cross)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// deriveSynthetic returns a quote for every synthetic pair using leg, priced
+// from the latest quotes of both legs
+func (client *WebSocketClient) deriveSynthetic(leg parsedMessage) []parsedMessage {
+	s := &client.synthetics
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.pairs) == 0 || leg.quote.Synthetic {
+		return nil
+	}
+
+	var derived []parsedMessage
+	for cross, pair := range s.pairs {
+		if leg.quote.Symbol != pair.baseLeg && leg.quote.Symbol != pair.quoteLeg {
+			continue
+		}
+		base, ok1 := client.GetLastQuote(pair.baseLeg)
+		quote, ok2 := client.GetLastQuote(pair.quoteLeg)
+		if !ok1 || !ok2 {
+			continue // Waiting for the other leg
+		}
+
+		bBid, bAsk, bMid := legPrice(base, pair.baseInverted)
+		qBid, qAsk, qMid := legPrice(quote, pair.quoteInverted)
+		if bMid <= 0 || qMid <= 0 || qBid <= 0 || qAsk <= 0 {
+			continue
+		}
+		msg := leg
+		msg.quote = QuoteMessage{
+			Symbol:    cross,
+			Bid:       bBid / qAsk, // Selling the base for the common currency and buying the quote currency
+			Ask:       bAsk / qBid,
+			Mid:       bMid / qMid,
+			Ts:        leg.quote.Ts,
+			Time:      leg.quote.Time,
+			Latency:   leg.quote.Latency,
+			Synthetic: true,
+		}
+		client.lastQuotes.set(msg.quote)
+		derived = append(derived, msg)
+	}
+	return derived
+}
+
+// legPrice returns a leg's bid, ask and mid as the price of the cross
+// currency in the common currency, inverting reversed pairs
+func legPrice(q QuoteMessage, inverted bool) (bid, ask, mid float64) {
+	bid, ask, mid = q.Bid, q.Ask, q.Mid
+	if mid <= 0 && bid > 0 && ask > 0 {
+		mid = (bid + ask) / 2
+	}
+	if bid <= 0 || ask <= 0 {
+		bid, ask = mid, mid
+	}
+	if inverted && bid > 0 && ask > 0 && mid > 0 {
+		bid, ask, mid = 1/ask, 1/bid, 1/mid
+	}
+	return bid, ask, mid
+}
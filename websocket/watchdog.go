@@ -0,0 +1,125 @@
+package tradermadews
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tradermade/Go-SDK/clock"
+	"github.com/tradermade/Go-SDK/markethours"
+)
+
+// watchdog tracks when each symbol last quoted, see SetStaleHandler
+type watchdog struct {
+	mu       sync.Mutex
+	lastSeen map[string]time.Time
+	stale    map[string]bool // Symbols already reported, cleared by the next quote
+}
+
+// SetStaleHandler calls handler when a subscribed symbol has not quoted for
+// after while the market is open and delivery is not paused. It fires once
+// per silence and again only after the symbol has quoted. lastQuote is zero
+// if the symbol never quoted. Zero disables the watchdog.
+func (client *WebSocketClient) SetStaleHandler(after time.Duration, handler func(symbol string, lastQuote time.Time)) {
+	client.StaleAfter = after
+	client.StaleHandler = handler
+}
+
+// SetMarketHours sets the function deciding whether the market is open. The
+// staleness watchdog is paused while it returns false. Nil means always open.
+func (client *WebSocketClient) SetMarketHours(open func(time.Time) bool) {
+	client.MarketOpen = open
+}
+
+// ForexMarketOpen reports whether the spot FX market is open at t, see
+// markethours.IsOpen
+func ForexMarketOpen(t time.Time) bool {
+	return markethours.IsOpen(t)
+}
+
+// seen records a quote for symbol
+func (w *watchdog) seen(symbol string, t time.Time) {
+	w.mu.Lock()
+	if w.lastSeen == nil {
+		w.lastSeen = make(map[string]time.Time)
+		w.stale = make(map[string]bool)
+	}
+	w.lastSeen[symbol] = t
+	delete(w.stale, symbol)
+	w.mu.Unlock()
+}
+
+// watchStale checks subscribed symbols for silence until stop is closed
+func (client *WebSocketClient) watchStale(stop <-chan struct{}) {
+	after := client.StaleAfter
+	if after <= 0 {
+		return
+	}
+	tick := after / 4
+	if tick < time.Millisecond {
+		tick = time.Millisecond
+	}
+	clk := clock.OrDefault(client.Clock)
+	ticker := clk.NewTicker(tick)
+	defer ticker.Stop()
+
+	// Silence is measured from the later of the last quote and the time the
+	// market (or this connection) opened, so reconnects and weekends don't
+	// report every symbol at once
+	openSince := clk.Now()
+	for {
+		select {
+		case now := <-ticker.C():
+			if client.paused.Load() || (client.MarketOpen != nil && !client.MarketOpen(now)) {
+				openSince = time.Time{}
+				continue
+			}
+			if openSince.IsZero() {
+				openSince = now
+			}
+			for _, symbol := range client.staleSymbols(now, openSince, after) {
+				client.reportStale(symbol)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// staleSymbols returns subscribed symbols silent for longer than after and
+// marks them as reported
+func (client *WebSocketClient) staleSymbols(now, openSince time.Time, after time.Duration) []string {
+	symbols := client.Symbols()
+
+	w := &client.watchdog
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stale == nil {
+		w.lastSeen = make(map[string]time.Time)
+		w.stale = make(map[string]bool)
+	}
+
+	var stale []string
+	for _, symbol := range symbols {
+		since := w.lastSeen[symbol]
+		if since.Before(openSince) {
+			since = openSince
+		}
+		if !w.stale[symbol] && now.Sub(since) >= after {
+			w.stale[symbol] = true
+			stale = append(stale, symbol)
+		}
+	}
+	return stale
+}
+
+// reportStale notifies the stale handler and the Events channel
+func (client *WebSocketClient) reportStale(symbol string) {
+	client.watchdog.mu.Lock()
+	lastQuote := client.watchdog.lastSeen[symbol]
+	client.watchdog.mu.Unlock()
+
+	if client.StaleHandler != nil {
+		client.callHandler("stale handler", func() { client.StaleHandler(symbol, lastQuote) })
+	}
+	client.emitEvent(ConnectionEvent{Type: EventSymbolStale, Symbol: symbol})
+}
@@ -0,0 +1,21 @@
+package tradermadews
+
+import (
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// writeWait limits how long a single outgoing message may block
+const writeWait = 10 * time.Second
+
+// writeMessage sends a data message on conn. The connection supports only one
+// concurrent writer, so every data write goes through here. Control frames
+// (pings) use WriteControl, which is safe to call concurrently.
+func (client *WebSocketClient) writeMessage(conn *websocket.Conn, messageType int, data []byte) error {
+	client.writeMu.Lock()
+	defer client.writeMu.Unlock()
+
+	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return conn.WriteMessage(messageType, data)
+}
@@ -1,17 +1,23 @@
 package tradermadews
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/tradermade/Go-SDK/clock"
+	"github.com/tradermade/Go-SDK/codec"
 )
 
-const wsURL = "wss://marketdata.tradermade.com/feedadv"
+// DefaultURL is the TraderMade streaming feed
+const DefaultURL = "wss://marketdata.tradermade.com/feedadv"
 
 // QuoteMessage represents a quote from the WebSocket feed
 type QuoteMessage struct {
@@ -20,6 +26,21 @@ type QuoteMessage struct {
 	Ask    float64 `json:"ask"`
 	Mid    float64 `json:"mid"`
 	Ts     string  `json:"ts"` // Timestamp as a string (from API response)
+
+	Time    time.Time     `json:"-"` // Ts parsed, set by the client
+	Latency time.Duration `json:"-"` // Receive time minus Ts, set by the client; negative values indicate clock skew
+	Outlier bool          `json:"-"` // Set when the outlier filter flags the quote, see SetOutlierFilter
+
+	Synthetic bool `json:"-"` // Derived by the client from two legs, see AddSynthetic
+}
+
+// Timestamp converts the millisecond epoch in Ts to a time.Time
+func (q QuoteMessage) Timestamp() (time.Time, error) {
+	tsInt, err := strconv.ParseInt(q.Ts, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(tsInt), nil
 }
 
 // ConnectedMessage represents the connection status message
@@ -28,44 +49,249 @@ type ConnectedMessage struct {
 	Message string `json:"message"`
 }
 
+// DefaultTimestampLayout is the layout of the message handler's timestamp
+const DefaultTimestampLayout = "2006-01-02 15:04:05.000"
+
+// FormatTimestamp formats t for a message handler the way the client does:
+// with layout (default DefaultTimestampLayout) in loc (default UTC). Other
+// quote sources use it so handlers receive the same format from each.
+func FormatTimestamp(t time.Time, layout string, loc *time.Location) string {
+	return formatTimestamp(t, layout, loc)
+}
+
+// formatTimestamp formats t for the message handler, defaulting to
+// DefaultTimestampLayout in UTC
+func formatTimestamp(t time.Time, layout string, loc *time.Location) string {
+	if layout == "" {
+		layout = DefaultTimestampLayout
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format(layout)
+}
+
+// ErrAuthFailed is reported when the server rejects the API key. The client
+// does not reconnect after it, and Run returns it wrapped with the server's message.
+var ErrAuthFailed = errors.New("WebSocket authentication failed")
+
+// ErrQuotesDropped is reported when the Quotes channel is full and quotes are
+// dropped, once each time it fills up. Nothing is reported until Quotes has
+// been called.
+var ErrQuotesDropped = errors.New("quotes channel full, dropping quotes")
+
 // WebSocketClient manages the WebSocket connection and state
 type WebSocketClient struct {
 	APIKey              string
 	Symbol              string // Single string for the symbol to subscribe to
 	Conn                *websocket.Conn
+	URL                 string                       // Feed URL including its path (default DefaultURL)
+	SymbolResolver      func(string) (string, error) // Normalizes subscribed symbols, e.g. symbols.Resolve; nil uses them as given
+	Dialer              *websocket.Dialer            // Dialer for the connection, nil uses websocket.DefaultDialer
+	ConnectTimeout      time.Duration                // Limit for dialing and the handshake (default 10s), zero waits for the dialer
+	Compression         bool                         // Negotiate permessage-deflate compression with the server
+	Codec               codec.Codec                  // Decodes JSON frames, nil uses the built-in quote parser and encoding/json
 	ConnMutex           sync.Mutex
-	MessageHandler      func(QuoteMessage, string) // Handles market data with a human-readable timestamp
-	ConnectedHandler    func(ConnectedMessage)     // Handles the "Connected" message
-	ReconnectionHandler func(int)                  // Handles reconnection attempts
-
-	MaxRetries    int           // Maximum retries for reconnection
-	RetryInterval time.Duration // Time between retries
-	AutoReconnect bool          // Enable/Disable automatic reconnection
-	StopReconnect chan struct{} // Channel to stop reconnection attempts
+	MessageHandler      func(QuoteMessage, string)    // Handles market data with a human-readable timestamp
+	QuoteHandler        func(QuoteMessage, time.Time) // Handles market data with the parsed timestamp
+	ConnectedHandler    func(ConnectedMessage)        // Handles the "Connected" message
+	ReconnectionHandler func(int)                     // Handles reconnection attempts
+	ErrorHandler        func(error)                   // Handles read, parse and write errors
+	DisconnectedHandler func(error)                   // Handles connection drops with the reason
+	RawMessageHandler   func([]byte)                  // Handles every frame as received, before parsing
+
+	MaxRetries       int           // Maximum retries for reconnection, <= 0 retries forever
+	RetryInterval    time.Duration // Time between retries
+	MaxRetryInterval time.Duration // Cap for the interval, which doubles after each failed attempt (default 1m)
+	AutoReconnect    bool          // Enable/Disable automatic reconnection
+	StopReconnect    chan struct{} // Channel to stop reconnection attempts
+	Clock            clock.Clock   // Times reconnection attempts, the stale watchdog and conflation, nil uses the system clock
+
+	TimestampLayout    string         // Layout of the message handler's timestamp (default DefaultTimestampLayout)
+	TimestampLocation  *time.Location // Time zone of the message handler's timestamp, nil means UTC
+	OutlierPercent     float64        // Flag quotes deviating more than this from the recent median, zero disables
+	OutlierWindow      int            // Quotes in the median window (default 21)
+	OutlierDrop        bool           // Drop flagged quotes instead of delivering them
+	KeepDuplicates     bool           // Deliver quotes repeating a recent quote's symbol, Ts, Bid and Ask
+	OnlyOnChange       bool           // Skip quotes whose bid and ask equal the symbol's previous quote
+	PauseUnsubscribes  bool           // Drop the upstream subscription while paused, see Pause
+	ConflationInterval time.Duration  // Deliver at most one quote per symbol per interval, zero disables
+	Filters            []Filter       // Applied in order to every quote before delivery, see AddFilter
+	HeartbeatTimeout   time.Duration  // Drop the connection after this long without any frame (default 30s), zero disables
+	PingInterval       time.Duration  // Interval between keep-alive pings (default 10s), zero disables
+
+	ShutdownTimeout time.Duration // How long Disconnect waits for queued messages to be handled (default 5s)
+
+	BufferSize      int            // Messages queued between the read loop and the handlers, zero disables
+	OverflowPolicy  OverflowPolicy // What to drop when the buffer is full
+	OverflowHandler func(uint64)   // Handles buffer overflows with the total number of dropped messages
+
+	StaleAfter   time.Duration           // Report symbols silent for this long, zero disables
+	StaleHandler func(string, time.Time) // Handles symbols silent for StaleAfter with their last quote time
+	MarketOpen   func(time.Time) bool    // Pauses the staleness watchdog while false, nil means always open
+
+	quotes     chan QuoteMessage    // Buffered channel behind Quotes()
+	errors     chan error           // Buffered channel behind Errors()
+	events     chan ConnectionEvent // Buffered channel behind Events()
+	recorder   *Recorder            // Active stream recorder, see Record
+	recorderMu sync.Mutex
+	lastQuotes quoteCache             // Latest quote per symbol, see GetLastQuote
+	conflation conflator              // Pending quotes held back by ConflationInterval
+	watchdog   watchdog               // Last quote time per symbol, see SetStaleHandler
+	deliverMu  sync.Mutex             // Serializes handler calls from the read pump and background flushes
+	lastTs     time.Time              // Timestamp of lastTsText, guarded by deliverMu
+	lastTsText string                 // Last formatted handler timestamp, guarded by deliverMu
+	writeMu    sync.Mutex             // Serializes data writes to Conn, see writeMessage
+	terminal   chan error             // Signals the end of an active Run, guarded by ConnMutex
+	done       chan struct{}          // Closed on shutdown, see Done, guarded by ConnMutex
+	stopped    bool                   // Whether the client has shut down, guarded by ConnMutex
+	closing    atomic.Bool            // Whether Disconnect is draining the connection
+	finished   chan struct{}          // Closed when the current connection's reader exits and its queue is handled, guarded by ConnMutex
+	connState  connState              // Current state, see GetState
+	stats      stats                  // Counters behind Stats()
+	dedupe     deduper                // Recent quotes per symbol for dropping duplicates
+	outliers   outlierFilter          // Recent mids per symbol, see SetOutlierFilter
+	paused     atomic.Bool            // Whether delivery is paused, see Pause
+	broker     atomic.Pointer[Broker] // Fan-out to in-process subscribers, see Broker
+	synthetics synthetics             // Crosses derived from their legs, see AddSynthetic
+	dropped    atomic.Uint64          // Messages dropped by buffer overflows
+	quotesLost atomic.Uint64          // Quotes dropped because the Quotes channel was full
+	quotesFull atomic.Bool            // Whether the Quotes channel was full on the last send
+	quotesUsed atomic.Bool            // Whether Quotes has been called, so drops matter
 }
 
 // NewWebSocketClient initializes the WebSocket client with an API key and symbol
 func NewWebSocketClient(apiKey, symbol string) *WebSocketClient {
 	return &WebSocketClient{
-		APIKey:        apiKey,
-		Symbol:        symbol,
-		MaxRetries:    5,               // Default maximum retries
-		RetryInterval: 5 * time.Second, // Default retry interval
-		AutoReconnect: true,            // Auto-reconnect enabled by default
-		StopReconnect: make(chan struct{}),
+		APIKey:           apiKey,
+		Symbol:           symbol,
+		URL:              DefaultURL,
+		MaxRetries:       5,               // Default maximum retries
+		RetryInterval:    5 * time.Second, // Default retry interval
+		MaxRetryInterval: time.Minute,     // Default cap for the retry backoff
+		AutoReconnect:    true,            // Auto-reconnect enabled by default
+		ConnectTimeout:   10 * time.Second,
+		StopReconnect:    make(chan struct{}),
+		done:             make(chan struct{}),
+		connState:        connState{changes: make(chan StateChange, 64)},
+		HeartbeatTimeout: 30 * time.Second,
+		PingInterval:     10 * time.Second,
+		ShutdownTimeout:  5 * time.Second,
+		quotes:           make(chan QuoteMessage, 256),
+		errors:           make(chan error, 64),
+		events:           make(chan ConnectionEvent, 64),
 	}
 }
 
+// SetURL sets the feed URL, e.g. a dedicated enterprise endpoint or a mock server
+func (client *WebSocketClient) SetURL(URL string) {
+	client.URL = URL
+}
+
+// feedURL returns the configured feed URL or DefaultURL
+func (client *WebSocketClient) feedURL() string {
+	if client.URL == "" {
+		return DefaultURL
+	}
+	return client.URL
+}
+
 // SetSymbol sets the symbol for WebSocket streaming
 func (client *WebSocketClient) SetSymbol(symbol string) {
 	client.Symbol = symbol
 }
 
+// Subscribe adds symbols to the subscription. When connected, the
+// credentials are re-sent with the full symbol list to update the live feed.
+func (client *WebSocketClient) Subscribe(symbols ...string) error {
+	symbols, err := client.resolveSymbols(symbols)
+	if err != nil {
+		return err
+	}
+
+	client.ConnMutex.Lock()
+	defer client.ConnMutex.Unlock()
+
+	client.Symbol = mergeSymbols(client.Symbol, symbols)
+	if client.Conn == nil {
+		return nil
+	}
+	return client.sendCredentials()
+}
+
+// Unsubscribe removes symbols from the subscription. When connected, the
+// credentials are re-sent with the remaining symbols to update the live feed.
+func (client *WebSocketClient) Unsubscribe(symbols ...string) error {
+	symbols, err := client.resolveSymbols(symbols)
+	if err != nil {
+		return err
+	}
+
+	client.ConnMutex.Lock()
+	defer client.ConnMutex.Unlock()
+
+	client.Symbol = removeSymbols(client.Symbol, symbols)
+	if client.Conn == nil {
+		return nil
+	}
+	return client.sendCredentials()
+}
+
+// Symbols returns the current subscription as a list
+func (client *WebSocketClient) Symbols() []string {
+	client.ConnMutex.Lock()
+	defer client.ConnMutex.Unlock()
+	return splitSymbols(client.Symbol)
+}
+
+// Quotes returns a channel receiving every parsed quote, as an alternative to
+// the message handler. Quotes are dropped when the channel's buffer is full:
+// an error wrapping ErrQuotesDropped is reported each time it fills up, and
+// QuotesDropped counts them.
+func (client *WebSocketClient) Quotes() <-chan QuoteMessage {
+	client.quotesUsed.Store(true)
+	return client.quotes
+}
+
+// QuotesDropped returns the number of quotes dropped because the Quotes
+// channel was full
+func (client *WebSocketClient) QuotesDropped() uint64 {
+	return client.quotesLost.Load()
+}
+
+// Close disconnects the client, see Disconnect
+func (client *WebSocketClient) Close() error {
+	return client.Disconnect()
+}
+
 // SetMessageHandler sets the callback function to handle incoming WebSocket messages
 func (client *WebSocketClient) SetMessageHandler(handler func(QuoteMessage, string)) {
 	client.MessageHandler = handler
 }
 
+// SetQuoteHandler sets the callback function to handle quotes with their
+// timestamp as a time.Time. It can be used instead of or alongside the message handler.
+func (client *WebSocketClient) SetQuoteHandler(handler func(QuoteMessage, time.Time)) {
+	client.QuoteHandler = handler
+}
+
+// SetOnlyOnChange suppresses delivery of quotes whose bid and ask are
+// unchanged from the symbol's previous quote. GetLastQuote still sees them.
+func (client *WebSocketClient) SetOnlyOnChange(enable bool) {
+	client.OnlyOnChange = enable
+}
+
+// SetTimestampFormat sets the layout and time zone of the timestamp passed to
+// the message handler. An empty layout uses DefaultTimestampLayout and a nil
+// location uses UTC; pass time.Local for the machine's zone.
+func (client *WebSocketClient) SetTimestampFormat(layout string, loc *time.Location) {
+	client.deliverMu.Lock()
+	defer client.deliverMu.Unlock()
+	client.TimestampLayout = layout
+	client.TimestampLocation = loc
+	client.lastTsText = ""
+}
+
 // SetConnectedHandler sets the callback function to handle the "Connected" message
 func (client *WebSocketClient) SetConnectedHandler(handler func(ConnectedMessage)) {
 	client.ConnectedHandler = handler
@@ -76,6 +302,47 @@ func (client *WebSocketClient) SetReconnectionHandler(handler func(int)) {
 	client.ReconnectionHandler = handler
 }
 
+// SetErrorHandler sets the callback function for read, parse and write errors.
+// Without a handler errors are printed to stdout.
+func (client *WebSocketClient) SetErrorHandler(handler func(error)) {
+	client.ErrorHandler = handler
+}
+
+// SetDisconnectedHandler sets the callback function called when the
+// connection drops, with the error that caused it
+func (client *WebSocketClient) SetDisconnectedHandler(handler func(reason error)) {
+	client.DisconnectedHandler = handler
+}
+
+// SetDialer sets the dialer used to connect, for custom TLS settings, proxies
+// or network dialing. The dialer's own HandshakeTimeout still applies.
+func (client *WebSocketClient) SetDialer(dialer *websocket.Dialer) {
+	client.Dialer = dialer
+}
+
+// SetConnectTimeout limits how long dialing and the handshake may take
+func (client *WebSocketClient) SetConnectTimeout(timeout time.Duration) {
+	client.ConnectTimeout = timeout
+}
+
+// SetCompression enables negotiation of permessage-deflate compression, which
+// reduces bandwidth for large subscriptions at some CPU cost. The server may
+// decline it, in which case messages arrive uncompressed.
+func (client *WebSocketClient) SetCompression(enable bool) {
+	client.Compression = enable
+}
+
+// SetCodec replaces the built-in JSON decoding of frames with cd
+func (client *WebSocketClient) SetCodec(cd codec.Codec) {
+	client.Codec = cd
+}
+
+// SetRawMessageHandler sets the callback function receiving every frame exactly
+// as received, before parsing. It runs on the read loop.
+func (client *WebSocketClient) SetRawMessageHandler(handler func([]byte)) {
+	client.RawMessageHandler = handler
+}
+
 // EnableAutoReconnect enables/disables automatic reconnection
 func (client *WebSocketClient) EnableAutoReconnect(enable bool) {
 	client.AutoReconnect = enable
@@ -86,125 +353,456 @@ func (client *WebSocketClient) Connect() error {
 	client.ConnMutex.Lock()
 	defer client.ConnMutex.Unlock()
 
+	// Restart a client that was disconnected or gave up reconnecting
+	if client.stopped {
+		client.StopReconnect = make(chan struct{})
+		client.done = make(chan struct{})
+		client.stopped = false
+	}
+	if client.SymbolResolver != nil {
+		symbols, err := client.resolveSymbols(splitSymbols(client.Symbol))
+		if err != nil {
+			return err
+		}
+		client.Symbol = strings.Join(symbols, ",")
+	}
+	return client.connect()
+}
+
+// SetSymbolResolver sets the function normalizing subscribed symbols, e.g.
+// symbols.Resolve so "eur/usd" subscribes to EURUSD. Connect and Subscribe
+// fail with the resolver's error for symbols it rejects.
+func (client *WebSocketClient) SetSymbolResolver(resolve func(string) (string, error)) {
+	client.SymbolResolver = resolve
+}
+
+// resolveSymbols passes symbols through the symbol resolver, if any
+func (client *WebSocketClient) resolveSymbols(symbols []string) ([]string, error) {
+	if client.SymbolResolver == nil {
+		return symbols, nil
+	}
+	resolved := make([]string, 0, len(symbols))
+	for _, s := range symbols {
+		r, err := client.SymbolResolver(s)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, r)
+	}
+	return resolved, nil
+}
+
+// connect dials the server unless already connected. Must be called with ConnMutex held.
+func (client *WebSocketClient) connect() error {
 	// If connection already exists, don't reconnect
 	if client.Conn != nil {
 		return nil
 	}
 
+	// Reconnection attempts stay in StateReconnecting until they succeed
+	reconnecting := client.GetState() == StateReconnecting
+	if !reconnecting {
+		client.setState(StateConnecting)
+	}
+
 	// Establish connection
+	dialer := client.Dialer
+	if dialer == nil {
+		dialer = websocket.DefaultDialer
+	}
+	if client.Compression && !dialer.EnableCompression {
+		withCompression := *dialer
+		withCompression.EnableCompression = true
+		dialer = &withCompression
+	}
+	ctx := context.Background()
+	if client.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, client.ConnectTimeout)
+		defer cancel()
+	}
+
 	var err error
-	client.Conn, _, err = websocket.DefaultDialer.Dial(wsURL, nil)
+	client.Conn, _, err = dialer.DialContext(ctx, client.feedURL(), nil)
 	if err != nil {
-		fmt.Printf("WebSocket connection failed: %v\n", err)
+		err = fmt.Errorf("WebSocket connection failed: %w", err)
+		client.reportError(err)
+		if !reconnecting {
+			client.setState(StateDisconnected)
+		}
 		return err
 	}
 
 	// Start reading messages
-	go client.wsReadPump()
+	client.finished = make(chan struct{})
+	go client.wsReadPump(client.Conn, client.finished)
 
 	// Send authentication message with user key and symbol
-	cred := fmt.Sprintf(`{"userKey":"%s", "symbol":"%s"}`, client.APIKey, client.Symbol)
-	err = client.Conn.WriteMessage(websocket.TextMessage, []byte(cred))
-	if err != nil {
-		return fmt.Errorf("Failed to send credentials: %w", err)
+	if err := client.sendCredentials(); err != nil {
+		return err
 	}
+	client.setState(StateConnected)
+	return nil
+}
 
+// sendCredentials sends the user key and symbol list. Must be called with ConnMutex held.
+func (client *WebSocketClient) sendCredentials() error {
+	cred := fmt.Sprintf(`{"userKey":"%s", "symbol":"%s"}`, client.APIKey, client.subscription())
+	err := client.writeMessage(client.Conn, websocket.TextMessage, []byte(cred))
+	if err != nil {
+		err = fmt.Errorf("Failed to send credentials: %w", err)
+		client.reportError(err)
+		return err
+	}
 	return nil
 }
 
-// Disconnect closes the WebSocket connection and stops reconnection attempts
+// Disconnect closes the WebSocket connection gracefully and stops reconnection
+// attempts, waiting up to ShutdownTimeout for queued messages to be handled,
+// see Shutdown. It is safe to call more than once, and Connect restarts the
+// client afterwards.
 func (client *WebSocketClient) Disconnect() error {
-	close(client.StopReconnect) // Stop reconnect attempts
+	ctx := context.Background()
+	if client.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, client.ShutdownTimeout)
+		defer cancel()
+	}
+	return client.Shutdown(ctx)
+}
 
+// Done returns a channel that is closed when the client shuts down for good:
+// after Disconnect, when reconnection is disabled or gives up, or when the API
+// key is rejected. A later Connect restarts the client with a new channel.
+func (client *WebSocketClient) Done() <-chan struct{} {
 	client.ConnMutex.Lock()
 	defer client.ConnMutex.Unlock()
+	return client.done
+}
 
-	if client.Conn != nil {
-		err := client.Conn.Close()
-		client.Conn = nil
-		return err
+// shutdown stops reconnection attempts and closes Done. Must be called with ConnMutex held.
+func (client *WebSocketClient) shutdown() {
+	if client.stopped {
+		return
 	}
-	return nil
+	client.stopped = true
+	close(client.StopReconnect)
+	close(client.done)
+	client.setState(StateClosed)
 }
 
-// wsReadPump handles incoming messages from the WebSocket connection
-func (client *WebSocketClient) wsReadPump() {
+// wsReadPump handles incoming messages from the WebSocket connection. It
+// closes finished once it has exited and every queued message is handled.
+func (client *WebSocketClient) wsReadPump(conn *websocket.Conn, finished chan struct{}) {
+	// Detect half-open connections: every frame and pong extends the read
+	// deadline, and pings make the server answer even when the feed is quiet
+	client.extendDeadline(conn)
+	conn.SetPongHandler(func(string) error {
+		client.extendDeadline(conn)
+		return nil
+	})
+	stop := make(chan struct{})
+	go client.keepAlive(conn, stop)
+	go client.watchStale(stop)
+	queue, drained := client.startDispatcher()
+
+	var buf bytes.Buffer // Reused for every frame, see readFrame
+	var readErr error
 	defer func() {
+		close(stop)
+		if queue != nil {
+			close(queue)
+			go func() {
+				<-drained
+				close(finished)
+			}()
+		} else {
+			close(finished)
+		}
+
 		client.ConnMutex.Lock()
-		client.Conn.Close()
-		client.Conn = nil
+		conn.Close()
+		if client.Conn == conn {
+			client.Conn = nil
+		}
+		// Nothing left to do after Disconnect, and retrying with a rejected key would loop forever
+		stopped := client.stopped || client.closing.Load()
+		retry := !stopped && client.AutoReconnect && !errors.Is(readErr, ErrAuthFailed)
+		if !stopped && !retry {
+			client.shutdown()
+		}
 		client.ConnMutex.Unlock()
 
-		if client.AutoReconnect {
+		if retry {
 			client.reconnect() // Try to reconnect when the connection is closed
+		} else if !stopped {
+			client.terminate(readErr)
 		}
 	}()
 
 	for {
-		_, message, err := client.Conn.ReadMessage()
+		message, err := readFrame(conn, &buf)
 		if err != nil {
-			fmt.Printf("WebSocket read error: %v\n", err)
+			readErr = err
+			if !client.closing.Load() && !client.isStopped() { // Closing on Disconnect is not a failure
+				client.reportError(fmt.Errorf("WebSocket read error: %w", err))
+			}
+			if client.DisconnectedHandler != nil {
+				client.callHandler("disconnected handler", func() { client.DisconnectedHandler(err) })
+			}
+			client.emitEvent(ConnectionEvent{Type: EventDisconnected, Err: err})
 			return
 		}
+		if client.closing.Load() {
+			continue // Read until the server acknowledges the close, delivering nothing new
+		}
+		client.extendDeadline(conn)
+		received := time.Now()
+		client.stats.frame(received, len(message))
+		client.record(received, message)
+		if client.RawMessageHandler != nil {
+			// Copied since the read buffer is reused for the next frame
+			raw := append([]byte(nil), message...)
+			client.callHandler("raw message handler", func() { client.RawMessageHandler(raw) })
+		}
 
-		// Check if the message is valid JSON (starts with '{' or '[')
-		msgStr := string(message)
-		if strings.HasPrefix(msgStr, "{") || strings.HasPrefix(msgStr, "[") {
-			// Try to handle the "Connected" message
-			var connectedMsg ConnectedMessage
-			if err := json.Unmarshal(message, &connectedMsg); err == nil && connectedMsg.Status == "connected" {
-				if client.ConnectedHandler != nil {
-					client.ConnectedHandler(connectedMsg) // Pass "Connected" message to the handler
-				}
-				continue
+		msg, err := parseMessage(message, client.Codec)
+		if err != nil {
+			client.reportError(err)
+			continue
+		}
+		msg.received = received
+		if msg.kind == kindAuthFailed {
+			readErr = fmt.Errorf("%w: %s", ErrAuthFailed, msg.status)
+			client.reportError(readErr)
+			if client.DisconnectedHandler != nil {
+				client.callHandler("disconnected handler", func() { client.DisconnectedHandler(readErr) })
 			}
+			client.emitEvent(ConnectionEvent{Type: EventDisconnected, Err: readErr})
+			return
+		}
+		if queue != nil {
+			client.enqueue(queue, msg)
+		} else {
+			client.dispatch(msg)
+		}
+	}
+}
 
-			// Parse the JSON message into the QuoteMessage struct (for market data)
-			var quote QuoteMessage
-			err = json.Unmarshal(message, &quote)
-			if err != nil {
-				fmt.Printf("Failed to unmarshal quote message: %v\n", err)
-				continue
+// dispatch passes a parsed message to the matching handler
+func (client *WebSocketClient) dispatch(msg parsedMessage) {
+	switch msg.kind {
+	case kindConnected:
+		if client.ConnectedHandler != nil {
+			// Pass "Connected" message to the handler
+			client.callHandler("connected handler", func() { client.ConnectedHandler(msg.connected) })
+		}
+		client.emitEvent(ConnectionEvent{Type: EventConnected, Message: msg.connected.Message})
+	case kindQuote:
+		msg.quote.Latency = msg.received.Sub(msg.ts)
+		if !client.KeepDuplicates && client.dedupe.isDuplicate(&msg.quote) {
+			return
+		}
+		client.watchdog.seen(msg.quote.Symbol, clock.OrDefault(client.Clock).Now())
+		client.stats.quote(msg.quote.Symbol, msg.quote.Latency)
+		if client.checkOutlier(&msg.quote) {
+			return
+		}
+		if len(client.Filters) > 0 {
+			var ok bool
+			if msg.quote, ok = ApplyFilters(client.Filters, msg.quote); !ok {
+				return
 			}
+		}
+		prev, seen := client.lastQuotes.set(msg.quote)
+		if client.OnlyOnChange && seen && prev.Bid == msg.quote.Bid && prev.Ask == msg.quote.Ask {
+			return
+		}
+		if client.conflate(msg) {
+			return
+		}
+		client.deliverQuote(msg)
+	case kindHeartbeat:
+		// Nothing to do, the read pump already extended the deadline
+	default:
+		// Non-JSON message: Handle appropriately (e.g., skip, log, etc.)
+		fmt.Printf("Status: %s\n", msg.status)
+	}
+}
 
-			// Convert the timestamp from string to int64
-			tsInt, err := strconv.ParseInt(quote.Ts, 10, 64)
-			if err != nil {
-				fmt.Printf("Failed to parse timestamp: %v\n", err)
-				continue
-			}
+// deliverQuote passes a quote, and any synthetic quotes derived from it, to
+// the message handler and the Quotes channel
+func (client *WebSocketClient) deliverQuote(msg parsedMessage) {
+	if client.paused.Load() {
+		return
+	}
+	client.deliverMu.Lock()
+	defer client.deliverMu.Unlock()
 
-			// Convert the timestamp from milliseconds to human-readable format (including milliseconds)
-			timestamp := time.Unix(0, tsInt*int64(time.Millisecond)).Format("2006-01-02 15:04:05.000")
+	client.handleQuote(msg)
+	for _, derived := range client.deriveSynthetic(msg) {
+		client.handleQuote(derived)
+	}
+}
 
-			// If the handler is set, call it with the parsed quote message and human-readable timestamp
-			if client.MessageHandler != nil {
-				client.MessageHandler(quote, timestamp)
-			}
-		} else {
-			// Non-JSON message: Handle appropriately (e.g., skip, log, etc.)
-			fmt.Printf("Status: %s\n", msgStr)
+// handleQuote calls the handlers for one quote. Must be called with deliverMu held.
+func (client *WebSocketClient) handleQuote(msg parsedMessage) {
+	// If the handler is set, call it with the parsed quote message and human-readable timestamp
+	if client.MessageHandler != nil {
+		// Quotes often share a millisecond, so reuse the last formatted timestamp
+		if !msg.ts.Equal(client.lastTs) || client.lastTsText == "" {
+			client.lastTs = msg.ts
+			client.lastTsText = formatTimestamp(msg.ts, client.TimestampLayout, client.TimestampLocation)
+		}
+		timestamp := client.lastTsText
+		client.callHandler("message handler", func() { client.MessageHandler(msg.quote, timestamp) })
+	}
+	if client.QuoteHandler != nil {
+		client.callHandler("quote handler", func() { client.QuoteHandler(msg.quote, msg.ts) })
+	}
+	select {
+	case client.quotes <- msg.quote:
+		client.quotesFull.Store(false)
+	default:
+		if !client.quotesUsed.Load() {
+			break // Nobody reads the channel, only the handlers are in use
+		}
+		lost := client.quotesLost.Add(1)
+		if !client.quotesFull.Swap(true) {
+			client.reportError(fmt.Errorf("%w: %d quotes dropped so far", ErrQuotesDropped, lost))
+		}
+	}
+	if broker := client.broker.Load(); broker != nil {
+		broker.publish(msg.quote)
+	}
+}
+
+// messageKind identifies the type of a received frame
+type messageKind int
+
+const (
+	kindStatus     messageKind = iota // Non-JSON status text
+	kindConnected                     // {"status":"connected"} message
+	kindQuote                         // Market data
+	kindHeartbeat                     // Keep-alive frame, only extends the read deadline
+	kindAuthFailed                    // The server rejected the API key
+)
+
+// parsedMessage is a received frame decoded by parseMessage
+type parsedMessage struct {
+	kind      messageKind
+	connected ConnectedMessage
+	quote     QuoteMessage
+	ts        time.Time // Quote timestamp
+	received  time.Time // Local receive time, zero for replayed frames
+	status    string    // Raw text of status frames
+}
+
+// parseMessage decodes a raw frame from the feed. A nil codec uses the
+// built-in quote parser with encoding/json for other messages.
+func parseMessage(message []byte, cd codec.Codec) (parsedMessage, error) {
+	// Check if the message is valid JSON (starts with '{' or '[')
+	if len(message) == 0 || (message[0] != '{' && message[0] != '[') {
+		msgStr := string(message)
+		if isHeartbeat(msgStr) {
+			return parsedMessage{kind: kindHeartbeat}, nil
+		}
+		if isAuthFailure(msgStr) {
+			return parsedMessage{kind: kindAuthFailed, status: strings.TrimSpace(msgStr)}, nil
 		}
+		return parsedMessage{kind: kindStatus, status: msgStr}, nil
+	}
+
+	// Quotes take the allocation-free path, everything else goes through encoding/json
+	var quote QuoteMessage
+	if cd != nil || !parseQuoteFast(message, &quote) {
+		return parseMessageJSON(message, codec.OrDefault(cd))
 	}
+	return quoteMessage(quote)
+}
+
+// parseMessageJSON decodes a JSON frame with the codec
+func parseMessageJSON(message []byte, cd codec.Codec) (parsedMessage, error) {
+	// Try to handle the "Connected" message
+	var connectedMsg ConnectedMessage
+	if err := cd.Unmarshal(message, &connectedMsg); err == nil && connectedMsg.Status == "connected" {
+		return parsedMessage{kind: kindConnected, connected: connectedMsg}, nil
+	}
+
+	// Parse the JSON message into the QuoteMessage struct (for market data)
+	var quote QuoteMessage
+	if err := cd.Unmarshal(message, &quote); err != nil {
+		return parsedMessage{}, fmt.Errorf("Failed to unmarshal quote message: %v", err)
+	}
+	return quoteMessage(quote)
+}
+
+// quoteMessage wraps a decoded quote, parsing its timestamp
+func quoteMessage(quote QuoteMessage) (parsedMessage, error) {
+	// Convert the timestamp from milliseconds to time.Time
+	ts, err := quote.Timestamp()
+	if err != nil {
+		return parsedMessage{}, fmt.Errorf("Failed to parse timestamp: %v", err)
+	}
+	quote.Time = ts
+	return parsedMessage{kind: kindQuote, quote: quote, ts: ts}, nil
+}
+
+// isAuthFailure reports whether a non-JSON status frame rejects the API key,
+// e.g. "User Key Wrong"
+func isAuthFailure(status string) bool {
+	status = strings.ToLower(status)
+	return strings.Contains(status, "key") && (strings.Contains(status, "wrong") || strings.Contains(status, "invalid"))
 }
 
 // reconnect attempts to reconnect to the WebSocket with retry logic
 func (client *WebSocketClient) reconnect() {
+	client.ConnMutex.Lock()
+	stop := client.StopReconnect
+	if !client.stopped {
+		client.setState(StateReconnecting)
+	}
+	client.ConnMutex.Unlock()
+
 	retries := 0
+	interval := client.RetryInterval
 	for {
 		retries++
-		if retries > client.MaxRetries {
+		if client.MaxRetries > 0 && retries > client.MaxRetries {
 			fmt.Println("Max retries reached. Stopping reconnection attempts.")
+			err := fmt.Errorf("reconnection failed after %d attempts", client.MaxRetries)
+			client.emitEvent(ConnectionEvent{Type: EventReconnectFailed, Err: err})
+			client.ConnMutex.Lock()
+			client.shutdown()
+			client.ConnMutex.Unlock()
+			client.terminate(err)
 			return
 		}
 
 		// Notify reconnection attempt
 		if client.ReconnectionHandler != nil {
-			client.ReconnectionHandler(retries)
+			client.callHandler("reconnection handler", func() { client.ReconnectionHandler(retries) })
 		}
+		client.emitEvent(ConnectionEvent{Type: EventReconnecting, Attempt: retries})
+		client.stats.reconnect()
 
-		fmt.Printf("Attempting to reconnect... (Attempt %d/%d)\n", retries, client.MaxRetries)
-		err := client.Connect()
+		if client.MaxRetries > 0 {
+			fmt.Printf("Attempting to reconnect... (Attempt %d/%d)\n", retries, client.MaxRetries)
+		} else {
+			fmt.Printf("Attempting to reconnect... (Attempt %d)\n", retries)
+		}
+		// Connect without restarting a client that was disconnected meanwhile
+		client.ConnMutex.Lock()
+		stopped := client.stopped
+		var err error
+		if !stopped {
+			err = client.connect()
+		}
+		client.ConnMutex.Unlock()
+		if stopped {
+			fmt.Println("Reconnect stopped.")
+			return
+		}
 		if err == nil {
 			fmt.Println("Successfully reconnected to WebSocket.")
 			return
@@ -212,10 +810,62 @@ func (client *WebSocketClient) reconnect() {
 
 		// Wait for the retry interval or stop if requested
 		select {
-		case <-time.After(client.RetryInterval):
-		case <-client.StopReconnect:
+		case <-clock.OrDefault(client.Clock).After(interval):
+		case <-stop:
 			fmt.Println("Reconnect stopped.")
 			return
 		}
+
+		// Back off exponentially up to MaxRetryInterval
+		if interval *= 2; client.MaxRetryInterval > 0 && interval > client.MaxRetryInterval {
+			interval = client.MaxRetryInterval
+		}
+		if interval < client.RetryInterval {
+			interval = client.RetryInterval
+		}
+	}
+}
+
+// splitSymbols splits a comma separated symbol list, dropping blanks and duplicates
+func splitSymbols(list string) []string {
+	seen := make(map[string]bool)
+	var symbols []string
+	for _, s := range strings.Split(list, ",") {
+		if s = strings.TrimSpace(s); s != "" && !seen[s] {
+			seen[s] = true
+			symbols = append(symbols, s)
+		}
+	}
+	return symbols
+}
+
+// mergeSymbols appends symbols missing from the comma separated list
+func mergeSymbols(list string, symbols []string) string {
+	merged := splitSymbols(list)
+	existing := make(map[string]bool, len(merged))
+	for _, s := range merged {
+		existing[s] = true
+	}
+	for _, s := range symbols {
+		if s = strings.TrimSpace(s); s != "" && !existing[s] {
+			existing[s] = true
+			merged = append(merged, s)
+		}
+	}
+	return strings.Join(merged, ",")
+}
+
+// removeSymbols drops symbols from the comma separated list
+func removeSymbols(list string, symbols []string) string {
+	remove := make(map[string]bool, len(symbols))
+	for _, s := range symbols {
+		remove[strings.TrimSpace(s)] = true
+	}
+	var kept []string
+	for _, s := range splitSymbols(list) {
+		if !remove[s] {
+			kept = append(kept, s)
+		}
 	}
+	return strings.Join(kept, ",")
 }